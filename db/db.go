@@ -12,12 +12,27 @@ import (
 )
 
 var Client *mongo.Client
+
+// TransactionsSupported - чи підтримує сервер багатодокументні транзакції (лише replica set або mongos)
+var TransactionsSupported bool
 var categoryCollection *mongo.Collection
 var transactionCollection *mongo.Collection
 var budgetCollection *mongo.Collection
 var goalCollection *mongo.Collection
 var countersCollection *mongo.Collection
 var settingCollection *mongo.Collection
+var recurringCollection *mongo.Collection
+var subscriptionCollection *mongo.Collection
+var savedSearchCollection *mongo.Collection
+var importProfileCollection *mongo.Collection
+var importBatchCollection *mongo.Collection
+var ruleCollection *mongo.Collection
+var payeeCollection *mongo.Collection
+var attachmentCollection *mongo.Collection
+var budgetSnapshotCollection *mongo.Collection
+var envelopeMovementCollection *mongo.Collection
+var notificationCollection *mongo.Collection
+var budgetTemplateCollection *mongo.Collection
 
 func init() {
 	// Створення параметрів підключення
@@ -37,6 +52,11 @@ func init() {
 	}
 	log.Println("Connected to MongoDB")
 
+	TransactionsSupported = detectTransactionSupport()
+	if !TransactionsSupported {
+		log.Println("MongoDB is a standalone server: multi-document writes run without transactions; use a replica set for atomic writes")
+	}
+
 	// Ініціалізація колекції після успішного підключення
 	countersCollection = Client.Database("cashWiseDB").Collection("counters")
 	categoryCollection = Client.Database("cashWiseDB").Collection("Category")
@@ -44,6 +64,32 @@ func init() {
 	budgetCollection = Client.Database("cashWiseDB").Collection("Budgets")
 	goalCollection = Client.Database("cashWiseDB").Collection("Goals")
 	settingCollection = Client.Database("cashWiseDB").Collection("Settings")
+	recurringCollection = Client.Database("cashWiseDB").Collection("RecurringTransactions")
+	subscriptionCollection = Client.Database("cashWiseDB").Collection("SubscriptionDecisions")
+	savedSearchCollection = Client.Database("cashWiseDB").Collection("SavedSearches")
+	importProfileCollection = Client.Database("cashWiseDB").Collection("ImportProfiles")
+	importBatchCollection = Client.Database("cashWiseDB").Collection("ImportBatches")
+	ruleCollection = Client.Database("cashWiseDB").Collection("CategoryRules")
+	payeeCollection = Client.Database("cashWiseDB").Collection("Payees")
+	attachmentCollection = Client.Database("cashWiseDB").Collection("Attachments")
+	budgetSnapshotCollection = Client.Database("cashWiseDB").Collection("BudgetSnapshots")
+	envelopeMovementCollection = Client.Database("cashWiseDB").Collection("EnvelopeMovements")
+	notificationCollection = Client.Database("cashWiseDB").Collection("Notifications")
+	budgetTemplateCollection = Client.Database("cashWiseDB").Collection("BudgetTemplates")
+
+	EnsureIndexes()
+}
+
+// detectTransactionSupport - транзакції доступні, якщо сервер є членом replica set або mongos
+func detectTransactionSupport() bool {
+	var hello bson.M
+	err := Client.Database("admin").RunCommand(context.Background(), bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		log.Printf("Could not detect MongoDB topology: %v", err)
+		return false
+	}
+	_, replicaSet := hello["setName"]
+	return replicaSet || hello["msg"] == "isdbgrid"
 }
 
 // GetCategoryCollection - повертає колекцію категорій
@@ -90,6 +136,94 @@ func GetSettingCollection() *mongo.Collection {
 	return settingCollection
 }
 
+func GetRecurringCollection() *mongo.Collection {
+	if recurringCollection == nil {
+		recurringCollection = Client.Database("cashWiseDB").Collection("RecurringTransactions")
+	}
+	return recurringCollection
+}
+
+func GetSubscriptionCollection() *mongo.Collection {
+	if subscriptionCollection == nil {
+		subscriptionCollection = Client.Database("cashWiseDB").Collection("SubscriptionDecisions")
+	}
+	return subscriptionCollection
+}
+
+func GetSavedSearchCollection() *mongo.Collection {
+	if savedSearchCollection == nil {
+		savedSearchCollection = Client.Database("cashWiseDB").Collection("SavedSearches")
+	}
+	return savedSearchCollection
+}
+
+func GetImportProfileCollection() *mongo.Collection {
+	if importProfileCollection == nil {
+		importProfileCollection = Client.Database("cashWiseDB").Collection("ImportProfiles")
+	}
+	return importProfileCollection
+}
+
+func GetImportBatchCollection() *mongo.Collection {
+	if importBatchCollection == nil {
+		importBatchCollection = Client.Database("cashWiseDB").Collection("ImportBatches")
+	}
+	return importBatchCollection
+}
+
+func GetRuleCollection() *mongo.Collection {
+	if ruleCollection == nil {
+		ruleCollection = Client.Database("cashWiseDB").Collection("CategoryRules")
+	}
+	return ruleCollection
+}
+
+func GetPayeeCollection() *mongo.Collection {
+	if payeeCollection == nil {
+		payeeCollection = Client.Database("cashWiseDB").Collection("Payees")
+	}
+	return payeeCollection
+}
+
+func GetAttachmentCollection() *mongo.Collection {
+	if attachmentCollection == nil {
+		attachmentCollection = Client.Database("cashWiseDB").Collection("Attachments")
+	}
+	return attachmentCollection
+}
+
+// GetBudgetSnapshotCollection - повертає колекцію підсумків закритих періодів бюджетів
+func GetBudgetSnapshotCollection() *mongo.Collection {
+	if budgetSnapshotCollection == nil {
+		budgetSnapshotCollection = Client.Database("cashWiseDB").Collection("BudgetSnapshots")
+	}
+	return budgetSnapshotCollection
+}
+
+// GetEnvelopeMovementCollection - повертає колекцію переміщень конвертного бюджету
+func GetEnvelopeMovementCollection() *mongo.Collection {
+	if envelopeMovementCollection == nil {
+		envelopeMovementCollection = Client.Database("cashWiseDB").Collection("EnvelopeMovements")
+	}
+	return envelopeMovementCollection
+}
+
+// GetNotificationCollection - повертає колекцію сповіщень користувачів
+func GetNotificationCollection() *mongo.Collection {
+	if notificationCollection == nil {
+		notificationCollection = Client.Database("cashWiseDB").Collection("Notifications")
+	}
+	return notificationCollection
+}
+
+// GetBudgetTemplateCollection - повертає колекцію збережених шаблонів бюджетів
+func GetBudgetTemplateCollection() *mongo.Collection {
+	if budgetTemplateCollection == nil {
+		budgetTemplateCollection = Client.Database("cashWiseDB").Collection("BudgetTemplates")
+	}
+	return budgetTemplateCollection
+}
+
 // ToggleDarkTheme - встановлює darkTheme на протилежне значення
 func ToggleDarkTheme(userID int) error {
 	collection := GetSettingCollection()
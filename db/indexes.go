@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureIndexes - створює індекси, на які спираються сортування, пагінація та пошук
+func EnsureIndexes() {
+	indexes := map[*mongo.Collection][]mongo.IndexModel{
+		GetTransactionCollection(): {
+			{Keys: bson.D{{Key: "userID", Value: 1}, {Key: "transactionID", Value: -1}}},
+			{Keys: bson.D{{Key: "userID", Value: 1}, {Key: "date", Value: -1}, {Key: "transactionID", Value: -1}}},
+			{Keys: bson.D{{Key: "userID", Value: 1}, {Key: "amount", Value: -1}, {Key: "transactionID", Value: -1}}},
+			{Keys: bson.D{{Key: "userID", Value: 1}, {Key: "tags", Value: 1}}},                                                 // Теги та автодоповнення
+			{Keys: bson.D{{Key: "userID", Value: 1}, {Key: "payeeID", Value: 1}, {Key: "date", Value: -1}}},                    // Статистика отримувача
+			{Keys: bson.D{{Key: "description", Value: "text"}}},                                                                // Повнотекстовий пошук
+			{Keys: bson.D{{Key: "userID", Value: 1}, {Key: "externalID", Value: 1}}, Options: options.Index().SetSparse(true)}, // Дублікати імпорту
+		},
+		GetCategoryCollection(): {
+			{Keys: bson.D{{Key: "userID", Value: 1}, {Key: "categoryID", Value: 1}}},
+			{Keys: bson.D{{Key: "userID", Value: 1}, {Key: "name", Value: 1}, {Key: "categoryID", Value: 1}}},
+		},
+		GetGoalCollection(): {
+			{Keys: bson.D{{Key: "userID", Value: 1}, {Key: "goalID", Value: 1}}},
+			{Keys: bson.D{{Key: "userID", Value: 1}, {Key: "deadline", Value: 1}, {Key: "goalID", Value: 1}}},
+			{Keys: bson.D{{Key: "userID", Value: 1}, {Key: "targetAmount", Value: 1}, {Key: "goalID", Value: 1}}},
+		},
+		GetPayeeCollection(): {
+			{Keys: bson.D{{Key: "userID", Value: 1}, {Key: "payeeID", Value: 1}}},
+			{Keys: bson.D{{Key: "userID", Value: 1}, {Key: "aliases", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		GetAttachmentCollection(): {
+			{Keys: bson.D{{Key: "userID", Value: 1}, {Key: "transactionID", Value: 1}, {Key: "attachmentID", Value: 1}}},
+		},
+		GetBudgetSnapshotCollection(): {
+			// Один підсумок на період: повторне закриття того самого періоду не створить дубліката
+			{Keys: bson.D{{Key: "budgetID", Value: 1}, {Key: "from", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		GetEnvelopeMovementCollection(): {
+			{Keys: bson.D{{Key: "userID", Value: 1}, {Key: "month", Value: 1}, {Key: "movementID", Value: 1}}},
+		},
+		GetNotificationCollection(): {
+			{Keys: bson.D{{Key: "userID", Value: 1}, {Key: "notificationID", Value: -1}}},
+			// Однакове сповіщення (той самий поріг бюджету в тому самому періоді) зберігається лише раз
+			{Keys: bson.D{{Key: "key", Value: 1}}, Options: options.Index().SetUnique(true).SetSparse(true)},
+		},
+		GetBudgetTemplateCollection(): {
+			{Keys: bson.D{{Key: "userID", Value: 1}, {Key: "templateID", Value: 1}}},
+		},
+		GetRuleCollection(): {
+			{Keys: bson.D{{Key: "userID", Value: 1}, {Key: "priority", Value: 1}, {Key: "ruleID", Value: 1}}},
+		},
+	}
+
+	for collection, models := range indexes {
+		if _, err := collection.Indexes().CreateMany(context.Background(), models); err != nil {
+			// Без індексу запити лише повільніші: унікальні індекси підстраховують перевірки в repo від гонок
+			log.Printf("Could not create indexes for %s: %v", collection.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"cashWise/repo"
+	"cashWise/service"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// UploadAttachment - прикріплює до транзакції файл з поля форми file (зображення або PDF)
+func UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := strconv.Atoi(mux.Vars(r)["transactionID"])
+	if err != nil {
+		http.Error(w, "Invalid transaction ID", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	data, fileName, err := readUploadedFile(w, r, "file", service.MaxAttachmentSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	attachment, err := service.AddAttachment(userID, transactionID, fileName, data)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Transaction not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Error adding attachment: %v", err), http.StatusBadRequest)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(attachment)
+}
+
+// GetAttachments - повертає список вкладень транзакції
+func GetAttachments(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := strconv.Atoi(mux.Vars(r)["transactionID"])
+	if err != nil {
+		http.Error(w, "Invalid transaction ID", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	attachments, err := repo.GetAttachments(userID, transactionID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching attachments: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attachments)
+}
+
+// DownloadAttachment - віддає вміст вкладення, а з thumbnail=true - його мініатюру
+func DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	attachmentID, err := strconv.Atoi(mux.Vars(r)["attachmentID"])
+	if err != nil {
+		http.Error(w, "Invalid attachment ID", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	attachment, err := repo.GetAttachmentByID(userID, attachmentID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Attachment not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Error fetching attachment: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	thumbnail := r.URL.Query().Get("thumbnail") == "true"
+	content, err := repo.OpenAttachment(attachment, thumbnail)
+	if err != nil {
+		if errors.Is(err, repo.ErrBlobNotFound) {
+			http.Error(w, "Attachment content not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Error reading attachment: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+	defer content.Close()
+
+	contentType, size := attachment.ContentType, strconv.FormatInt(attachment.Size, 10)
+	if thumbnail {
+		contentType, size = "image/jpeg", ""
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": attachment.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if size != "" {
+		w.Header().Set("Content-Length", size)
+	}
+	io.Copy(w, content)
+}
+
+// DeleteAttachment - видаляє вкладення разом з мініатюрою
+func DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	attachmentID, err := strconv.Atoi(mux.Vars(r)["attachmentID"])
+	if err != nil {
+		http.Error(w, "Invalid attachment ID", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := repo.DeleteAttachment(userID, attachmentID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Attachment not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Error deleting attachment: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Attachment deleted successfully"})
+}
@@ -3,12 +3,16 @@ package handlers
 import (
 	"cashWise/models"
 	"cashWise/repo"
+	"cashWise/service"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // CreateBudget - створює новий бюджет
@@ -21,14 +25,20 @@ func CreateBudget(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Додаємо бюджет через repo
-	if err := repo.CreateBudget(newBudget); err != nil {
+	if err := service.ValidateBudget(newBudget); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid budget: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// ID бюджету присвоює сервер
+	created, err := service.CreateBudget(newBudget)
+	if err != nil {
 		http.Error(w, fmt.Sprintf("Error creating budget: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{"message": "Budget created successfully"})
+	json.NewEncoder(w).Encode(map[string]interface{}{"message": "Budget created successfully", "budgetID": created.BudgetID})
 }
 
 // GetBudgetByID - обробляє запит на отримання бюджету за ID
@@ -71,6 +81,44 @@ func EditBudget(w http.ResponseWriter, r *http.Request) {
 
 	updatedBudget.BudgetID = budgetID // Встановлюємо ID в оновлений бюджет
 
+	// Перевіряємо бюджет у тому вигляді, який він матиме після оновлення
+	existing, err := repo.GetBudgetByID(budgetID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving budget: %v", err), http.StatusNotFound)
+		return
+	}
+	merged := existing
+	if updatedBudget.Limit != 0 {
+		merged.Limit = updatedBudget.Limit
+	}
+	if updatedBudget.Period != "" {
+		merged.Period = updatedBudget.Period
+	}
+	if updatedBudget.StartDate != "" {
+		merged.StartDate = updatedBudget.StartDate
+	}
+	if updatedBudget.CarryOver != "" {
+		merged.CarryOver = updatedBudget.CarryOver
+	}
+	if updatedBudget.CategoryIDs != nil {
+		merged.CategoryIDs = updatedBudget.CategoryIDs
+	}
+	if updatedBudget.Thresholds != nil {
+		merged.Thresholds = updatedBudget.Thresholds
+	}
+	if err := service.ValidateBudget(merged); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid budget: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// Зміна розкладу закриває період, що триває, і починає відлік заново; закриті періоди лишаються в історії
+	if merged.Period != existing.Period || merged.StartDate != existing.StartDate {
+		if err := service.RestartBudgetPeriod(existing, merged, time.Now().UTC()); err != nil {
+			http.Error(w, fmt.Sprintf("Error closing budget period: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
 	err = repo.EditBudget(updatedBudget)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error updating budget: %v", err), http.StatusInternalServerError)
@@ -101,7 +149,7 @@ func DeleteBudget(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"message": "Budget deleted successfully"})
 }
 
-// CheckLimit - обробляє запит на перевірку ліміту бюджету
+// CheckLimit - обробляє запит на перевірку ліміту бюджету за фактичними витратами поточного періоду
 func CheckLimit(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	budgetIDStr := params["budgetID"]
@@ -111,12 +159,310 @@ func CheckLimit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	message, err := repo.CheckLimit(budgetID)
+	budget, err := repo.GetBudgetByID(budgetID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving budget: %v", err), http.StatusNotFound)
+		return
+	}
+
+	budget, err = service.RollOverBudget(budget, time.Now().UTC())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error checking limit: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	status, err := service.GetBudgetStatus(budget, time.Now().UTC())
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error checking limit: %v", err), http.StatusInternalServerError)
 		return
 	}
 
+	message := "Limit is within range."
+	if status.Exceeded {
+		message = "Limit exceeded!"
+	}
+
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": message})
 }
+
+// GetBudgetStatus - повертає витрачену суму, залишок, відсоток використання та денний ліміт бюджету.
+// Необов'язковий параметр date (YYYY-MM-DD) вибирає період, за замовчуванням - поточний.
+func GetBudgetStatus(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	budgetIDStr := params["budgetID"]
+	budgetID, err := strconv.Atoi(budgetIDStr)
+	if err != nil {
+		http.Error(w, "Invalid budget ID", http.StatusBadRequest)
+		return
+	}
+
+	date := time.Now().UTC()
+	if dateStr := r.URL.Query().Get("date"); dateStr != "" {
+		date, err = time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			http.Error(w, "Invalid date format", http.StatusBadRequest)
+			return
+		}
+	}
+
+	budget, err := repo.GetBudgetByID(budgetID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving budget: %v", err), http.StatusNotFound)
+		return
+	}
+
+	budget, err = service.RollOverBudget(budget, time.Now().UTC())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error calculating budget status: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	status, err := service.GetBudgetStatus(budget, date)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error calculating budget status: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// GetBudgets - повертає всі бюджети користувача разом з їхнім станом у поточному періоді
+func GetBudgets(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	statuses, err := service.GetBudgetStatuses(userID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching budgets: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(statuses)
+}
+
+// GetBudgetHistory - повертає незмінні підсумки закритих періодів бюджету, від найновішого
+func GetBudgetHistory(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	budgetIDStr := params["budgetID"]
+	budgetID, err := strconv.Atoi(budgetIDStr)
+	if err != nil {
+		http.Error(w, "Invalid budget ID", http.StatusBadRequest)
+		return
+	}
+
+	budget, err := repo.GetBudgetByID(budgetID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving budget: %v", err), http.StatusNotFound)
+		return
+	}
+
+	history, err := service.GetBudgetHistory(budget)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching budget history: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// GetBudgetTemplates - повертає вбудовані шаблони бюджетів з категоріями користувача та його збережені шаблони
+func GetBudgetTemplates(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	lang := service.RequestLanguage(r.URL.Query().Get("lang"), r.Header.Get("Accept-Language"))
+	templates, err := service.GetBudgetTemplates(userID, lang)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching budget templates: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(templates)
+}
+
+// CreateBudgetTemplate - зберігає власний шаблон бюджетів користувача
+func CreateBudgetTemplate(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	var template models.BudgetTemplate
+	if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	template.UserID = userID
+
+	if err := service.ValidateBudgetTemplate(template); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid budget template: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	created, err := repo.CreateBudgetTemplate(template)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error creating budget template: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// DeleteBudgetTemplate - видаляє збережений шаблон; створені з нього бюджети лишаються
+func DeleteBudgetTemplate(w http.ResponseWriter, r *http.Request) {
+	templateID, err := strconv.Atoi(mux.Vars(r)["templateID"])
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := repo.DeleteBudgetTemplate(userID, templateID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Budget template not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Error deleting budget template: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Budget template deleted successfully"})
+}
+
+// ApplyBudgetTemplate - створює набір бюджетів за шаблоном (templateID збереженого або key вбудованого)
+// з лімітами як частками цільового доходу income
+func ApplyBudgetTemplate(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	var input struct {
+		TemplateID int       `json:"templateID"`
+		Key        string    `json:"key"`
+		Income     float64   `json:"income"`
+		Period     string    `json:"period"`
+		StartDate  string    `json:"startDate"`
+		CarryOver  string    `json:"carryOver"`
+		Thresholds []float64 `json:"thresholds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if input.TemplateID == 0 && input.Key == "" {
+		http.Error(w, "templateID or key is required", http.StatusBadRequest)
+		return
+	}
+
+	lang := service.RequestLanguage(r.URL.Query().Get("lang"), r.Header.Get("Accept-Language"))
+	budgets, err := service.ApplyBudgetTemplate(userID, input.TemplateID, input.Key, lang, input.Income, models.Budget{
+		Period:     input.Period,
+		StartDate:  input.StartDate,
+		CarryOver:  input.CarryOver,
+		Thresholds: input.Thresholds,
+	})
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Budget template not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Could not apply budget template: %v", err), http.StatusBadRequest)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(budgets)
+}
+
+// RestoreLastMonthLimits - повертає вказаним бюджетам ліміти, з якими вони працювали минулого місяця
+func RestoreLastMonthLimits(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	var input struct {
+		BudgetIDs []int `json:"budgetIDs"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if len(input.BudgetIDs) == 0 {
+		http.Error(w, "budgetIDs are required", http.StatusBadRequest)
+		return
+	}
+
+	budgets, skipped, err := service.RestoreLastMonthLimits(userID, input.BudgetIDs)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Budget not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Could not restore last month's limits: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"restored": budgets, "skipped": skipped})
+}
@@ -3,12 +3,15 @@ package handlers
 import (
 	"cashWise/models"
 	"cashWise/repo"
+	"cashWise/service"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // CreateCategory - створює нову категорію
@@ -21,8 +24,14 @@ func CreateCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Батьківська категорія має належати тому ж користувачу
+	if err := service.ValidateCategoryParent(newCategory.UserID, 0, newCategory.ParentID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Додаємо категорію через repo
-	if err := repo.AddCategory(newCategory); err != nil {
+	if _, err := repo.AddCategory(newCategory); err != nil {
 		http.Error(w, fmt.Sprintf("Error creating category: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -47,8 +56,14 @@ func GetAllCategoriesByUserID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Отримуємо всі категорії для конкретного користувача
-	categories, err := repo.GetAllCategoriesByUserIDLogic(userID)
+	params, err := parseListParams(r, categoryListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Отримуємо сторінку категорій для конкретного користувача
+	page, err := repo.ListCategories(userID, r.URL.Query().Get("includeArchived") == "true", params)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error retrieving categories: %v", err), http.StatusInternalServerError)
 		return
@@ -56,7 +71,8 @@ func GetAllCategoriesByUserID(w http.ResponseWriter, r *http.Request) {
 
 	// Повертаємо категорії у форматі JSON
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(categories); err != nil {
+	writePageHeaders(w, page)
+	if err := json.NewEncoder(w).Encode(page.Items); err != nil {
 		http.Error(w, fmt.Sprintf("Error encoding categories: %v", err), http.StatusInternalServerError)
 	}
 }
@@ -101,16 +117,20 @@ func GetCategoryByID(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(category)
 }
 
-// EditCategory - обробляє запит на оновлення категорії
+// EditCategory - обробляє запит на оновлення категорії; ненульовий parentID переносить її в іншу гілку
 func EditCategory(w http.ResponseWriter, r *http.Request) {
-	// Отримуємо budgetID та categoryID з параметрів запиту
-	vars := mux.Vars(r)
-	budgetIDStr := vars["budgetID"]
-	categoryIDStr := vars["categoryID"]
+	// categoryID з шляху, userID з query параметрів
+	categoryIDStr := mux.Vars(r)["categoryID"]
+
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "userID is required", http.StatusBadRequest)
+		return
+	}
 
-	budgetID, err := strconv.Atoi(budgetIDStr)
+	userID, err := strconv.Atoi(userIDStr)
 	if err != nil {
-		http.Error(w, "Invalid budget ID", http.StatusBadRequest)
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
 		return
 	}
 
@@ -128,8 +148,15 @@ func EditCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if updatedCategory.ParentID != 0 {
+		if err := service.MoveCategory(userID, categoryID, updatedCategory.ParentID); err != nil {
+			http.Error(w, fmt.Sprintf("Could not move category: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Оновлюємо категорію в БД
-	err = repo.UpdateCategory(budgetID, categoryID, updatedCategory)
+	err = repo.UpdateCategory(userID, categoryID, updatedCategory)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Could not update category: %v", err), http.StatusInternalServerError)
 		return
@@ -140,37 +167,191 @@ func EditCategory(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"message": "Category updated"})
 }
 
-// DeleteCategory - обробляє запит на видалення категорії
+// DeleteCategory - видаляє категорію. Категорію, що використовується, можна лише замінити іншою
+// (replaceWith - її транзакції, правила та інші посилання переходять на нову) або архівувати (archive=true).
 func DeleteCategory(w http.ResponseWriter, r *http.Request) {
-	// Отримуємо budgetID та categoryID з параметрів запиту
-	vars := mux.Vars(r)
-	budgetIDStr := vars["budgetID"]
-	categoryIDStr := vars["categoryID"]
-
-	budgetID, err := strconv.Atoi(budgetIDStr)
+	categoryID, err := strconv.Atoi(mux.Vars(r)["categoryID"])
 	if err != nil {
-		http.Error(w, "Invalid budget ID", http.StatusBadRequest)
+		http.Error(w, "Invalid category ID", http.StatusBadRequest)
 		return
 	}
 
-	categoryID, err := strconv.Atoi(categoryIDStr)
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "userID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
 	if err != nil {
-		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	if r.URL.Query().Get("archive") == "true" {
+		if err := repo.SetCategoryArchived(userID, categoryID, true); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				http.Error(w, "Category not found", http.StatusNotFound)
+			} else {
+				http.Error(w, fmt.Sprintf("Could not archive category: %v", err), http.StatusInternalServerError)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Category archived"})
 		return
 	}
 
-	// Викликаємо функцію для видалення категорії
-	err = repo.DeleteCategory(budgetID, categoryID)
+	if replaceWithStr := r.URL.Query().Get("replaceWith"); replaceWithStr != "" {
+		replacementID, err := strconv.Atoi(replaceWithStr)
+		if err != nil {
+			http.Error(w, "Invalid replacement category ID", http.StatusBadRequest)
+			return
+		}
+		reassigned, err := service.ReplaceCategory(userID, categoryID, replacementID)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				http.Error(w, "Category not found", http.StatusNotFound)
+			} else {
+				http.Error(w, fmt.Sprintf("Could not delete category: %v", err), http.StatusBadRequest)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"message": "Category deleted", "reassigned": reassigned})
+		return
+	}
+
+	// Без заміни видаляємо лише категорію, на яку ніщо не посилається
+	usage, err := repo.GetCategoryUsage(userID, categoryID)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Could not delete category: %v", err), http.StatusInternalServerError)
 		return
 	}
+	if service.CategoryInUse(usage) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": "Category is in use: pass replaceWith to reassign its records or archive=true to hide it",
+			"usage": usage,
+		})
+		return
+	}
+
+	if err := repo.DeleteCategory(userID, categoryID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Category not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Could not delete category: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
 
 	// Повертаємо успішну відповідь
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Category deleted"})
 }
 
+// RestoreCategory - повертає категорію з архіву
+func RestoreCategory(w http.ResponseWriter, r *http.Request) {
+	categoryID, err := strconv.Atoi(mux.Vars(r)["categoryID"])
+	if err != nil {
+		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "userID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := repo.SetCategoryArchived(userID, categoryID, false); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Category not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Could not restore category: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Category restored"})
+}
+
+// GetCategoryTree - повертає категорії користувача деревом з підкатегоріями
+func GetCategoryTree(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "userID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid userID: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	tree, err := service.GetCategoryTree(userID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving categories: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tree)
+}
+
+// MoveCategory - переносить категорію з усіма підкатегоріями під parentID; parentID 0 - на верхній рівень
+func MoveCategory(w http.ResponseWriter, r *http.Request) {
+	categoryID, err := strconv.Atoi(mux.Vars(r)["categoryID"])
+	if err != nil {
+		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "userID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid userID: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var input struct {
+		ParentID int `json:"parentID"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	if err := service.MoveCategory(userID, categoryID, input.ParentID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Category not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Could not move category: %v", err), http.StatusBadRequest)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Category moved"})
+}
+
+// GetCategoriesByBudgetID - повертає категорії бюджету разом з підкатегоріями, якщо бюджет їх враховує.
+// Для бюджету на всі витрати повертаються всі категорії користувача.
 func GetCategoriesByBudgetID(w http.ResponseWriter, r *http.Request) {
 	// Отримуємо budgetID з параметрів запиту
 	params := mux.Vars(r)
@@ -181,8 +362,14 @@ func GetCategoriesByBudgetID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Отримуємо категорії для даного budgetID
-	categories, err := repo.GetCategories(budgetID)
+	budget, err := repo.GetBudgetByID(budgetID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving budget: %v", err), http.StatusNotFound)
+		return
+	}
+
+	// Отримуємо категорії, витрати за якими входять у бюджет
+	categories, err := service.GetBudgetCategories(budget)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error retrieving categories: %v", err), http.StatusInternalServerError)
 		return
@@ -219,3 +406,49 @@ func GetCategoryByName(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, fmt.Sprintf("Error encoding category: %v", err), http.StatusInternalServerError)
 	}
 }
+
+// SuggestCategories - пропонує категорії для опису (та необов'язкових суми і типу) за історією користувача
+func SuggestCategories(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "userID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid userID: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	description := r.URL.Query().Get("description")
+	if description == "" {
+		http.Error(w, "description is required", http.StatusBadRequest)
+		return
+	}
+
+	amount := 0.0
+	if amountStr := r.URL.Query().Get("amount"); amountStr != "" {
+		if amount, err = strconv.ParseFloat(amountStr, 64); err != nil {
+			http.Error(w, "Invalid amount", http.StatusBadRequest)
+			return
+		}
+	}
+
+	limit := 5
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if limit, err = strconv.Atoi(limitStr); err != nil || limit < 1 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	suggestions, err := service.SuggestCategories(userID, description, amount, r.URL.Query().Get("type"), limit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error suggesting categories: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(suggestions)
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"cashWise/models"
+	"cashWise/repo"
+	"cashWise/service"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetDuplicates - повертає групи ймовірних дублікатів; з scan=true перевіряє всю історію
+func GetDuplicates(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	groups, err := service.GetDuplicateGroups(userID, r.URL.Query().Get("scan") == "true")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching duplicates: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(groups)
+}
+
+// MergeDuplicates - зливає дублікати в одну транзакцію
+func MergeDuplicates(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	var merge models.DuplicateMerge
+	if err := json.NewDecoder(r.Body).Decode(&merge); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	kept, err := service.MergeDuplicates(userID, merge)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error merging transactions: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(kept)
+}
+
+// DismissDuplicate - знімає позначку дубліката з транзакції
+func DismissDuplicate(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := strconv.Atoi(mux.Vars(r)["transactionID"])
+	if err != nil {
+		http.Error(w, "Invalid transaction ID", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := repo.ClearDuplicateFlag(userID, transactionID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Flagged transaction not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Error dismissing duplicate: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Duplicate flag removed"})
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"cashWise/models"
+	"cashWise/service"
+)
+
+// GetEnvelopes - повертає нерозподілену суму та доступні гроші в кожному конверті за місяць
+// (параметр month у форматі YYYY-MM, за замовчуванням поточний)
+func GetEnvelopes(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	summary, err := service.GetEnvelopes(userID, r.URL.Query().Get("month"))
+	if err != nil {
+		writeEnvelopeError(w, "Error fetching envelopes", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// AssignEnvelope - розподіляє гроші з нерозподіленого пулу в конверт категорії
+func AssignEnvelope(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	var input struct {
+		CategoryID int     `json:"categoryID"`
+		Amount     float64 `json:"amount"`
+		Note       string  `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if input.CategoryID == 0 {
+		http.Error(w, "categoryID is required", http.StatusBadRequest)
+		return
+	}
+
+	movement, err := service.MoveEnvelopeMoney(userID, models.EnvelopeMovement{
+		ToCategoryID: input.CategoryID,
+		Amount:       input.Amount,
+		Note:         input.Note,
+	})
+	if err != nil {
+		writeEnvelopeError(w, "Could not assign money", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(movement)
+}
+
+// MoveEnvelopeMoney - переміщує гроші між конвертами; категорія 0 означає нерозподілений пул
+func MoveEnvelopeMoney(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	var movement models.EnvelopeMovement
+	if err := json.NewDecoder(r.Body).Decode(&movement); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	movement, err = service.MoveEnvelopeMoney(userID, movement)
+	if err != nil {
+		writeEnvelopeError(w, "Could not move money", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(movement)
+}
+
+// GetEnvelopeMovements - повертає історію розподілу та переміщень за місяць
+func GetEnvelopeMovements(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	movements, err := service.GetEnvelopeMovements(userID, r.URL.Query().Get("month"))
+	if err != nil {
+		writeEnvelopeError(w, "Error fetching envelope movements", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(movements)
+}
+
+// CopyLastMonthEnvelopes - повторює в поточному місяці розподіл конвертів минулого місяця
+func CopyLastMonthEnvelopes(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	movements, skipped, err := service.CopyLastMonthEnvelopes(userID)
+	if err != nil {
+		writeEnvelopeError(w, "Could not copy last month's envelopes", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"assigned": movements, "skipped": skipped})
+}
+
+// writeEnvelopeError - вимкнений конвертний режим - конфлікт, решта помилок - некоректний запит
+func writeEnvelopeError(w http.ResponseWriter, message string, err error) {
+	if errors.Is(err, service.ErrEnvelopeModeDisabled) {
+		http.Error(w, "Envelope mode is disabled", http.StatusConflict)
+		return
+	}
+	http.Error(w, fmt.Sprintf("%s: %v", message, err), http.StatusBadRequest)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cashWise/service"
+)
+
+// ExportTransactions - вивантажує транзакції у CSV, XLSX або JSON з тими ж фільтрами, що й пошук
+// (from/to або period, types, categories, accounts, tags, minAmount, maxAmount, q) та сортуванням списку
+func ExportTransactions(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	formatName := r.URL.Query().Get("format")
+	if formatName == "" {
+		formatName = "csv"
+	}
+	format, ok := service.ExportFormats[formatName]
+	if !ok {
+		http.Error(w, "format must be csv, xlsx or json", http.StatusBadRequest)
+		return
+	}
+
+	search, err := parseTransactionSearch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.URL.Query().Get("period") != "" {
+		dateRange, err := resolveRequestPeriod(r, userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		search.From, search.To = dateRange.StartString(), dateRange.EndString()
+	}
+
+	// Розмір сторінки та курсор тут не потрібні, беремо лише сортування
+	params, err := parseListParams(r, transactionListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fileName := fmt.Sprintf("transactions-%s.%s", time.Now().UTC().Format("2006-01-02"), format.Extension)
+	w.Header().Set("Content-Type", format.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+
+	// Після початку відповіді статус змінити вже не можна, тому помилку лише записуємо в лог
+	if err := service.ExportTransactions(w, formatName, userID, search, params.Sort, params.Desc); err != nil {
+		log.Printf("Error exporting transactions for userID %d: %v", userID, err)
+	}
+}
@@ -129,8 +129,14 @@ func GetGoalsByUserIDHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Викликаємо репозиторій для отримання цілей
-	goals, err := repo.GetGoalsByUserID(userID)
+	params, err := parseListParams(r, goalListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Викликаємо репозиторій для отримання сторінки цілей
+	page, err := repo.ListGoals(userID, params)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error fetching goals for userID %d: %v", userID, err), http.StatusInternalServerError)
 		return
@@ -138,7 +144,8 @@ func GetGoalsByUserIDHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Повертаємо знайдені цілі у форматі JSON
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(goals); err != nil {
+	writePageHeaders(w, page)
+	if err := json.NewEncoder(w).Encode(page.Items); err != nil {
 		http.Error(w, fmt.Sprintf("Error encoding goals to JSON: %v", err), http.StatusInternalServerError)
 	}
 }
@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"cashWise/models"
+	"cashWise/repo"
+	"cashWise/service"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxStatementSize - максимальний розмір файлу виписки
+const maxStatementSize = 10 << 20
+
+// readUploadedFile - читає файл з multipart-форми
+func readUploadedFile(w http.ResponseWriter, r *http.Request, field string, maxSize int64) ([]byte, string, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+1<<20) // Запас на інші поля форми
+	if err := r.ParseMultipartForm(maxSize); err != nil {
+		return nil, "", fmt.Errorf("invalid multipart form: %v", err)
+	}
+
+	file, header, err := r.FormFile(field)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s is required", field)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading file: %v", err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, "", fmt.Errorf("file is larger than %d bytes", maxSize)
+	}
+	return data, header.Filename, nil
+}
+
+// ImportCSV - імпортує CSV-виписку; з preview=true лише показує розібрані рядки
+func ImportCSV(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	data, fileName, err := readUploadedFile(w, r, "file", maxStatementSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Зіставлення колонок: збережений профіль, перевизначений полем форми profile
+	var profile models.ImportProfile
+	if profileIDStr := r.URL.Query().Get("profileID"); profileIDStr != "" {
+		profileID, err := strconv.Atoi(profileIDStr)
+		if err != nil {
+			http.Error(w, "Invalid profile ID", http.StatusBadRequest)
+			return
+		}
+		profile, err = repo.GetImportProfileByID(userID, profileID)
+		if err != nil {
+			http.Error(w, "Import profile not found", http.StatusNotFound)
+			return
+		}
+	}
+	if profileJSON := r.FormValue("profile"); profileJSON != "" {
+		if err := json.Unmarshal([]byte(profileJSON), &profile); err != nil {
+			http.Error(w, "Invalid profile", http.StatusBadRequest)
+			return
+		}
+	}
+
+	preview, err := service.ParseCSVStatement(data, profile)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing statement: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	writeImportResult(w, r, userID, fileName, preview, profile.DefaultCategoryID)
+}
+
+// ImportOFX - імпортує виписку OFX або QFX; з dryRun=true показує, що буде створено
+func ImportOFX(w http.ResponseWriter, r *http.Request) {
+	importStatementFile(w, r, service.ParseOFXStatement)
+}
+
+// ImportQIF - імпортує файл QIF; з dryRun=true показує, що буде створено
+func ImportQIF(w http.ResponseWriter, r *http.Request) {
+	importStatementFile(w, r, service.ParseQIFStatement)
+}
+
+// ImportCAMT - імпортує виписку ISO 20022 camt.053; виписки, залишки яких не сходяться, відхиляються
+func ImportCAMT(w http.ResponseWriter, r *http.Request) {
+	importStatementFile(w, r, service.ParseCAMT053Statement)
+}
+
+// ImportMT940 - імпортує виписку SWIFT MT940; виписки, залишки яких не сходяться, відхиляються
+func ImportMT940(w http.ResponseWriter, r *http.Request) {
+	importStatementFile(w, r, service.ParseMT940Statement)
+}
+
+// importStatementFile - спільна обробка форматів, що не потребують зіставлення колонок.
+// Параметри account та categoryID задають рахунок і категорію для рядків виписки.
+func importStatementFile(w http.ResponseWriter, r *http.Request, parse func([]byte) (models.ImportPreview, error)) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	categoryID := 0
+	if categoryIDStr := r.URL.Query().Get("categoryID"); categoryIDStr != "" {
+		if categoryID, err = strconv.Atoi(categoryIDStr); err != nil {
+			http.Error(w, "Invalid category ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	data, fileName, err := readUploadedFile(w, r, "file", maxStatementSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	preview, err := parse(data)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing statement: %v", err), http.StatusBadRequest)
+		return
+	}
+	service.ApplyImportDefaults(&preview, r.URL.Query().Get("account"))
+
+	writeImportResult(w, r, userID, fileName, preview, categoryID)
+}
+
+// writeImportResult - застосовує правила категоризації, отримувачів і категорію за замовчуванням, позначає дублікати, звіряє залишки і або повертає попередній перегляд (preview/dryRun), або створює транзакції
+func writeImportResult(w http.ResponseWriter, r *http.Request, userID int, fileName string, preview models.ImportPreview, defaultCategoryID int) {
+	if err := service.CategorizeImportRows(userID, preview.Rows); err != nil {
+		http.Error(w, fmt.Sprintf("Error applying categorization rules: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := service.MatchImportPayees(userID, preview.Rows); err != nil {
+		http.Error(w, fmt.Sprintf("Error matching payees: %v", err), http.StatusInternalServerError)
+		return
+	}
+	service.ApplyImportDefaultCategory(preview.Rows, defaultCategoryID)
+	if err := service.MarkDuplicateRows(userID, &preview); err != nil {
+		http.Error(w, fmt.Sprintf("Error checking duplicates: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := service.ReconcileStatements(userID, &preview); err != nil {
+		http.Error(w, fmt.Sprintf("Error reconciling balances: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if r.URL.Query().Get("preview") == "true" || r.URL.Query().Get("dryRun") == "true" {
+		json.NewEncoder(w).Encode(preview)
+		return
+	}
+
+	batch, err := service.CommitImport(userID, fileName, preview)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error importing statement: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(batch)
+}
+
+// CreateImportProfile - зберігає профіль зіставлення колонок для банку
+func CreateImportProfile(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	var profile models.ImportProfile
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if profile.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+
+	profile.UserID = userID
+
+	created, err := repo.CreateImportProfile(profile)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error saving import profile: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// GetImportProfiles - повертає профілі імпорту користувача
+func GetImportProfiles(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	profiles, err := repo.GetImportProfiles(userID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching import profiles: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profiles)
+}
+
+// DeleteImportProfile - видаляє профіль імпорту
+func DeleteImportProfile(w http.ResponseWriter, r *http.Request) {
+	profileID, err := strconv.Atoi(mux.Vars(r)["profileID"])
+	if err != nil {
+		http.Error(w, "Invalid profile ID", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := repo.DeleteImportProfile(userID, profileID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Import profile not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Error deleting import profile: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Import profile deleted successfully"})
+}
+
+// GetImportBatches - повертає історію імпортів користувача
+func GetImportBatches(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	batches, err := repo.GetImportBatches(userID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching import batches: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(batches)
+}
+
+// RollbackImportBatch - видаляє всі транзакції, створені імпортом
+func RollbackImportBatch(w http.ResponseWriter, r *http.Request) {
+	batchID, err := strconv.Atoi(mux.Vars(r)["batchID"])
+	if err != nil {
+		http.Error(w, "Invalid batch ID", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := repo.RollbackImportBatch(userID, batchID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Import batch not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Error rolling back import: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+	service.ResetCategoryModel(userID)
+	service.CheckBudgetAlerts(userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"message": "Import rolled back", "deleted": deleted})
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"cashWise/repo"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+)
+
+// listSpec - дозволені параметри сортування та полів для спискового ендпоінта
+type listSpec struct {
+	sortKeys    map[string]string // Ключ сортування в API -> поле БД
+	defaultSort string
+	defaultDesc bool
+	fields      []string // Поля, які можна вибрати через fields=
+}
+
+var transactionListSpec = listSpec{
+	sortKeys:    map[string]string{"date": "date", "amount": "amount", "createdAt": "transactionID"},
+	defaultSort: "date",
+	defaultDesc: true, // Найновіші транзакції спочатку
+	fields: []string{"transactionID", "userID", "categoryID", "type", "amount", "date", "description",
+		"account", "currency", "transferID", "transferSide", "exchangeRate", "splits", "recurringID", "tags"},
+}
+
+// transactionIconListSpec - транзакції з іконкою категорії для /transactionsIcon
+var transactionIconListSpec = listSpec{
+	sortKeys:    transactionListSpec.sortKeys,
+	defaultSort: transactionListSpec.defaultSort,
+	defaultDesc: transactionListSpec.defaultDesc,
+	fields:      append(append([]string{}, transactionListSpec.fields...), "icon"),
+}
+
+var categoryListSpec = listSpec{
+	sortKeys:    map[string]string{"name": "name", "createdAt": "categoryID"},
+	defaultSort: "createdAt",
+	fields:      []string{"categoryID", "userID", "name", "description", "icon", "parentID", "archived"},
+}
+
+var goalListSpec = listSpec{
+	sortKeys:    map[string]string{"date": "deadline", "amount": "targetAmount", "createdAt": "goalID"},
+	defaultSort: "createdAt",
+	fields:      []string{"goalID", "userID", "name", "targetAmount", "deadline", "status"},
+}
+
+// parseListParams - читає limit, after, sort, order та fields з query параметрів. Без limit і after
+// повертається весь список, як до появи пагінації; сторінку за замовчуванням бере лише after без limit.
+func parseListParams(r *http.Request, spec listSpec) (repo.ListParams, error) {
+	query := r.URL.Query()
+	params := repo.ListParams{After: query.Get("after")}
+	if params.After != "" {
+		params.Limit = defaultPageLimit
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > maxPageLimit {
+			return params, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+		}
+		params.Limit = limit
+	}
+
+	sortKey := query.Get("sort")
+	if sortKey == "" {
+		sortKey = spec.defaultSort
+	}
+	sortField, ok := spec.sortKeys[sortKey]
+	if !ok {
+		return params, fmt.Errorf("invalid sort key: %s", sortKey)
+	}
+	params.Sort = sortField
+
+	switch query.Get("order") {
+	case "":
+		params.Desc = spec.defaultDesc
+	case "asc":
+		params.Desc = false
+	case "desc":
+		params.Desc = true
+	default:
+		return params, fmt.Errorf("order must be asc or desc")
+	}
+
+	if fieldsStr := query.Get("fields"); fieldsStr != "" {
+		for _, field := range strings.Split(fieldsStr, ",") {
+			field = strings.TrimSpace(field)
+			if !containsString(spec.fields, field) {
+				return params, fmt.Errorf("unknown field: %s", field)
+			}
+			params.Fields = append(params.Fields, field)
+		}
+	}
+
+	return params, nil
+}
+
+// writePageHeaders - передає загальну кількість та курсор наступної сторінки в заголовках
+func writePageHeaders(w http.ResponseWriter, page repo.Page) {
+	w.Header().Set("X-Total-Count", strconv.FormatInt(page.Total, 10))
+	if page.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
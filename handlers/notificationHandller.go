@@ -1,11 +1,16 @@
 package handlers
 
 import (
+	"cashWise/repo"
 	"cashWise/service"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func ToggleGoalReminderHandler(w http.ResponseWriter, r *http.Request) {
@@ -35,3 +40,60 @@ func ToggleGoalReminderHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
+
+// GetNotifications - повертає останні сповіщення користувача; з unread=true - лише непрочитані
+func GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	notifications, err := repo.GetNotifications(userID, r.URL.Query().Get("unread") == "true")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching notifications: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(notifications)
+}
+
+// MarkNotificationRead - позначає сповіщення прочитаним
+func MarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	notificationID, err := strconv.Atoi(mux.Vars(r)["notificationID"])
+	if err != nil {
+		http.Error(w, "Invalid notification ID", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := repo.MarkNotificationRead(userID, notificationID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Notification not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Error updating notification: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Notification marked as read"})
+}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"cashWise/models"
+	"cashWise/repo"
+	"cashWise/service"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CreatePayee - додає отримувача до довідника
+func CreatePayee(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	var payee models.Payee
+	if err := json.NewDecoder(r.Body).Decode(&payee); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	payee.UserID = userID
+	payee.PayeeID = 0
+
+	if err := service.ValidatePayee(userID, &payee); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	created, err := repo.CreatePayee(payee)
+	if errors.Is(err, repo.ErrPayeeAliasTaken) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error saving payee: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// GetPayees - повертає довідник отримувачів користувача
+func GetPayees(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	payees, err := repo.GetPayees(userID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching payees: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payees)
+}
+
+// UpdatePayee - замінює назву, псевдоніми та категорію за замовчуванням отримувача
+func UpdatePayee(w http.ResponseWriter, r *http.Request) {
+	payeeID, err := strconv.Atoi(mux.Vars(r)["payeeID"])
+	if err != nil {
+		http.Error(w, "Invalid payee ID", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	var payee models.Payee
+	if err := json.NewDecoder(r.Body).Decode(&payee); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	payee.UserID = userID
+	payee.PayeeID = payeeID
+
+	if err := service.ValidatePayee(userID, &payee); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := repo.UpdatePayee(payee); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Payee not found", http.StatusNotFound)
+		} else if errors.Is(err, repo.ErrPayeeAliasTaken) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, fmt.Sprintf("Error updating payee: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payee)
+}
+
+// DeletePayee - видаляє отримувача; його транзакції лишаються без отримувача
+func DeletePayee(w http.ResponseWriter, r *http.Request) {
+	payeeID, err := strconv.Atoi(mux.Vars(r)["payeeID"])
+	if err != nil {
+		http.Error(w, "Invalid payee ID", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := repo.DeletePayee(userID, payeeID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Payee not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Error deleting payee: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Payee deleted successfully"})
+}
+
+// MergePayees - зливає кількох отримувачів в одного разом з псевдонімами та транзакціями
+func MergePayees(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	var merge models.PayeeMerge
+	if err := json.NewDecoder(r.Body).Decode(&merge); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if merge.TargetID == 0 || len(merge.SourceIDs) == 0 {
+		http.Error(w, "sourceIDs and targetID are required", http.StatusBadRequest)
+		return
+	}
+
+	moved, err := service.MergePayees(userID, merge)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Payee not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Error merging payees: %v", err), http.StatusBadRequest)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"payeeID": merge.TargetID, "updated": moved})
+}
+
+// SplitPayee - виділяє частину псевдонімів отримувача в нового отримувача разом з відповідними транзакціями
+func SplitPayee(w http.ResponseWriter, r *http.Request) {
+	payeeID, err := strconv.Atoi(mux.Vars(r)["payeeID"])
+	if err != nil {
+		http.Error(w, "Invalid payee ID", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	var split models.PayeeSplit
+	if err := json.NewDecoder(r.Body).Decode(&split); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	created, err := service.SplitPayee(userID, payeeID, split)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Payee not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Error splitting payee: %v", err), http.StatusBadRequest)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// GetPayeeStats - історія витрат в отримувача: підсумок і суми за місяцями; межі як у звітах
+func GetPayeeStats(w http.ResponseWriter, r *http.Request) {
+	payeeID, err := strconv.Atoi(mux.Vars(r)["payeeID"])
+	if err != nil {
+		http.Error(w, "Invalid payee ID", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	if r.URL.Query().Get("period") != "" {
+		dateRange, err := resolveRequestPeriod(r, userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		from, to = dateRange.StartString(), dateRange.EndString()
+	}
+
+	stats, err := service.GetPayeeStats(userID, payeeID, from, to)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Payee not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Error building payee stats: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
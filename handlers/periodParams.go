@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cashWise/service"
+)
+
+// resolveRequestPeriod - обчислює період з query параметрів period, date, from, to та weekStart
+// з урахуванням налаштувань користувача (перший день тижня, початок фінансового місяця)
+func resolveRequestPeriod(r *http.Request, userID int) (service.DateRange, error) {
+	query := r.URL.Query()
+
+	period := query.Get("period")
+	if period == "" {
+		return service.DateRange{}, errors.New("period is required")
+	}
+
+	opts := service.PeriodOptionsForUser(userID)
+	opts.From = query.Get("from")
+	opts.To = query.Get("to")
+
+	// Перший день тижня можна перевизначити для окремого запиту
+	if weekStartStr := query.Get("weekStart"); weekStartStr != "" {
+		weekStart, err := strconv.Atoi(weekStartStr)
+		if err != nil || weekStart < 0 || weekStart > 6 {
+			return service.DateRange{}, errors.New("weekStart must be between 0 (Sunday) and 6 (Saturday)")
+		}
+		opts.WeekStart = time.Weekday(weekStart)
+	}
+
+	date := time.Now().UTC()
+	if dateStr := query.Get("date"); dateStr != "" {
+		parsed, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			return service.DateRange{}, errors.New("invalid date format")
+		}
+		date = parsed
+	} else if period != "custom" {
+		return service.DateRange{}, errors.New("date is required")
+	}
+
+	return service.ResolvePeriod(period, date, opts)
+}
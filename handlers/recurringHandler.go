@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cashWise/models"
+	"cashWise/repo"
+	"cashWise/service"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CreateRecurringHandler - створює шаблон транзакції, що повторюється
+func CreateRecurringHandler(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	var recurring models.RecurringTransaction
+	if err := json.NewDecoder(r.Body).Decode(&recurring); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := time.Parse("2006-01-02", recurring.StartDate); err != nil {
+		http.Error(w, "Invalid startDate format", http.StatusBadRequest)
+		return
+	}
+	if err := service.ValidateSchedule(recurring.Schedule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := repo.ValidateRecurring(recurring); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	recurring.UserID = userID
+	recurring.LastGenerated = ""
+
+	created, err := repo.CreateRecurring(recurring)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error creating recurring transaction: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// GetRecurringHandler - повертає всі шаблони користувача
+func GetRecurringHandler(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	recurring, err := repo.GetRecurringByUserID(userID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching recurring transactions: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(recurring)
+}
+
+// EditRecurringHandler - оновлює шаблон
+func EditRecurringHandler(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	recurringID, err := strconv.Atoi(params["recurringID"])
+	if err != nil {
+		http.Error(w, "Invalid recurring ID", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	var input struct {
+		models.RecurringTransaction
+		Active *bool `json:"active"` // Необов'язковий: пауза або відновлення шаблону
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	if input.Schedule.Frequency != "" {
+		if err := service.ValidateSchedule(input.Schedule); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	existing, err := repo.GetRecurringByID(userID, recurringID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Recurring transaction not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Error fetching recurring transaction: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Перевіряємо шаблон, яким він стане після оновлення: нова сума має збігатися зі старими частинами
+	merged := existing
+	if input.CategoryID != 0 {
+		merged.CategoryID = input.CategoryID
+	}
+	if input.Type != "" {
+		merged.Type = input.Type
+	}
+	if input.Amount != 0 {
+		merged.Amount = input.Amount
+	}
+	if len(input.Splits) > 0 {
+		merged.Splits = input.Splits
+	}
+	if err := repo.ValidateRecurring(merged); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := repo.UpdateRecurring(userID, recurringID, input.RecurringTransaction, input.Active); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Recurring transaction not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Error updating recurring transaction: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Recurring transaction updated successfully"})
+}
+
+// DeleteRecurringHandler - видаляє шаблон
+func DeleteRecurringHandler(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	recurringID, err := strconv.Atoi(params["recurringID"])
+	if err != nil {
+		http.Error(w, "Invalid recurring ID", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := repo.DeleteRecurring(userID, recurringID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Recurring transaction not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Error deleting recurring transaction: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Recurring transaction deleted successfully"})
+}
+
+// SetOccurrenceHandler - пропускає або змінює одне повторення шаблону
+func SetOccurrenceHandler(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	recurringID, err := strconv.Atoi(params["recurringID"])
+	if err != nil {
+		http.Error(w, "Invalid recurring ID", http.StatusBadRequest)
+		return
+	}
+
+	date := params["date"]
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		http.Error(w, "Invalid date format", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	var exception models.RecurringException
+	if err := json.NewDecoder(r.Body).Decode(&exception); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	exception.Date = date
+
+	if err := repo.SetRecurringException(userID, recurringID, exception); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Recurring transaction not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Error updating occurrence: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Occurrence updated successfully"})
+}
+
+// GetUpcomingOccurrencesHandler - повертає повторення на найближчі N днів
+func GetUpcomingOccurrencesHandler(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	days := 30
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		days, err = strconv.Atoi(daysStr)
+		if err != nil || days <= 0 || days > 366 {
+			http.Error(w, "days must be between 1 and 366", http.StatusBadRequest)
+			return
+		}
+	}
+
+	occurrences, err := service.GetUpcomingOccurrences(userID, days)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching upcoming occurrences: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(occurrences)
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cashWise/repo"
+	"cashWise/service"
+)
+
+// GetCategoryReport - повертає суми за категоріями з урахуванням розбиття транзакцій; з rollup=true - деревом
+func GetCategoryReport(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "Missing userID parameter", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid userID format", http.StatusBadRequest)
+		return
+	}
+
+	// Типи транзакцій через кому, за замовчуванням - витрати
+	transactionTypes := []string{"expense"}
+	if typesStr := r.URL.Query().Get("type"); typesStr != "" {
+		transactionTypes = strings.Split(typesStr, ",")
+	}
+
+	// Межі звіту: або період (period, date), або просто from/to
+	from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	if r.URL.Query().Get("period") != "" {
+		dateRange, err := resolveRequestPeriod(r, userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		from, to = dateRange.StartString(), dateRange.EndString()
+	}
+
+	// З rollup=true суми підкатегорій додаються до батьківських і звіт повертається деревом
+	if r.URL.Query().Get("rollup") == "true" {
+		rollup, err := service.GetCategoryRollup(userID, transactionTypes, from, to)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error building category report: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(rollup)
+		return
+	}
+
+	totals, err := repo.GetCategoryTotals(userID, transactionTypes, from, to)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error building category report: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(totals); err != nil {
+		http.Error(w, fmt.Sprintf("Error encoding report: %v", err), http.StatusInternalServerError)
+	}
+}
+
+// GetTagReport - суми за тегами (за замовчуванням витрати) за період або from/to
+func GetTagReport(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "Missing userID parameter", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid userID format", http.StatusBadRequest)
+		return
+	}
+
+	transactionTypes := []string{"expense"}
+	if typesStr := r.URL.Query().Get("type"); typesStr != "" {
+		transactionTypes = strings.Split(typesStr, ",")
+	}
+
+	from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	if r.URL.Query().Get("period") != "" {
+		dateRange, err := resolveRequestPeriod(r, userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		from, to = dateRange.StartString(), dateRange.EndString()
+	}
+
+	totals, err := repo.GetTagTotals(userID, transactionTypes, from, to)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error building tag report: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(totals)
+}
+
+// GetStatementPDF - повертає місячну виписку користувача у PDF (month=YYYY-MM, lang=uk|en)
+func GetStatementPDF(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "Missing userID parameter", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid userID format", http.StatusBadRequest)
+		return
+	}
+
+	month := r.URL.Query().Get("month")
+	if month == "" {
+		http.Error(w, "Missing month parameter", http.StatusBadRequest)
+		return
+	}
+	if _, err := time.Parse("2006-01", month); err != nil {
+		http.Error(w, "month must be in YYYY-MM format", http.StatusBadRequest)
+		return
+	}
+
+	statement, err := service.BuildMonthlyStatement(userID, month)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error building statement: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// PDF збирається в пам'яті, щоб при помилці ще можна було повернути код 500
+	var document bytes.Buffer
+	lang := service.RequestLanguage(r.URL.Query().Get("lang"), r.Header.Get("Accept-Language"))
+	if err := service.RenderStatementPDF(&document, statement, lang); err != nil {
+		http.Error(w, fmt.Sprintf("Error rendering statement: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", fmt.Sprintf("statement-%s.pdf", month)))
+	w.Write(document.Bytes())
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cashWise/models"
+	"cashWise/repo"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// splitList - розбиває значення query параметра за комами, пропускаючи порожні
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// parseTransactionSearch - читає умови пошуку з query параметрів
+func parseTransactionSearch(r *http.Request) (models.TransactionSearch, error) {
+	query := r.URL.Query()
+	search := models.TransactionSearch{
+		From:     query.Get("from"),
+		To:       query.Get("to"),
+		Types:    splitList(query.Get("types")),
+		Accounts: splitList(query.Get("accounts")),
+		Tags:     splitList(query.Get("tags")),
+		Text:     strings.TrimSpace(query.Get("q")),
+	}
+
+	for _, date := range []string{search.From, search.To} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return search, errors.New("invalid date format, expected YYYY-MM-DD")
+		}
+	}
+
+	for _, idStr := range splitList(query.Get("categories")) {
+		categoryID, err := strconv.Atoi(idStr)
+		if err != nil {
+			return search, fmt.Errorf("invalid category ID: %s", idStr)
+		}
+		search.CategoryIDs = append(search.CategoryIDs, categoryID)
+	}
+
+	if minStr := query.Get("minAmount"); minStr != "" {
+		minAmount, err := strconv.ParseFloat(minStr, 64)
+		if err != nil {
+			return search, errors.New("invalid minAmount")
+		}
+		search.MinAmount = &minAmount
+	}
+	if maxStr := query.Get("maxAmount"); maxStr != "" {
+		maxAmount, err := strconv.ParseFloat(maxStr, 64)
+		if err != nil {
+			return search, errors.New("invalid maxAmount")
+		}
+		search.MaxAmount = &maxAmount
+	}
+
+	return search, nil
+}
+
+// writeSearchResults - виконує пошук і повертає сторінку результатів
+func writeSearchResults(w http.ResponseWriter, r *http.Request, userID int, search models.TransactionSearch) {
+	params, err := parseListParams(r, transactionListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := repo.SearchTransactions(userID, search, params)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error searching transactions: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	writePageHeaders(w, page)
+	json.NewEncoder(w).Encode(page.Items)
+}
+
+// SearchTransactions - пошук транзакцій за довільною комбінацією умов
+func SearchTransactions(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	search, err := parseTransactionSearch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeSearchResults(w, r, userID, search)
+}
+
+// CreateSavedSearch - зберігає пошук під назвою
+func CreateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	var search models.SavedSearch
+	if err := json.NewDecoder(r.Body).Decode(&search); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if search.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+
+	search.UserID = userID
+
+	created, err := repo.CreateSavedSearch(search)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error saving search: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// GetSavedSearches - повертає всі збережені пошуки користувача
+func GetSavedSearches(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	searches, err := repo.GetSavedSearches(userID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching saved searches: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(searches)
+}
+
+// RunSavedSearch - виконує збережений пошук
+func RunSavedSearch(w http.ResponseWriter, r *http.Request) {
+	searchID, err := strconv.Atoi(mux.Vars(r)["searchID"])
+	if err != nil {
+		http.Error(w, "Invalid search ID", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	saved, err := repo.GetSavedSearchByID(userID, searchID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Saved search not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Error fetching saved search: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	writeSearchResults(w, r, userID, saved.Query)
+}
+
+// DeleteSavedSearch - видаляє збережений пошук
+func DeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	searchID, err := strconv.Atoi(mux.Vars(r)["searchID"])
+	if err != nil {
+		http.Error(w, "Invalid search ID", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := repo.DeleteSavedSearch(userID, searchID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Saved search not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Error deleting saved search: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Saved search deleted successfully"})
+}
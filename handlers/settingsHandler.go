@@ -2,6 +2,9 @@ package handlers
 
 import (
 	"cashWise/db"
+	"cashWise/repo"
+	"cashWise/service"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -90,3 +93,81 @@ func ToggleNotificationsHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("Notifications toggled successfully"))
 }
+
+// UpdatePeriodSettingsHandler - хендлер для зміни дня початку фінансового місяця та першого дня тижня
+func UpdatePeriodSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	// Отримуємо userID з параметрів запиту
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Перетворюємо userID з рядка в ціле число
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	var input struct {
+		FiscalMonthStart *int `json:"fiscalMonthStart"`
+		WeekStart        *int `json:"weekStart"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	if input.FiscalMonthStart != nil && (*input.FiscalMonthStart < 1 || *input.FiscalMonthStart > 31) {
+		http.Error(w, "fiscalMonthStart must be between 1 and 31", http.StatusBadRequest)
+		return
+	}
+	if input.WeekStart != nil && (*input.WeekStart < 0 || *input.WeekStart > 6) {
+		http.Error(w, "weekStart must be between 0 (Sunday) and 6 (Saturday)", http.StatusBadRequest)
+		return
+	}
+
+	if err := repo.UpdatePeriodSettings(userID, input.FiscalMonthStart, input.WeekStart); err != nil {
+		http.Error(w, fmt.Sprintf("Error updating period settings: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Відповідь на успішне виконання
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Period settings updated successfully"))
+}
+
+// UpdateEnvelopeModeHandler - хендлер для ввімкнення та вимкнення конвертного бюджету
+func UpdateEnvelopeModeHandler(w http.ResponseWriter, r *http.Request) {
+	// Отримуємо userID з параметрів запиту
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Перетворюємо userID з рядка в ціле число
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	var input struct {
+		Enabled bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	if err := service.SetEnvelopeMode(userID, input.Enabled); err != nil {
+		http.Error(w, fmt.Sprintf("Error updating envelope mode: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Відповідь на успішне виконання
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Envelope mode updated successfully"))
+}
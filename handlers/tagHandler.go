@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"cashWise/models"
+	"cashWise/repo"
+
+	"github.com/gorilla/mux"
+)
+
+// GetTags - автодоповнення тегів: теги користувача з префіксом prefix, від найуживаніших
+func GetTags(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	limit := 20
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if limit, err = strconv.Atoi(limitStr); err != nil || limit < 1 || limit > maxPageLimit {
+			http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxPageLimit), http.StatusBadRequest)
+			return
+		}
+	}
+
+	tags, err := repo.GetTags(userID, r.URL.Query().Get("prefix"), limit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching tags: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
+
+// RenameTag - перейменовує тег в усіх транзакціях; якщо новий тег уже існує, теги зливаються
+func RenameTag(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	var input struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	writeTagMerge(w, userID, models.TagMerge{Sources: []string{mux.Vars(r)["tag"]}, Target: input.Name})
+}
+
+// MergeTags - зливає кілька тегів в один
+func MergeTags(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	var merge models.TagMerge
+	if err := json.NewDecoder(r.Body).Decode(&merge); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	writeTagMerge(w, userID, merge)
+}
+
+// writeTagMerge - перевіряє та виконує злиття тегів і повертає кількість змінених транзакцій
+func writeTagMerge(w http.ResponseWriter, userID int, merge models.TagMerge) {
+	sources := repo.NormalizeTags(merge.Sources)
+	targets := repo.NormalizeTags([]string{merge.Target})
+	if len(sources) == 0 || len(targets) == 0 {
+		http.Error(w, "source and target tags are required", http.StatusBadRequest)
+		return
+	}
+
+	updated, err := repo.MergeTags(userID, sources, targets[0])
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error merging tags: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"tag": targets[0], "updated": updated})
+}
+
+// DeleteTag - прибирає тег з усіх транзакцій користувача
+func DeleteTag(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID")
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	tags := repo.NormalizeTags([]string{mux.Vars(r)["tag"]})
+	if len(tags) == 0 {
+		http.Error(w, "Invalid tag", http.StatusBadRequest)
+		return
+	}
+
+	updated, err := repo.DeleteTag(userID, tags[0])
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error deleting tag: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"tag": tags[0], "updated": updated})
+}
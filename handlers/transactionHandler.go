@@ -6,7 +6,6 @@ import (
 	"log"
 	"net/http"
 	"strconv"
-	"time"
 
 	"cashWise/models"
 	"cashWise/repo"
@@ -37,13 +36,47 @@ func AddTransaction(w http.ResponseWriter, r *http.Request) {
 
 	newTransaction.UserID = userID
 
+	// Внесок можна прив'язати лише до власної цілі користувача
+	if newTransaction.GoalID != 0 {
+		if goal, err := repo.GetGoalByID(newTransaction.GoalID); err != nil || goal.UserID != userID {
+			http.Error(w, "Goal not found", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Правила категоризації заповнюють категорію, якщо її не вибрано, а також теги й опис
+	if err := service.CategorizeTransaction(&newTransaction); err != nil {
+		log.Printf("Categorization rules failed for userID %d: %v", userID, err)
+	}
+
+	// Отримувача визначаємо за описом; його категорія за замовчуванням діє, якщо правила категорію не задали.
+	// Нового отримувача для невідомого опису створюємо лише на прохання (createPayee=true).
+	if err := service.AssignPayee(&newTransaction, r.URL.Query().Get("createPayee") == "true"); err != nil {
+		http.Error(w, fmt.Sprintf("Error assigning payee: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// Схожу на наявну транзакцію створюємо з позначкою, щоб користувач переглянув її в /transactions/duplicates
+	duplicateOf, err := service.FindDuplicateOf(newTransaction)
+	if err != nil {
+		log.Printf("Duplicate check failed for userID %d: %v", userID, err)
+	}
+	newTransaction.DuplicateOf = duplicateOf
+
 	if err := repo.AddTransaction(newTransaction); err != nil {
 		http.Error(w, fmt.Sprintf("Error adding transaction: %v", err), http.StatusInternalServerError)
 		return
 	}
+	service.LearnTransaction(newTransaction)
+	service.CheckBudgetAlerts(userID)
+
+	response := map[string]interface{}{"message": "Transaction added successfully"}
+	if duplicateOf != 0 {
+		response["duplicateOf"] = duplicateOf
+	}
 
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{"message": "Transaction added successfully"})
+	json.NewEncoder(w).Encode(response)
 }
 
 // EditTransaction - редагує існуючу транзакцію
@@ -76,11 +109,32 @@ func EditTransaction(w http.ResponseWriter, r *http.Request) {
 
 	updatedTransaction.UserID = userID
 
+	// Внесок можна прив'язати лише до власної цілі користувача
+	if updatedTransaction.GoalID != 0 {
+		if goal, err := repo.GetGoalByID(updatedTransaction.GoalID); err != nil || goal.UserID != userID {
+			http.Error(w, "Goal not found", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Попередній стан потрібен, щоб перенавчити підказки категорій на зміненій транзакції
+	before, err := repo.GetTransactionsByIDs(userID, []int{transactionID})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error editing transaction: %v", err), http.StatusInternalServerError)
+		return
+	}
+
 	if err := repo.EditTransaction(transactionID, updatedTransaction); err != nil {
 		http.Error(w, fmt.Sprintf("Error editing transaction: %v", err), http.StatusInternalServerError)
 		return
 	}
 
+	if after, err := repo.GetTransactionsByIDs(userID, []int{transactionID}); err == nil && len(before) == 1 && len(after) == 1 {
+		service.ForgetTransaction(before[0])
+		service.LearnTransaction(after[0])
+	}
+	service.CheckBudgetAlerts(userID)
+
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Transaction updated successfully"})
 }
@@ -107,17 +161,27 @@ func DeleteTransaction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	deleted, err := repo.GetTransactionsByIDs(userID, []int{transactionID})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error deleting transaction: %v", err), http.StatusInternalServerError)
+		return
+	}
+
 	// Викликаємо репозиторій для видалення транзакції за userID та transactionID
 	if err := repo.DeleteTransaction(userID, transactionID); err != nil {
 		http.Error(w, fmt.Sprintf("Error deleting transaction: %v", err), http.StatusInternalServerError)
 		return
 	}
+	for _, transaction := range deleted {
+		service.ForgetTransaction(transaction)
+	}
+	service.CheckBudgetAlerts(userID)
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Transaction deleted successfully"})
 }
 
-// FilterByDate - обробник запиту для фільтрації транзакцій за заданим періодом (день, тиждень, місяць)
+// FilterByDate - обробник запиту для фільтрації транзакцій за заданим періодом (день, тиждень, місяць, квартал, рік тощо)
 func FilterByDate(w http.ResponseWriter, r *http.Request) {
 	userIDStr := r.URL.Query().Get("userID") // Отримання userID з параметрів запиту
 	if userIDStr == "" {
@@ -131,54 +195,33 @@ func FilterByDate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	period := r.URL.Query().Get("period") // day, week, month
-	dateStr := r.URL.Query().Get("date")  // дата у форматі YYYY-MM-DD
-
-	if period == "" || dateStr == "" {
-		http.Error(w, "Period and date are required", http.StatusBadRequest)
-		return
-	}
-
-	date, err := time.Parse("2006-01-02", dateStr)
+	// Період: day, week, isoWeek, month, fiscalMonth, quarter, year або custom з from/to
+	dateRange, err := resolveRequestPeriod(r, userID)
 	if err != nil {
-		http.Error(w, "Invalid date format", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	var startDate, endDate time.Time
-	switch period {
-	case "day":
-		startDate = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-		endDate = startDate.Add(24 * time.Hour)
-	case "week":
-		weekday := date.Weekday()
-		offset := (int(weekday) - int(time.Monday) + 7) % 7             // Розрахунок зсуву до понеділка
-		startDate = date.Add(-time.Duration(offset+7) * 24 * time.Hour) // На тиждень назад
-		endDate = startDate.Add(7 * 24 * time.Hour)                     // Додаємо 7 днів для кінця тижня
-	case "month":
-		startDate = time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
-		endDate = startDate.AddDate(0, 1, -1) // Останній день місяця
-	default:
-		http.Error(w, "Invalid period, must be day, week, or month", http.StatusBadRequest)
+	params, err := parseListParams(r, transactionListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	startDateStr := startDate.Format("2006-01-02")
-	endDateStr := endDate.Format("2006-01-02")
-
-	transactions, err := repo.GetTransactionsByDateAndUserID(startDateStr, endDateStr, userID)
+	page, err := repo.ListTransactionsByDate(userID, dateRange.StartString(), dateRange.EndString(), params)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error fetching transactions: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(transactions); err != nil {
+	writePageHeaders(w, page)
+	if err := json.NewEncoder(w).Encode(page.Items); err != nil {
 		http.Error(w, fmt.Sprintf("Error encoding transactions: %v", err), http.StatusInternalServerError)
 	}
 }
 
-// GetAllTransactions - отримує всі транзакції для користувача
+// GetAllTransactions - отримує сторінку транзакцій для користувача
 func GetAllTransactions(w http.ResponseWriter, r *http.Request) {
 	userIDStr := r.URL.Query().Get("userID") // Отримання userID з параметрів запиту
 	if userIDStr == "" {
@@ -192,14 +235,21 @@ func GetAllTransactions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	transactions, err := repo.GetAllTransactions(userID)
+	params, err := parseListParams(r, transactionListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := repo.ListTransactions(userID, splitList(r.URL.Query().Get("tags")), params)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error fetching transactions: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(transactions)
+	writePageHeaders(w, page)
+	json.NewEncoder(w).Encode(page.Items)
 }
 
 // Обробник для отримання загальної суми витрат для конкретного користувача
@@ -319,8 +369,14 @@ func GetTransactionsByUserIDAndTypeHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Викликаємо репозиторій для отримання транзакцій
-	transactions, err := repo.GetTransactionsByUserIDAndType(userID, transactionType)
+	params, err := parseListParams(r, transactionListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Викликаємо репозиторій для отримання сторінки транзакцій
+	page, err := repo.ListTransactionsByType(userID, transactionType, params)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error fetching transactions for userID %d and type %s: %v", userID, transactionType, err), http.StatusInternalServerError)
 		return
@@ -328,7 +384,8 @@ func GetTransactionsByUserIDAndTypeHandler(w http.ResponseWriter, r *http.Reques
 
 	// Повертаємо знайдені транзакції у форматі JSON
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(transactions); err != nil {
+	writePageHeaders(w, page)
+	if err := json.NewEncoder(w).Encode(page.Items); err != nil {
 		http.Error(w, fmt.Sprintf("Error encoding transactions to JSON: %v", err), http.StatusInternalServerError)
 	}
 }
@@ -360,7 +417,7 @@ func GetTransactionWithCategoryHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(data)
 }
 
-// GetTransactionsHandler - хендлер для отримання всіх транзакцій за userID
+// GetTransactionsHandler - хендлер для отримання сторінки транзакцій з іконками за userID
 func GetTransactionsHandler(w http.ResponseWriter, r *http.Request) {
 	// Отримуємо параметр userID з query параметрів
 	userIDStr := r.URL.Query().Get("userID")
@@ -376,8 +433,14 @@ func GetTransactionsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	params, err := parseListParams(r, transactionIconListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Викликаємо сервіс для отримання транзакцій
-	transactions, err := service.GetTransactionsByUserID(userID)
+	page, err := service.GetTransactionsByUserID(userID, splitList(r.URL.Query().Get("tags")), params)
 	if err != nil {
 		http.Error(w, "Error fetching transactions", http.StatusInternalServerError)
 		return
@@ -385,8 +448,9 @@ func GetTransactionsHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Відправляємо результат у вигляді JSON
 	w.Header().Set("Content-Type", "application/json")
+	writePageHeaders(w, page)
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(transactions); err != nil {
+	if err := json.NewEncoder(w).Encode(page.Items); err != nil {
 		log.Printf("Error encoding response: %v", err)
 	}
 }
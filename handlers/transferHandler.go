@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"cashWise/models"
+	"cashWise/repo"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CreateTransfer - створює переказ між рахунками
+func CreateTransfer(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("userID") // Отримання userID з параметрів запиту
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	var transfer models.Transfer
+	if err := json.NewDecoder(r.Body).Decode(&transfer); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	transfer.UserID = userID
+
+	created, err := repo.CreateTransfer(transfer)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error creating transfer: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// GetTransfer - повертає переказ з обома частинами
+func GetTransfer(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	transferID, err := strconv.Atoi(params["transferID"])
+	if err != nil {
+		http.Error(w, "Invalid transfer ID", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID") // Отримання userID з параметрів запиту
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	transfer, err := repo.GetTransferByID(userID, transferID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Transfer not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Error retrieving transfer: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transfer)
+}
+
+// EditTransfer - редагує обидві частини переказу
+func EditTransfer(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	transferID, err := strconv.Atoi(params["transferID"])
+	if err != nil {
+		http.Error(w, "Invalid transfer ID", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID") // Отримання userID з параметрів запиту
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	var updatedTransfer models.Transfer
+	if err := json.NewDecoder(r.Body).Decode(&updatedTransfer); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	transfer, err := repo.EditTransfer(userID, transferID, updatedTransfer)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Transfer not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Error editing transfer: %v", err), http.StatusBadRequest)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transfer)
+}
+
+// DeleteTransfer - видаляє обидві частини переказу
+func DeleteTransfer(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	transferID, err := strconv.Atoi(params["transferID"])
+	if err != nil {
+		http.Error(w, "Invalid transfer ID", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr := r.URL.Query().Get("userID") // Отримання userID з параметрів запиту
+	if userIDStr == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := repo.DeleteTransfer(userID, transferID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Transfer not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Error deleting transfer: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Transfer deleted successfully"})
+}
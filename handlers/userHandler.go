@@ -3,10 +3,12 @@ package handlers
 import (
 	"cashWise/models"
 	"cashWise/repo"
+	"cashWise/service"
 	"cashWise/utils"
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"strconv"
 
@@ -54,6 +56,12 @@ func RegisterUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Стандартні категорії мовою клієнта, щоб одразу можна було додавати транзакції
+	lang := service.RequestLanguage(r.URL.Query().Get("lang"), r.Header.Get("Accept-Language"))
+	if err := service.SeedDefaultCategories(result.UserID, lang); err != nil {
+		log.Printf("Could not create default categories for userID %d: %v", result.UserID, err)
+	}
+
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(result)
 }
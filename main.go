@@ -3,8 +3,10 @@ package main
 
 import (
 	"cashWise/routes"
+	"cashWise/service"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/handlers"
 )
@@ -18,6 +20,7 @@ func main() {
 		handlers.AllowedOrigins([]string{"*"}),                                                                               // Allow access from any origin
 		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),                                         // Allow HTTP methods
 		handlers.AllowedHeaders([]string{"Origin", "Content-Type", "Accept", "Authorization", "ngrok-skip-browser-warning"}), // Include additional headers
+		handlers.ExposedHeaders([]string{"X-Total-Count", "X-Next-Cursor", "Content-Disposition"}),                           // Pagination and download headers
 	)(r)
 
 	// Preflight request handling for OPTIONS method
@@ -32,6 +35,12 @@ func main() {
 		r.ServeHTTP(w, r)
 	})
 
+	// Start the background generator for recurring transactions
+	service.StartRecurringGenerator(time.Hour)
+
+	// Start closing finished budget periods and saving their history
+	service.StartBudgetRollover(time.Hour)
+
 	// Start the server on port 8081
 	log.Println("Server is running on port 8081")
 	log.Fatal(http.ListenAndServe(":8081", corsHandler))
@@ -0,0 +1,16 @@
+package models
+
+// Attachment - файл, прикріплений до транзакції (чек, гарантійний талон, рахунок).
+// Вміст лежить у сховищі файлів за StorageKey, у БД лише опис.
+type Attachment struct {
+	AttachmentID  int    `bson:"attachmentID" json:"attachmentID"`
+	UserID        int    `bson:"userID" json:"userID"`
+	TransactionID int    `bson:"transactionID" json:"transactionID"`
+	FileName      string `bson:"fileName" json:"fileName"`
+	ContentType   string `bson:"contentType" json:"contentType"` // Визначений за вмістом, а не за заголовком запиту
+	Size          int64  `bson:"size" json:"size"`
+	StorageKey    string `bson:"storageKey" json:"-"`
+	ThumbnailKey  string `bson:"thumbnailKey,omitempty" json:"-"`  // Лише для зображень
+	HasThumbnail  bool   `bson:"hasThumbnail" json:"hasThumbnail"` // Мініатюра доступна з thumbnail=true
+	CreatedAt     string `bson:"createdAt" json:"createdAt"`
+}
@@ -1,10 +1,69 @@
 package models
 
 type Budget struct {
-	BudgetID       int     `bson:"budgetID" json:"budgetID"`
-	UserID         int     `bson:"userID" json:"userID"`
-	Name           string  `bson:"name" json:"name"`
-	InitialBalance float64 `bson:"initialBalance" json:"initialBalance"`
-	Limit          float64 `bson:"limit" json:"limit"`
-	Period         string  `bson:"period" json:"period"`
+	BudgetID             int       `bson:"budgetID" json:"budgetID"`
+	UserID               int       `bson:"userID" json:"userID"`
+	Name                 string    `bson:"name" json:"name"`
+	InitialBalance       float64   `bson:"initialBalance" json:"initialBalance"`
+	Limit                float64   `bson:"limit" json:"limit"`
+	Period               string    `bson:"period" json:"period"`                                                 // weekly, monthly, quarterly або yearly
+	StartDate            string    `bson:"startDate,omitempty" json:"startDate,omitempty"`                       // Початок першого періоду (YYYY-MM-DD); без нього періоди календарні
+	CarryOver            string    `bson:"carryOver,omitempty" json:"carryOver,omitempty"`                       // Що переносити в наступний період: unspent, overspent або all
+	CategoryIDs          []int     `bson:"categoryIDs,omitempty" json:"categoryIDs,omitempty"`                   // Порожній список - усі витрати
+	IncludeSubcategories bool      `bson:"includeSubcategories,omitempty" json:"includeSubcategories,omitempty"` // Враховувати й підкатегорії CategoryIDs
+	Thresholds           []float64 `bson:"thresholds,omitempty" json:"thresholds,omitempty"`                     // Пороги сповіщень у відсотках використання, напр. 50, 80, 100
+	PeriodStart          string    `bson:"periodStart,omitempty" json:"periodStart,omitempty"`                   // Початок поточного, ще не закритого періоду
+	CarriedOver          float64   `bson:"carriedOver" json:"carriedOver"`                                       // Перенесено в поточний період: залишок (+) або перевитрата (-)
+}
+
+// BudgetStatus - фактичні витрати за бюджетом у поточному періоді
+type BudgetStatus struct {
+	Budget         `bson:",inline"`
+	From           string  `json:"from"`
+	To             string  `json:"to"`
+	Available      float64 `json:"available"` // Ліміт разом з перенесеною сумою
+	Spent          float64 `json:"spent"`
+	Remaining      float64 `json:"remaining"`
+	PercentUsed    float64 `json:"percentUsed"`
+	DaysLeft       int     `json:"daysLeft"`       // Разом із сьогоднішнім днем
+	DailyAllowance float64 `json:"dailyAllowance"` // Скільки можна витрачати на день до кінця періоду
+	Exceeded       bool    `json:"exceeded"`
+}
+
+// BudgetSnapshot - незмінний підсумок закритого періоду бюджету
+type BudgetSnapshot struct {
+	BudgetID    int     `bson:"budgetID" json:"budgetID"`
+	UserID      int     `bson:"userID" json:"userID"`
+	Name        string  `bson:"name" json:"name"`
+	Period      string  `bson:"period" json:"period"`
+	From        string  `bson:"from" json:"from"`
+	To          string  `bson:"to" json:"to"`
+	Limit       float64 `bson:"limit" json:"limit"`
+	CarriedIn   float64 `bson:"carriedIn" json:"carriedIn"` // Перенесено з попереднього періоду
+	Available   float64 `bson:"available" json:"available"`
+	Spent       float64 `bson:"spent" json:"spent"`
+	Remaining   float64 `bson:"remaining" json:"remaining"`
+	PercentUsed float64 `bson:"percentUsed" json:"percentUsed"`
+	Exceeded    bool    `bson:"exceeded" json:"exceeded"`
+	CarriedOut  float64 `bson:"carriedOut" json:"carriedOut"` // Перенесено в наступний період
+	ClosedAt    string  `bson:"closedAt" json:"closedAt"`
+}
+
+// BudgetTemplate - набір бюджетів, що створюються разом як частки цільового доходу.
+// Вбудовані шаблони мають Key замість TemplateID і не зберігаються в БД.
+type BudgetTemplate struct {
+	TemplateID  int                  `bson:"templateID" json:"templateID,omitempty"`
+	Key         string               `bson:"-" json:"key,omitempty"`
+	UserID      int                  `bson:"userID" json:"userID,omitempty"`
+	Name        string               `bson:"name" json:"name"`
+	Description string               `bson:"description,omitempty" json:"description,omitempty"`
+	Items       []BudgetTemplateItem `bson:"items" json:"items"`
+}
+
+// BudgetTemplateItem - бюджет шаблону: частка доходу у відсотках на вибрані категорії
+type BudgetTemplateItem struct {
+	Name                 string  `bson:"name" json:"name"`
+	Percent              float64 `bson:"percent" json:"percent"`
+	CategoryIDs          []int   `bson:"categoryIDs,omitempty" json:"categoryIDs,omitempty"` // Порожній список - усі витрати
+	IncludeSubcategories bool    `bson:"includeSubcategories,omitempty" json:"includeSubcategories,omitempty"`
 }
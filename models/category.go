@@ -6,4 +6,35 @@ type Category struct {
 	Name        string `bson:"name" json:"name"`
 	Description string `bson:"description" json:"description"`
 	Icon        string `bson:"icon" json:"icon"`
+	ParentID    int    `bson:"parentID,omitempty" json:"parentID,omitempty"` // Батьківська категорія; 0 - верхній рівень
+	Archived    bool   `bson:"archived,omitempty" json:"archived,omitempty"` // Прихована зі списків, але лишається в історії
+}
+
+// CategoryUsage - скільки записів кожного виду посилаються на категорію: transactions, rules, subcategories, ...
+type CategoryUsage map[string]int64
+
+// CategoryNode - категорія з підкатегоріями для дерева категорій
+type CategoryNode struct {
+	Category `bson:",inline"`
+	Children []CategoryNode `json:"children"`
+}
+
+// CategoryRollup - суми категорії у звіті: власні транзакції та разом з усіма підкатегоріями
+type CategoryRollup struct {
+	CategoryID  int              `json:"categoryID"`
+	ParentID    int              `json:"parentID,omitempty"`
+	Name        string           `json:"name"`
+	Total       float64          `json:"total"` // Лише транзакції самої категорії
+	Count       int              `json:"count"`
+	RollupTotal float64          `json:"rollupTotal"` // Разом з підкатегоріями
+	RollupCount int              `json:"rollupCount"`
+	Children    []CategoryRollup `json:"children"`
+}
+
+// CategorySuggestion - категорія, запропонована для опису транзакції, з упевненістю від 0 до 1
+type CategorySuggestion struct {
+	CategoryID int     `json:"categoryID"`
+	Name       string  `json:"name"`
+	Icon       string  `json:"icon"`
+	Confidence float64 `json:"confidence"`
 }
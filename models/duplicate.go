@@ -0,0 +1,13 @@
+package models
+
+// DuplicateGroup - транзакція та її ймовірні дублікати
+type DuplicateGroup struct {
+	Original   Transaction   `json:"original"`
+	Duplicates []Transaction `json:"duplicates"`
+}
+
+// DuplicateMerge - запит на злиття дублікатів в одну транзакцію
+type DuplicateMerge struct {
+	KeepID   int   `json:"keepID"`   // Транзакція, яка залишається
+	MergeIDs []int `json:"mergeIDs"` // Транзакції, які видаляються після злиття
+}
@@ -0,0 +1,32 @@
+package models
+
+// EnvelopeMovement - переміщення грошей у конвертному бюджеті. Категорія 0 - нерозподілений
+// пул доходів: з нього гроші розподіляються в конверти і в нього ж повертаються.
+type EnvelopeMovement struct {
+	MovementID     int     `bson:"movementID" json:"movementID"`
+	UserID         int     `bson:"userID" json:"userID"`
+	FromCategoryID int     `bson:"fromCategoryID" json:"fromCategoryID"`
+	ToCategoryID   int     `bson:"toCategoryID" json:"toCategoryID"`
+	Amount         float64 `bson:"amount" json:"amount"`
+	Month          string  `bson:"month" json:"month"` // YYYY-MM, місяць, у якому гроші розподілено
+	Note           string  `bson:"note,omitempty" json:"note,omitempty"`
+	CreatedAt      string  `bson:"createdAt" json:"createdAt"`
+}
+
+// Envelope - стан конверта категорії за місяць
+type Envelope struct {
+	CategoryID int     `json:"categoryID"`
+	Name       string  `json:"name"`
+	Carried    float64 `json:"carried"`  // Залишок з попередніх місяців
+	Assigned   float64 `json:"assigned"` // Розподілено в конверт за місяць мінус переміщене з нього
+	Spent      float64 `json:"spent"`
+	Available  float64 `json:"available"`
+}
+
+// EnvelopeSummary - конвертний бюджет користувача за місяць
+type EnvelopeSummary struct {
+	Month      string     `json:"month"`
+	Income     float64    `json:"income"`     // Доходи за місяць
+	Unassigned float64    `json:"unassigned"` // Ще не розподілені гроші на кінець місяця
+	Envelopes  []Envelope `json:"envelopes"`
+}
@@ -0,0 +1,99 @@
+package models
+
+// ImportProfile - збережене зіставлення колонок виписки конкретного банку
+type ImportProfile struct {
+	ProfileID         int    `bson:"profileID" json:"profileID"`
+	UserID            int    `bson:"userID" json:"userID"`
+	Name              string `bson:"name" json:"name"`                                             // Назва банку або профілю
+	Delimiter         string `bson:"delimiter,omitempty" json:"delimiter,omitempty"`               // Порожній - визначити автоматично
+	Encoding          string `bson:"encoding,omitempty" json:"encoding,omitempty"`                 // utf-8, windows-1251; порожній - автоматично
+	DateFormat        string `bson:"dateFormat,omitempty" json:"dateFormat,omitempty"`             // Формат Go, порожній - автоматично
+	DecimalSeparator  string `bson:"decimalSeparator,omitempty" json:"decimalSeparator,omitempty"` // "," або "."; порожній - автоматично
+	SkipRows          int    `bson:"skipRows,omitempty" json:"skipRows,omitempty"`                 // Рядки перед заголовком
+	NoHeader          bool   `bson:"noHeader,omitempty" json:"noHeader,omitempty"`
+	DateColumn        *int   `bson:"dateColumn,omitempty" json:"dateColumn,omitempty"` // Номери колонок з 0
+	AmountColumn      *int   `bson:"amountColumn,omitempty" json:"amountColumn,omitempty"`
+	DebitColumn       *int   `bson:"debitColumn,omitempty" json:"debitColumn,omitempty"`   // Окрема колонка списань
+	CreditColumn      *int   `bson:"creditColumn,omitempty" json:"creditColumn,omitempty"` // Окрема колонка зарахувань
+	DescriptionColumn *int   `bson:"descriptionColumn,omitempty" json:"descriptionColumn,omitempty"`
+	InvertSign        bool   `bson:"invertSign,omitempty" json:"invertSign,omitempty"` // Витрати у виписці додатні
+	DefaultCategoryID int    `bson:"defaultCategoryID,omitempty" json:"defaultCategoryID,omitempty"`
+	Account           string `bson:"account,omitempty" json:"account,omitempty"`
+}
+
+// ImportRow - один розібраний рядок виписки
+type ImportRow struct {
+	Line             int      `json:"line"`
+	Date             string   `json:"date"`
+	Amount           float64  `json:"amount"` // Зі знаком: від'ємна - витрата
+	Description      string   `json:"description"`
+	CategoryID       int      `json:"categoryID,omitempty"`
+	Account          string   `json:"account,omitempty"`
+	Currency         string   `json:"currency,omitempty"`
+	ExternalID       string   `json:"externalID,omitempty"` // FITID або інший ідентифікатор операції з файлу
+	Counterparty     string   `json:"counterparty,omitempty"`
+	CounterpartyIBAN string   `json:"counterpartyIBAN,omitempty"`
+	ValueDate        string   `json:"valueDate,omitempty"`
+	BankReference    string   `json:"bankReference,omitempty"`
+	Tags             []string `json:"tags,omitempty"`        // Теги, додані правилами категоризації
+	PayeeID          int      `json:"payeeID,omitempty"`     // Відомий отримувач, знайдений за описом
+	Duplicate        bool     `json:"duplicate,omitempty"`   // Операцію вже імпортовано раніше
+	DuplicateOf      int      `json:"duplicateOf,omitempty"` // Схожа наявна транзакція; рядок буде створено з позначкою
+	Error            string   `json:"error,omitempty"`
+}
+
+// StatementAccount - дані рахунку з заголовка виписки
+type StatementAccount struct {
+	BankID      string   `json:"bankID,omitempty"`
+	AccountID   string   `json:"accountID,omitempty"`
+	AccountType string   `json:"accountType,omitempty"` // CHECKING, SAVINGS, CREDITCARD, Bank, CCard, ...
+	Name        string   `json:"name,omitempty"`
+	Currency    string   `json:"currency,omitempty"`
+	Balance     *float64 `json:"balance,omitempty"` // Залишок на дату BalanceDate
+	BalanceDate string   `json:"balanceDate,omitempty"`
+}
+
+// StatementBalance - звірка залишків однієї виписки
+type StatementBalance struct {
+	Account        string   `bson:"account" json:"account"`
+	Currency       string   `bson:"currency,omitempty" json:"currency,omitempty"`
+	From           string   `bson:"from" json:"from"` // Дата початкового залишку
+	To             string   `bson:"to" json:"to"`     // Дата кінцевого залишку
+	OpeningBalance float64  `bson:"openingBalance" json:"openingBalance"`
+	ClosingBalance float64  `bson:"closingBalance" json:"closingBalance"`
+	EntriesTotal   float64  `bson:"entriesTotal" json:"entriesTotal"`                         // Сума проведених операцій зі знаком
+	AccountBalance *float64 `bson:"accountBalance,omitempty" json:"accountBalance,omitempty"` // Залишок рахунку в системі на початок виписки
+	Difference     float64  `bson:"difference,omitempty" json:"difference,omitempty"`         // OpeningBalance - AccountBalance
+	Matches        bool     `bson:"matches" json:"matches"`                                   // Початковий залишок збігається з обчисленим
+}
+
+// ImportPreview - результат розбору файлу до збереження
+type ImportPreview struct {
+	Delimiter  string             `json:"delimiter,omitempty"`
+	Encoding   string             `json:"encoding,omitempty"`
+	DateFormat string             `json:"dateFormat,omitempty"`
+	Header     []string           `json:"header,omitempty"`
+	Profile    *ImportProfile     `json:"profile,omitempty"` // Зіставлення, з яким розібрано файл; його можна зберегти
+	Format     string             `json:"format,omitempty"`
+	Account    *StatementAccount  `json:"account,omitempty"`
+	NewRows    int                `json:"newRows"` // Рядки, які буде створено
+	Duplicates int                `json:"duplicates,omitempty"`
+	Statements []StatementBalance `json:"statements,omitempty"` // Звірка залишків для camt.053 та MT940
+	Rows       []ImportRow        `json:"rows"`
+}
+
+// ImportBatch - одне імпортування, яке можна відкотити
+type ImportBatch struct {
+	BatchID    int                `bson:"batchID" json:"batchID"`
+	UserID     int                `bson:"userID" json:"userID"`
+	Source     string             `bson:"source" json:"source"` // csv, ofx, qif, ...
+	FileName   string             `bson:"fileName" json:"fileName"`
+	CreatedAt  string             `bson:"createdAt" json:"createdAt"`
+	Created    int                `bson:"created" json:"created"`
+	Failed     int                `bson:"failed" json:"failed"`
+	Skipped    int                `bson:"skipped,omitempty" json:"skipped,omitempty"` // Дублікати вже імпортованих операцій
+	Errors     []string           `bson:"errors,omitempty" json:"errors,omitempty"`
+	Statements []StatementBalance `bson:"statements,omitempty" json:"statements,omitempty"`
+	PayeeIDs   []int              `bson:"payeeIDs,omitempty" json:"payeeIDs,omitempty"` // Отримувачі, створені цим імпортом
+	Status     string             `bson:"status" json:"status"`                         // completed, rolledBack
+}
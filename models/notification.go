@@ -0,0 +1,14 @@
+package models
+
+// Notification - сповіщення користувача, збережене для показу в застосунку
+type Notification struct {
+	NotificationID int     `bson:"notificationID" json:"notificationID"`
+	UserID         int     `bson:"userID" json:"userID"`
+	Type           string  `bson:"type" json:"type"` // budgetThreshold
+	Message        string  `bson:"message" json:"message"`
+	BudgetID       int     `bson:"budgetID,omitempty" json:"budgetID,omitempty"`
+	Threshold      float64 `bson:"threshold,omitempty" json:"threshold,omitempty"` // Досягнутий поріг у відсотках
+	Key            string  `bson:"key,omitempty" json:"-"`                         // Ключ, за яким однакове сповіщення надсилається лише раз
+	Read           bool    `bson:"read" json:"read"`
+	CreatedAt      string  `bson:"createdAt" json:"createdAt"`
+}
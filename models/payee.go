@@ -0,0 +1,42 @@
+package models
+
+// Payee - продавець або отримувач платежів. Aliases - нормалізовані варіанти опису з виписок,
+// за якими транзакції автоматично прив'язуються до отримувача.
+type Payee struct {
+	PayeeID           int      `bson:"payeeID" json:"payeeID"`
+	UserID            int      `bson:"userID" json:"userID"`
+	Name              string   `bson:"name" json:"name"`
+	Aliases           []string `bson:"aliases" json:"aliases"`
+	DefaultCategoryID int      `bson:"defaultCategoryID,omitempty" json:"defaultCategoryID,omitempty"` // Для транзакцій без категорії
+}
+
+// PayeeMerge - злиття кількох отримувачів в один разом з їхніми псевдонімами та транзакціями
+type PayeeMerge struct {
+	SourceIDs []int `json:"sourceIDs"`
+	TargetID  int   `json:"targetID"`
+}
+
+// PayeeSplit - виділення частини псевдонімів отримувача в нового отримувача
+type PayeeSplit struct {
+	Aliases           []string `json:"aliases"`
+	Name              string   `json:"name"`
+	DefaultCategoryID int      `json:"defaultCategoryID,omitempty"`
+}
+
+// PayeeMonth - витрати в отримувача за місяць
+type PayeeMonth struct {
+	Month string  `bson:"_id" json:"month"` // YYYY-MM
+	Total float64 `bson:"total" json:"total"`
+	Count int     `bson:"count" json:"count"`
+}
+
+// PayeeStats - історія витрат в отримувача
+type PayeeStats struct {
+	Payee     Payee        `json:"payee"`
+	Total     float64      `json:"total"`
+	Count     int          `json:"count"`
+	Average   float64      `json:"average"`
+	FirstDate string       `json:"firstDate,omitempty"`
+	LastDate  string       `json:"lastDate,omitempty"`
+	Months    []PayeeMonth `json:"months"`
+}
@@ -0,0 +1,49 @@
+package models
+
+// RecurringTransaction - шаблон транзакції, що повторюється за розкладом
+type RecurringTransaction struct {
+	RecurringID   int                  `bson:"recurringID" json:"recurringID"`
+	UserID        int                  `bson:"userID" json:"userID"`
+	CategoryID    int                  `bson:"categoryID" json:"categoryID"`
+	Type          string               `bson:"type" json:"type"`
+	Amount        float64              `bson:"amount" json:"amount"`
+	Description   string               `bson:"description" json:"description"`
+	Account       string               `bson:"account,omitempty" json:"account,omitempty"`
+	Currency      string               `bson:"currency,omitempty" json:"currency,omitempty"`
+	Splits        []TransactionSplit   `bson:"splits,omitempty" json:"splits,omitempty"`
+	StartDate     string               `bson:"startDate" json:"startDate"` // YYYY-MM-DD, перше можливе повторення
+	Schedule      Schedule             `bson:"schedule" json:"schedule"`
+	Exceptions    []RecurringException `bson:"exceptions,omitempty" json:"exceptions,omitempty"`
+	LastGenerated string               `bson:"lastGenerated,omitempty" json:"lastGenerated,omitempty"` // Дата останнього створеного повторення
+	Active        bool                 `bson:"active" json:"active"`
+}
+
+// Schedule - правило повторення у стилі RRULE
+type Schedule struct {
+	Frequency string `bson:"frequency" json:"frequency"`                   // daily, weekly, monthly, yearly
+	Interval  int    `bson:"interval,omitempty" json:"interval,omitempty"` // Кожні N періодів, за замовчуванням 1
+	Weekdays  []int  `bson:"weekdays,omitempty" json:"weekdays,omitempty"` // Для weekly: 0 - неділя ... 6 - субота
+	MonthDay  int    `bson:"monthDay,omitempty" json:"monthDay,omitempty"` // Для monthly: день місяця, -1 - останній день
+	EndDate   string `bson:"endDate,omitempty" json:"endDate,omitempty"`   // Останнє можливе повторення (включно)
+	Count     int    `bson:"count,omitempty" json:"count,omitempty"`       // Максимальна кількість повторень
+}
+
+// RecurringException - пропуск або зміна одного повторення
+type RecurringException struct {
+	Date        string  `bson:"date" json:"date"`
+	Skip        bool    `bson:"skip" json:"skip"`
+	Amount      float64 `bson:"amount,omitempty" json:"amount,omitempty"`
+	Description string  `bson:"description,omitempty" json:"description,omitempty"`
+	CategoryID  int     `bson:"categoryID,omitempty" json:"categoryID,omitempty"`
+}
+
+// Occurrence - одне заплановане повторення шаблону
+type Occurrence struct {
+	RecurringID int     `json:"recurringID"`
+	Date        string  `json:"date"`
+	CategoryID  int     `json:"categoryID"`
+	Type        string  `json:"type"`
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description"`
+	Skipped     bool    `json:"skipped"`
+}
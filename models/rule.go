@@ -0,0 +1,54 @@
+package models
+
+// CategoryRule - правило автоматичної категоризації. Правила перевіряються за зростанням Priority;
+// кожна умова, що задана, має виконуватися, а дії застосовуються до транзакції, що підпадає під усі умови.
+type CategoryRule struct {
+	RuleID         int            `bson:"ruleID" json:"ruleID"`
+	UserID         int            `bson:"userID" json:"userID"`
+	Name           string         `bson:"name" json:"name"`
+	Priority       int            `bson:"priority" json:"priority"` // Менше значення - вища пріоритетність
+	Disabled       bool           `bson:"disabled,omitempty" json:"disabled,omitempty"`
+	StopProcessing bool           `bson:"stopProcessing,omitempty" json:"stopProcessing,omitempty"` // Не перевіряти наступні правила
+	Conditions     RuleConditions `bson:"conditions" json:"conditions"`
+	Actions        RuleActions    `bson:"actions" json:"actions"`
+}
+
+// RuleConditions - умови правила; порожні поля не обмежують вибірку
+type RuleConditions struct {
+	DescriptionContains string   `bson:"descriptionContains,omitempty" json:"descriptionContains,omitempty"` // Без урахування регістру
+	DescriptionRegex    string   `bson:"descriptionRegex,omitempty" json:"descriptionRegex,omitempty"`       // Синтаксис Go regexp
+	MinAmount           *float64 `bson:"minAmount,omitempty" json:"minAmount,omitempty"`                     // Сума без знаку, включно
+	MaxAmount           *float64 `bson:"maxAmount,omitempty" json:"maxAmount,omitempty"`
+	Account             string   `bson:"account,omitempty" json:"account,omitempty"`
+	Type                string   `bson:"type,omitempty" json:"type,omitempty"` // income, expense, goal
+}
+
+// RuleActions - зміни, які правило вносить у транзакцію
+type RuleActions struct {
+	CategoryID  int      `bson:"categoryID,omitempty" json:"categoryID,omitempty"`
+	Tags        []string `bson:"tags,omitempty" json:"tags,omitempty"`               // Додаються до наявних тегів
+	Description string   `bson:"description,omitempty" json:"description,omitempty"` // Новий опис транзакції
+}
+
+// RuleMatch - транзакція, під яку підпадає правило, і результат застосування дій
+type RuleMatch struct {
+	Transaction Transaction `json:"transaction"`
+	Result      Transaction `json:"result"`
+	RuleIDs     []int       `json:"ruleIDs,omitempty"` // Правила, що змінили транзакцію
+}
+
+// RuleTestResult - результат перевірки правила на історії транзакцій
+type RuleTestResult struct {
+	Scanned int         `json:"scanned"`
+	Matched int         `json:"matched"`
+	Changed int         `json:"changed"` // Транзакції, які правило дійсно змінило б
+	Samples []RuleMatch `json:"samples"` // Перші збіги для перегляду
+}
+
+// RuleApplyResult - підсумок застосування правил до наявних транзакцій
+type RuleApplyResult struct {
+	Scanned int         `json:"scanned"`
+	Updated int         `json:"updated"`
+	DryRun  bool        `json:"dryRun,omitempty"`
+	Samples []RuleMatch `json:"samples"`
+}
@@ -0,0 +1,22 @@
+package models
+
+// TransactionSearch - умови пошуку транзакцій; порожні поля не обмежують вибірку
+type TransactionSearch struct {
+	From        string   `bson:"from,omitempty" json:"from,omitempty"` // YYYY-MM-DD включно
+	To          string   `bson:"to,omitempty" json:"to,omitempty"`     // YYYY-MM-DD включно
+	CategoryIDs []int    `bson:"categoryIDs,omitempty" json:"categoryIDs,omitempty"`
+	Types       []string `bson:"types,omitempty" json:"types,omitempty"`
+	Accounts    []string `bson:"accounts,omitempty" json:"accounts,omitempty"`
+	MinAmount   *float64 `bson:"minAmount,omitempty" json:"minAmount,omitempty"`
+	MaxAmount   *float64 `bson:"maxAmount,omitempty" json:"maxAmount,omitempty"`
+	Tags        []string `bson:"tags,omitempty" json:"tags,omitempty"`
+	Text        string   `bson:"text,omitempty" json:"text,omitempty"` // Повнотекстовий пошук за описом
+}
+
+// SavedSearch - збережений пошук, який користувач може запускати повторно
+type SavedSearch struct {
+	SearchID int               `bson:"searchID" json:"searchID"`
+	UserID   int               `bson:"userID" json:"userID"`
+	Name     string            `bson:"name" json:"name"`
+	Query    TransactionSearch `bson:"query" json:"query"`
+}
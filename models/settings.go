@@ -5,4 +5,9 @@ type Settings struct {
 	DarkTheme      bool `bson:"darkTheme"`
 	TermsCondition bool `bson:"termsConditional"`
 	Notifications  bool `bson:"notifications"`
+	// Налаштування періодів: день початку фінансового місяця та перший день тижня (0 - неділя)
+	FiscalMonthStart int  `bson:"fiscalMonthStart,omitempty"`
+	WeekStart        *int `bson:"weekStart,omitempty"`
+	// Перший день конвертного бюджету (YYYY-MM-DD); порожній - режим вимкнено
+	EnvelopeStart string `bson:"envelopeStart,omitempty"`
 }
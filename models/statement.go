@@ -0,0 +1,61 @@
+package models
+
+// MonthlyStatement - місячна виписка користувача для PDF-звіту
+type MonthlyStatement struct {
+	UserID            int                 `json:"userID"`
+	FullName          string              `json:"fullName"`
+	Month             string              `json:"month"` // YYYY-MM
+	From              string              `json:"from"`
+	To                string              `json:"to"`
+	OpeningBalance    float64             `json:"openingBalance"`
+	TotalIncome       float64             `json:"totalIncome"`
+	TotalExpense      float64             `json:"totalExpense"`
+	ClosingBalance    float64             `json:"closingBalance"`
+	Transactions      []StatementLine     `json:"transactions"`
+	IncomeByCategory  []StatementCategory `json:"incomeByCategory"`
+	ExpenseByCategory []StatementCategory `json:"expenseByCategory"`
+	Budgets           []StatementBudget   `json:"budgets"`
+	Goals             []StatementGoal     `json:"goals"`
+}
+
+// StatementLine - транзакція у виписці; сума зі знаком (надходження додатні, списання від'ємні)
+type StatementLine struct {
+	TransactionID int     `json:"transactionID"`
+	Date          string  `json:"date"`
+	Type          string  `json:"type"`
+	Category      string  `json:"category"`
+	Description   string  `json:"description"`
+	Amount        float64 `json:"amount"`
+	Currency      string  `json:"currency,omitempty"`
+}
+
+// StatementCategory - підсумок за категорією у виписці
+type StatementCategory struct {
+	CategoryID int     `json:"categoryID"`
+	Name       string  `json:"name"`
+	Total      float64 `json:"total"`
+	Count      int     `json:"count"`
+}
+
+// StatementBudget - стан бюджету за місяць виписки
+type StatementBudget struct {
+	BudgetID  int     `json:"budgetID"`
+	Name      string  `json:"name"`
+	From      string  `json:"from"` // Період бюджету, що триває на кінець місяця, обмежений кінцем місяця
+	To        string  `json:"to"`
+	Limit     float64 `json:"limit"`
+	Spent     float64 `json:"spent"`
+	Remaining float64 `json:"remaining"`
+	Exceeded  bool    `json:"exceeded"`
+}
+
+// StatementGoal - прогрес цілі на кінець місяця виписки
+type StatementGoal struct {
+	GoalID       int     `json:"goalID"`
+	Name         string  `json:"name"`
+	TargetAmount float64 `json:"targetAmount"`
+	Saved        float64 `json:"saved"`
+	Progress     float64 `json:"progress"` // Відсоток від 0 до 100
+	Deadline     string  `json:"deadline"`
+	Status       string  `json:"status"`
+}
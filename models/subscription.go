@@ -0,0 +1,25 @@
+package models
+
+// DetectedSubscription - регулярний платіж, знайдений в історії витрат
+type DetectedSubscription struct {
+	Key            string  `json:"key"` // Нормалізований опис, за яким згруповано платежі
+	Description    string  `json:"description"`
+	CategoryID     int     `json:"categoryID"`
+	Amount         float64 `json:"amount"` // Середня сума платежу
+	Frequency      string  `json:"frequency"`
+	Interval       int     `json:"interval"`
+	IntervalDays   int     `json:"intervalDays"`
+	Occurrences    int     `json:"occurrences"`
+	LastDate       string  `json:"lastDate"`
+	NextDate       string  `json:"nextDate"`
+	MonthlyCost    float64 `json:"monthlyCost"`
+	TransactionIDs []int   `json:"transactionIDs"`
+}
+
+// SubscriptionDecision - рішення користувача щодо знайденого платежу
+type SubscriptionDecision struct {
+	UserID      int    `bson:"userID" json:"userID"`
+	Key         string `bson:"key" json:"key"`
+	Status      string `bson:"status" json:"status"` // confirmed, dismissed
+	RecurringID int    `bson:"recurringID,omitempty" json:"recurringID,omitempty"`
+}
@@ -0,0 +1,20 @@
+package models
+
+// TagCount - тег і кількість транзакцій з ним, для автодоповнення
+type TagCount struct {
+	Tag   string `bson:"_id" json:"tag"`
+	Count int    `bson:"count" json:"count"`
+}
+
+// TagTotal - сума транзакцій з тегом
+type TagTotal struct {
+	Tag   string  `bson:"_id" json:"tag"`
+	Total float64 `bson:"total" json:"total"`
+	Count int     `bson:"count" json:"count"`
+}
+
+// TagMerge - злиття кількох тегів в один; перейменування - злиття одного тегу
+type TagMerge struct {
+	Sources []string `json:"sources"`
+	Target  string   `json:"target"`
+}
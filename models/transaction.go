@@ -1,11 +1,42 @@
 package models
 
 type Transaction struct {
-	TransactionID int     `bson:"transactionID" json:"transactionID"`
-	UserID        int     `bson:"userID" json:"userID"`
-	CategoryID    int     `bson:"categoryID" json:"categoryID"`
-	Type          string  `bson:"type" json:"type"`
-	Amount        float64 `bson:"amount" json:"amount"`
-	Date          string  `bson:"date" json:"date"`
-	Description   string  `bson:"description" json:"description"`
+	TransactionID    int                `bson:"transactionID" json:"transactionID"`
+	UserID           int                `bson:"userID" json:"userID"`
+	CategoryID       int                `bson:"categoryID" json:"categoryID"`
+	Type             string             `bson:"type" json:"type"`
+	Amount           float64            `bson:"amount" json:"amount"`
+	Date             string             `bson:"date" json:"date"`
+	Description      string             `bson:"description" json:"description"`
+	Account          string             `bson:"account,omitempty" json:"account,omitempty"`
+	Currency         string             `bson:"currency,omitempty" json:"currency,omitempty"`
+	TransferID       int                `bson:"transferID,omitempty" json:"transferID,omitempty"`     // Спільний ID обох частин переказу
+	TransferSide     string             `bson:"transferSide,omitempty" json:"transferSide,omitempty"` // "out" або "in"
+	ExchangeRate     float64            `bson:"exchangeRate,omitempty" json:"exchangeRate,omitempty"`
+	Splits           []TransactionSplit `bson:"splits,omitempty" json:"splits,omitempty"`           // Розбиття суми між кількома категоріями
+	RecurringID      int                `bson:"recurringID,omitempty" json:"recurringID,omitempty"` // Шаблон, з якого створено транзакцію
+	Tags             []string           `bson:"tags,omitempty" json:"tags,omitempty"`
+	ImportBatchID    int                `bson:"importBatchID,omitempty" json:"importBatchID,omitempty"` // Імпорт, яким створено транзакцію
+	ExternalID       string             `bson:"externalID,omitempty" json:"externalID,omitempty"`       // ID операції в банку (FITID), для пошуку дублікатів
+	Counterparty     string             `bson:"counterparty,omitempty" json:"counterparty,omitempty"`   // Платник або отримувач з банківської виписки
+	CounterpartyIBAN string             `bson:"counterpartyIBAN,omitempty" json:"counterpartyIBAN,omitempty"`
+	ValueDate        string             `bson:"valueDate,omitempty" json:"valueDate,omitempty"` // Дата валютування; Date - дата проведення
+	BankReference    string             `bson:"bankReference,omitempty" json:"bankReference,omitempty"`
+	DuplicateOf      int                `bson:"duplicateOf,omitempty" json:"duplicateOf,omitempty"` // Ймовірний дублікат цієї транзакції, чекає на перевірку
+	PayeeID          int                `bson:"payeeID,omitempty" json:"payeeID,omitempty"`         // Продавець або отримувач з довідника
+	GoalID           int                `bson:"goalID,omitempty" json:"goalID,omitempty"`           // Ціль, на яку зроблено внесок (лише для типу goal)
+}
+
+// TransactionSplit - частина транзакції, віднесена до окремої категорії
+type TransactionSplit struct {
+	CategoryID int     `bson:"categoryID" json:"categoryID"`
+	Amount     float64 `bson:"amount" json:"amount"`
+	Memo       string  `bson:"memo,omitempty" json:"memo,omitempty"`
+}
+
+// CategoryTotal - сума транзакцій за категорією
+type CategoryTotal struct {
+	CategoryID int     `bson:"_id" json:"categoryID"`
+	Total      float64 `bson:"total" json:"total"`
+	Count      int     `bson:"count" json:"count"`
 }
@@ -0,0 +1,16 @@
+package models
+
+// Transfer - переказ між рахунками, який зберігається як дві пов'язані транзакції типу "transfer"
+type Transfer struct {
+	TransferID   int     `json:"transferID"`
+	UserID       int     `json:"userID"`
+	FromAccount  string  `json:"fromAccount"`
+	ToAccount    string  `json:"toAccount"`
+	FromCurrency string  `json:"fromCurrency"`
+	ToCurrency   string  `json:"toCurrency"`
+	Amount       float64 `json:"amount"`   // Сума, що списується з рахунку-джерела
+	Rate         float64 `json:"rate"`     // Курс FromCurrency -> ToCurrency
+	ToAmount     float64 `json:"toAmount"` // Сума, що зараховується (Amount * Rate)
+	Date         string  `json:"date"`
+	Description  string  `json:"description"`
+}
@@ -0,0 +1,364 @@
+// Package pdf - мінімальний генератор PDF 1.4: сторінки A4, один шрифт, текст, лінії та заливка
+// прямокутників. Координати задаються від лівого верхнього кута сторінки в пунктах.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Розміри сторінки A4 і поля в пунктах
+const (
+	PageWidth  = 595.0
+	PageHeight = 842.0
+	Margin     = 40.0
+)
+
+// fontCandidates - де шукати шрифт з кирилицею, якщо PDF_FONT_PATH не задано
+var fontCandidates = []string{
+	"/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf",
+	"/usr/share/fonts/truetype/DejaVuSans.ttf",
+	"/usr/share/fonts/dejavu/DejaVuSans.ttf",
+	"/usr/share/fonts/TTF/DejaVuSans.ttf",
+}
+
+var (
+	fontOnce   sync.Once
+	loadedFont *trueTypeFont
+)
+
+// loadFont - завантажує шрифт один раз на процес; nil означає вбудовану Helvetica без кирилиці
+func loadFont() *trueTypeFont {
+	fontOnce.Do(func() {
+		candidates := fontCandidates
+		if path := os.Getenv("PDF_FONT_PATH"); path != "" {
+			candidates = []string{path}
+		}
+		for _, path := range candidates {
+			font, err := loadTrueTypeFont(path)
+			if err == nil {
+				loadedFont = font
+				return
+			}
+			if !os.IsNotExist(err) {
+				log.Printf("Error loading PDF font %s: %v", path, err)
+			}
+		}
+		log.Printf("No TrueType font found for PDF reports, falling back to Helvetica without Cyrillic; set PDF_FONT_PATH to a font with Cyrillic")
+	})
+	return loadedFont
+}
+
+// helveticaWidths - ширини символів ASCII 32..126 стандартного шрифту Helvetica
+var helveticaWidths = []int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// Document - документ, що будується сторінка за сторінкою
+type Document struct {
+	font  *trueTypeFont
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+	used  map[uint16]rune // Використані гліфи для масиву ширин і ToUnicode
+}
+
+// NewDocument - створює документ з першою порожньою сторінкою
+func NewDocument() *Document {
+	return newDocument(loadFont())
+}
+
+// newDocument - документ із заданим шрифтом; nil - Helvetica
+func newDocument(font *trueTypeFont) *Document {
+	doc := &Document{font: font, used: map[uint16]rune{}}
+	doc.AddPage()
+	return doc
+}
+
+// AddPage - починає нову сторінку
+func (d *Document) AddPage() {
+	d.page = &bytes.Buffer{}
+	d.pages = append(d.pages, d.page)
+}
+
+// PageCount - кількість сторінок документа
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// SetPage - робить поточною сторінку з номером index (від 0), напр. щоб дописати колонтитули
+func (d *Document) SetPage(index int) {
+	d.page = d.pages[index]
+}
+
+// CanRender - чи всі символи рядка є в шрифті документа; інакше вони виводяться як "?" або порожні гліфи
+func (d *Document) CanRender(s string) bool {
+	encoder := charmap.Windows1252.NewEncoder()
+	for _, r := range s {
+		if d.font != nil {
+			if d.font.glyph(r) == 0 {
+				return false
+			}
+			continue
+		}
+		if encoded, err := encoder.Bytes([]byte(string(r))); err != nil || len(encoded) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// runeWidth - ширина символу в тисячних частках кегля
+func (d *Document) runeWidth(r rune) int {
+	if d.font != nil {
+		return d.font.advance(d.font.glyph(r))
+	}
+	if r >= 32 && r <= 126 {
+		return helveticaWidths[r-32]
+	}
+	return 556
+}
+
+// TextWidth - ширина рядка в пунктах при кеглі size
+func (d *Document) TextWidth(s string, size float64) float64 {
+	total := 0
+	for _, r := range s {
+		total += d.runeWidth(r)
+	}
+	return float64(total) * size / 1000
+}
+
+// FitText - обрізає рядок до ширини maxWidth, додаючи три крапки
+func (d *Document) FitText(s string, size, maxWidth float64) string {
+	if d.TextWidth(s, size) <= maxWidth {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && d.TextWidth(string(runes)+"…", size) > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimRight(string(runes), " ") + "…"
+}
+
+// encodeText - рядок у вигляді hex-рядка PDF: номери гліфів для TrueType або байти WinAnsi для Helvetica
+func (d *Document) encodeText(s string) string {
+	var out strings.Builder
+	out.WriteByte('<')
+	encoder := charmap.Windows1252.NewEncoder()
+	for _, r := range s {
+		if d.font != nil {
+			glyph := d.font.glyph(r)
+			if _, ok := d.used[glyph]; !ok {
+				d.used[glyph] = r
+			}
+			fmt.Fprintf(&out, "%04X", glyph)
+			continue
+		}
+		encoded, err := encoder.Bytes([]byte(string(r)))
+		if err != nil || len(encoded) != 1 {
+			encoded = []byte{'?'}
+		}
+		fmt.Fprintf(&out, "%02X", encoded[0])
+	}
+	out.WriteByte('>')
+	return out.String()
+}
+
+// Color - колір RGB з компонентами від 0 до 1
+type Color struct{ r, g, b float64 }
+
+var (
+	Black     = Color{0, 0, 0}
+	Gray      = Color{0.45, 0.45, 0.45}
+	LightGray = Color{0.88, 0.88, 0.88}
+	Green     = Color{0.13, 0.55, 0.25}
+	Red       = Color{0.78, 0.16, 0.16}
+)
+
+// text - пише рядок з лівим краєм у x і базовою лінією на y від верху сторінки;
+// жирний шрифт імітується обведенням контуру гліфів
+func (d *Document) Text(x, y, size float64, bold bool, color Color, s string) {
+	if s == "" {
+		return
+	}
+	fmt.Fprintf(d.page, "BT %.3f %.3f %.3f rg %.3f %.3f %.3f RG ", color.r, color.g, color.b, color.r, color.g, color.b)
+	if bold {
+		fmt.Fprintf(d.page, "2 Tr %.2f w ", size*0.03)
+	} else {
+		d.page.WriteString("0 Tr ")
+	}
+	fmt.Fprintf(d.page, "/F1 %.1f Tf %.2f %.2f Td %s Tj ET\n", size, x, PageHeight-y, d.encodeText(s))
+}
+
+// TextRight - пише рядок, вирівняний правим краєм по x
+func (d *Document) TextRight(x, y, size float64, bold bool, color Color, s string) {
+	d.Text(x-d.TextWidth(s, size), y, size, bold, color, s)
+}
+
+// line - горизонтальна або довільна лінія між двома точками
+func (d *Document) Line(x1, y1, x2, y2, width float64, color Color) {
+	fmt.Fprintf(d.page, "q %.3f %.3f %.3f RG %.2f w %.2f %.2f m %.2f %.2f l S Q\n",
+		color.r, color.g, color.b, width, x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// FillRect - залитий прямокутник з лівим верхнім кутом у (x, y)
+func (d *Document) FillRect(x, y, width, height float64, color Color) {
+	fmt.Fprintf(d.page, "q %.3f %.3f %.3f rg %.2f %.2f %.2f %.2f re f Q\n",
+		color.r, color.g, color.b, x, PageHeight-y-height, width, height)
+}
+
+// objectWriter - збирає об'єкти PDF і таблицю зсувів xref
+type objectWriter struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+// add - записує наступний об'єкт; номер об'єкта дорівнює порядку додавання, починаючи з 1
+func (o *objectWriter) add(body string) {
+	o.offsets = append(o.offsets, o.buf.Len())
+	fmt.Fprintf(&o.buf, "%d 0 obj\n%s\nendobj\n", len(o.offsets), body)
+}
+
+// addStream - записує потік; з compress=true дані стискаються FlateDecode
+func (o *objectWriter) addStream(dict string, data []byte, compress bool) error {
+	if compress {
+		var compressed bytes.Buffer
+		writer := zlib.NewWriter(&compressed)
+		if _, err := writer.Write(data); err != nil {
+			return err
+		}
+		if err := writer.Close(); err != nil {
+			return err
+		}
+		data = compressed.Bytes()
+		dict += " /Filter /FlateDecode"
+	}
+	o.offsets = append(o.offsets, o.buf.Len())
+	fmt.Fprintf(&o.buf, "%d 0 obj\n<< %s /Length %d >>\nstream\n", len(o.offsets), dict, len(data))
+	o.buf.Write(data)
+	o.buf.WriteString("\nendstream\nendobj\n")
+	return nil
+}
+
+// widthsArray - масив /W шрифту CID лише для використаних гліфів
+func (d *Document) widthsArray(glyphs []uint16) string {
+	var out strings.Builder
+	out.WriteByte('[')
+	for _, glyph := range glyphs {
+		fmt.Fprintf(&out, "%d [%d] ", glyph, d.font.advance(glyph))
+	}
+	out.WriteByte(']')
+	return out.String()
+}
+
+// toUnicodeCMap - відповідність гліфів символам, щоб текст з PDF можна було копіювати та шукати
+func (d *Document) toUnicodeCMap(glyphs []uint16) []byte {
+	var out bytes.Buffer
+	out.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	var mapped []uint16
+	for _, glyph := range glyphs {
+		if glyph != 0 {
+			mapped = append(mapped, glyph)
+		}
+	}
+	// У блоці bfchar допускається не більше 100 записів
+	for start := 0; start < len(mapped); start += 100 {
+		end := start + 100
+		if end > len(mapped) {
+			end = len(mapped)
+		}
+		fmt.Fprintf(&out, "%d beginbfchar\n", end-start)
+		for _, glyph := range mapped[start:end] {
+			fmt.Fprintf(&out, "<%04X> <", glyph)
+			for _, unit := range utf16.Encode([]rune{d.used[glyph]}) {
+				fmt.Fprintf(&out, "%04X", unit)
+			}
+			out.WriteString(">\n")
+		}
+		out.WriteString("endbfchar\n")
+	}
+	out.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return out.Bytes()
+}
+
+// Write - записує готовий документ у w
+func (d *Document) Write(w io.Writer) error {
+	objects := &objectWriter{}
+	objects.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1 - каталог, 2 - дерево сторінок, 3 - шрифт; далі об'єкти шрифту та пари сторінка/вміст
+	objects.add("<< /Type /Catalog /Pages 2 0 R >>")
+
+	firstPage := 4
+	if d.font != nil {
+		firstPage = 8
+	}
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+i*2)
+	}
+	objects.add(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+
+	if d.font == nil {
+		objects.add("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	} else {
+		glyphs := make([]uint16, 0, len(d.used))
+		for glyph := range d.used {
+			glyphs = append(glyphs, glyph)
+		}
+		sort.Slice(glyphs, func(i, j int) bool { return glyphs[i] < glyphs[j] })
+
+		font := d.font
+		objects.add(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H "+
+			"/DescendantFonts [4 0 R] /ToUnicode 7 0 R >>", font.name))
+		objects.add(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s "+
+			"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> "+
+			"/FontDescriptor 5 0 R /DW 1000 /W %s /CIDToGIDMap /Identity >>", font.name, d.widthsArray(glyphs)))
+		objects.add(fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] "+
+			"/ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 6 0 R >>",
+			font.name, font.scale(font.bbox[0]), font.scale(font.bbox[1]), font.scale(font.bbox[2]), font.scale(font.bbox[3]),
+			font.scale(font.ascent), font.scale(font.descent), font.scale(font.capHeight)))
+		if err := objects.addStream(fmt.Sprintf("/Length1 %d /Filter /FlateDecode", font.length), font.data, false); err != nil {
+			return err
+		}
+		if err := objects.addStream("", d.toUnicodeCMap(glyphs), true); err != nil {
+			return err
+		}
+	}
+
+	for i, page := range d.pages {
+		objects.add(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", PageWidth, PageHeight, firstPage+i*2+1))
+		if err := objects.addStream("", page.Bytes(), true); err != nil {
+			return err
+		}
+	}
+
+	xref := objects.buf.Len()
+	fmt.Fprintf(&objects.buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects.offsets)+1)
+	for _, offset := range objects.offsets {
+		fmt.Fprintf(&objects.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&objects.buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects.offsets)+1, xref)
+
+	_, err := w.Write(objects.buf.Bytes())
+	return err
+}
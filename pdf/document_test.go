@@ -0,0 +1,136 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// checkXref - перевіряє, що кожен запис xref вказує на початок свого об'єкта
+func checkXref(t *testing.T, data []byte) {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatal("document has no PDF header or EOF marker")
+	}
+
+	match := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(data)
+	if match == nil {
+		t.Fatal("startxref not found")
+	}
+	xref, _ := strconv.Atoi(string(match[1]))
+	if !bytes.HasPrefix(data[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point to the xref table", xref)
+	}
+
+	lines := strings.Split(string(data[xref:]), "\n")
+	var count int
+	fmt.Sscanf(lines[1], "0 %d", &count)
+	if count < 2 {
+		t.Fatalf("xref has %d entries", count)
+	}
+	for i := 1; i < count; i++ {
+		offset, err := strconv.Atoi(strings.Fields(lines[2+i])[0])
+		if err != nil {
+			t.Fatalf("xref entry %d: %v", i, err)
+		}
+		if want := fmt.Sprintf("%d 0 obj\n", i); !bytes.HasPrefix(data[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points to %q", i, data[offset:offset+10])
+		}
+	}
+}
+
+func TestDocumentWriteHelvetica(t *testing.T) {
+	doc := newDocument(nil)
+	doc.Text(Margin, 60, 12, true, Black, "Monthly statement")
+	doc.Line(Margin, 70, PageWidth-Margin, 70, 0.5, Gray)
+	doc.AddPage()
+	doc.FillRect(Margin, 80, 50, 10, Green)
+	doc.TextRight(PageWidth-Margin, 100, 9, false, Red, "1,234.50")
+
+	var out bytes.Buffer
+	if err := doc.Write(&out); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	checkXref(t, out.Bytes())
+	if !bytes.Contains(out.Bytes(), []byte("/BaseFont /Helvetica")) {
+		t.Error("Helvetica font object not found")
+	}
+	if !bytes.Contains(out.Bytes(), []byte("/Count 2")) {
+		t.Error("page tree does not count 2 pages")
+	}
+}
+
+func TestDocumentWriteTrueType(t *testing.T) {
+	font, err := parseTrueTypeFont(testFont(1000), "Test")
+	if err != nil {
+		t.Fatalf("parseTrueTypeFont: %v", err)
+	}
+	doc := newDocument(font)
+	doc.Text(Margin, 60, 12, false, Black, "ABBA")
+
+	var out bytes.Buffer
+	if err := doc.Write(&out); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	checkXref(t, out.Bytes())
+	for _, want := range []string{"/Subtype /Type0", "/BaseFont /Test", "/W [1 [600] 2 [700] ]"} {
+		if !bytes.Contains(out.Bytes(), []byte(want)) {
+			t.Errorf("output does not contain %q", want)
+		}
+	}
+}
+
+func TestEncodeText(t *testing.T) {
+	if got := newDocument(nil).encodeText("Aé€Ж"); got != "<41E9803F>" {
+		t.Errorf("WinAnsi encodeText = %s, want <41E9803F>", got)
+	}
+
+	font, err := parseTrueTypeFont(testFont(1000), "Test")
+	if err != nil {
+		t.Fatalf("parseTrueTypeFont: %v", err)
+	}
+	doc := newDocument(font)
+	if got := doc.encodeText("BAC"); got != "<000200010000>" {
+		t.Errorf("glyph encodeText = %s, want <000200010000>", got)
+	}
+	if doc.used[1] != 'A' || doc.used[2] != 'B' {
+		t.Errorf("used glyphs = %v", doc.used)
+	}
+}
+
+func TestCanRender(t *testing.T) {
+	helvetica := newDocument(nil)
+	if !helvetica.CanRender("Total: 1,234.50 €") {
+		t.Error("Helvetica should render Latin text")
+	}
+	if helvetica.CanRender("Місячна виписка") {
+		t.Error("Helvetica cannot render Cyrillic")
+	}
+
+	font, err := parseTrueTypeFont(testFont(1000), "Test")
+	if err != nil {
+		t.Fatalf("parseTrueTypeFont: %v", err)
+	}
+	doc := newDocument(font)
+	if !doc.CanRender("ABBA") || doc.CanRender("ABC") {
+		t.Error("CanRender does not follow the font cmap")
+	}
+}
+
+func TestFitText(t *testing.T) {
+	doc := newDocument(nil)
+	if got := doc.FitText("Rent", 10, 100); got != "Rent" {
+		t.Errorf("FitText shortened text that fits: %q", got)
+	}
+
+	got := doc.FitText("Groceries and household goods", 10, 60)
+	if !strings.HasSuffix(got, "…") {
+		t.Errorf("FitText(%q) has no ellipsis", got)
+	}
+	if width := doc.TextWidth(got, 10); width > 60 {
+		t.Errorf("FitText result is %.1f wide, want at most 60", width)
+	}
+}
@@ -0,0 +1,243 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// trueTypeFont - метрики шрифту TrueType, потрібні для вбудовування в PDF
+type trueTypeFont struct {
+	data       []byte // Файл шрифту, стиснутий zlib для FontFile2
+	length     int    // Розмір нестиснутого файлу (Length1)
+	name       string
+	unitsPerEm int
+	bbox       [4]int
+	ascent     int
+	descent    int
+	capHeight  int
+	advances   []uint16        // Ширина кожного гліфа в одиницях шрифту
+	glyphs     map[rune]uint16 // Символ -> номер гліфа
+}
+
+// loadTrueTypeFont - читає файл шрифту і розбирає його
+func loadTrueTypeFont(path string) (*trueTypeFont, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseTrueTypeFont(data, fontName(path))
+}
+
+// parseTrueTypeFont - розбирає таблиці head, hhea, hmtx, maxp, cmap та OS/2 шрифту з ім'ям name
+func parseTrueTypeFont(data []byte, name string) (*trueTypeFont, error) {
+	if len(data) < 12 {
+		return nil, errors.New("font file is too short")
+	}
+
+	tables := map[string][]byte{}
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < numTables; i++ {
+		record := 12 + i*16
+		if record+16 > len(data) {
+			return nil, errors.New("invalid font table directory")
+		}
+		tag := string(data[record : record+4])
+		offset := int(binary.BigEndian.Uint32(data[record+8:]))
+		length := int(binary.BigEndian.Uint32(data[record+12:]))
+		if offset+length > len(data) {
+			return nil, fmt.Errorf("font table %s is out of bounds", tag)
+		}
+		tables[tag] = data[offset : offset+length]
+	}
+	// Мінімальні розміри таблиць, з яких читаються поля нижче
+	for _, table := range []struct {
+		tag  string
+		size int
+	}{{"head", 44}, {"hhea", 36}, {"hmtx", 0}, {"maxp", 6}, {"cmap", 4}} {
+		if tables[table.tag] == nil {
+			return nil, fmt.Errorf("font has no %s table", table.tag)
+		}
+		if len(tables[table.tag]) < table.size {
+			return nil, fmt.Errorf("font table %s is truncated", table.tag)
+		}
+	}
+
+	font := &trueTypeFont{length: len(data), name: name, glyphs: map[rune]uint16{}}
+
+	head := tables["head"]
+	font.unitsPerEm = int(binary.BigEndian.Uint16(head[18:]))
+	if font.unitsPerEm == 0 {
+		return nil, fmt.Errorf("font has zero unitsPerEm")
+	}
+	for i := range font.bbox {
+		font.bbox[i] = int(int16(binary.BigEndian.Uint16(head[36+i*2:])))
+	}
+
+	hhea := tables["hhea"]
+	font.ascent = int(int16(binary.BigEndian.Uint16(hhea[4:])))
+	font.descent = int(int16(binary.BigEndian.Uint16(hhea[6:])))
+	font.capHeight = font.ascent
+	if os2 := tables["OS/2"]; len(os2) >= 90 && binary.BigEndian.Uint16(os2) >= 2 {
+		font.capHeight = int(int16(binary.BigEndian.Uint16(os2[88:])))
+	}
+
+	numGlyphs := int(binary.BigEndian.Uint16(tables["maxp"][4:]))
+	numMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	hmtx := tables["hmtx"]
+	if numMetrics == 0 || len(hmtx) < numMetrics*4 {
+		return nil, errors.New("invalid hmtx table")
+	}
+	font.advances = make([]uint16, numGlyphs)
+	for i := range font.advances {
+		// Гліфи після numMetrics мають ширину останнього запису
+		metric := i
+		if metric >= numMetrics {
+			metric = numMetrics - 1
+		}
+		font.advances[i] = binary.BigEndian.Uint16(hmtx[metric*4:])
+	}
+
+	if err := font.parseCmap(tables["cmap"]); err != nil {
+		return nil, err
+	}
+
+	// Шрифт вбудовується повністю, тому стискаємо його один раз при завантаженні
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	font.data = compressed.Bytes()
+	return font, nil
+}
+
+// fontName - ім'я шрифту для PDF з назви файлу, лише з латинських літер і цифр
+func fontName(path string) string {
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' {
+			return r
+		}
+		return -1
+	}, base)
+	if name == "" {
+		return "EmbeddedFont"
+	}
+	return name
+}
+
+// parseCmap - читає таблицю символів Unicode (формат 12 або 4)
+func (f *trueTypeFont) parseCmap(cmap []byte) error {
+	numTables := int(binary.BigEndian.Uint16(cmap[2:]))
+	format4, format12 := -1, -1
+	for i := 0; i < numTables; i++ {
+		record := 4 + i*8
+		if record+8 > len(cmap) {
+			return errors.New("invalid cmap table")
+		}
+		platform := binary.BigEndian.Uint16(cmap[record:])
+		encoding := binary.BigEndian.Uint16(cmap[record+2:])
+		offset := int(binary.BigEndian.Uint32(cmap[record+4:]))
+		if offset+2 > len(cmap) {
+			continue
+		}
+		switch binary.BigEndian.Uint16(cmap[offset:]) {
+		case 12:
+			if platform == 3 && encoding == 10 || platform == 0 {
+				format12 = offset
+			}
+		case 4:
+			if platform == 3 && encoding == 1 || platform == 0 {
+				format4 = offset
+			}
+		}
+	}
+
+	switch {
+	case format12 >= 0:
+		table := cmap[format12:]
+		if len(table) < 16 {
+			return errors.New("invalid cmap format 12 subtable")
+		}
+		groups := int(binary.BigEndian.Uint32(table[12:]))
+		if groups > (len(table)-16)/12 {
+			return errors.New("invalid cmap format 12 subtable")
+		}
+		for i := 0; i < groups; i++ {
+			group := table[16+i*12:]
+			start := binary.BigEndian.Uint32(group)
+			end := binary.BigEndian.Uint32(group[4:])
+			glyph := binary.BigEndian.Uint32(group[8:])
+			for c := start; c <= end && c <= 0x10FFFF; c++ {
+				f.glyphs[rune(c)] = uint16(glyph + c - start)
+			}
+		}
+	case format4 >= 0:
+		table := cmap[format4:]
+		if len(table) < 14 {
+			return errors.New("invalid cmap format 4 subtable")
+		}
+		segCount := int(binary.BigEndian.Uint16(table[6:])) / 2
+		endCodes := 14
+		startCodes := endCodes + segCount*2 + 2
+		idDeltas := startCodes + segCount*2
+		idRangeOffsets := idDeltas + segCount*2
+		if idRangeOffsets+segCount*2 > len(table) {
+			return errors.New("invalid cmap format 4 subtable")
+		}
+		for i := 0; i < segCount; i++ {
+			end := int(binary.BigEndian.Uint16(table[endCodes+i*2:]))
+			start := int(binary.BigEndian.Uint16(table[startCodes+i*2:]))
+			delta := int(binary.BigEndian.Uint16(table[idDeltas+i*2:]))
+			rangeOffset := int(binary.BigEndian.Uint16(table[idRangeOffsets+i*2:]))
+			for c := start; c <= end && c != 0xFFFF; c++ {
+				glyph := 0
+				if rangeOffset == 0 {
+					glyph = (c + delta) & 0xFFFF
+				} else {
+					address := idRangeOffsets + i*2 + rangeOffset + (c-start)*2
+					if address+2 > len(table) {
+						continue
+					}
+					glyph = int(binary.BigEndian.Uint16(table[address:]))
+					if glyph != 0 {
+						glyph = (glyph + delta) & 0xFFFF
+					}
+				}
+				if glyph != 0 {
+					f.glyphs[rune(c)] = uint16(glyph)
+				}
+			}
+		}
+	default:
+		return errors.New("font has no Unicode cmap")
+	}
+	return nil
+}
+
+// glyph - номер гліфа для символу; 0 (.notdef), якщо символу в шрифті немає
+func (f *trueTypeFont) glyph(r rune) uint16 {
+	return f.glyphs[r]
+}
+
+// advance - ширина гліфа в тисячних частках кегля, як очікує PDF
+func (f *trueTypeFont) advance(glyph uint16) int {
+	if int(glyph) >= len(f.advances) {
+		return 0
+	}
+	return int(f.advances[glyph]) * 1000 / f.unitsPerEm
+}
+
+// scale - переводить одиниці шрифту в тисячні частки кегля
+func (f *trueTypeFont) scale(value int) int {
+	return value * 1000 / f.unitsPerEm
+}
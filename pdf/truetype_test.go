@@ -0,0 +1,169 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"os"
+	"sort"
+	"testing"
+)
+
+// testFont - мінімальний шрифт TrueType: гліфи 1 і 2 для символів "A" і "B"
+func testFont(unitsPerEm uint16) []byte {
+	be := binary.BigEndian
+
+	head := make([]byte, 54)
+	be.PutUint16(head[18:], unitsPerEm)
+	for i, value := range []int16{-50, -200, 900, 800} {
+		be.PutUint16(head[36+i*2:], uint16(value))
+	}
+
+	hhea := make([]byte, 36)
+	be.PutUint16(hhea[4:], 800)
+	be.PutUint16(hhea[6:], uint16(0x10000-200))
+	be.PutUint16(hhea[34:], 3)
+
+	maxp := make([]byte, 6)
+	be.PutUint16(maxp[4:], 3)
+
+	hmtx := make([]byte, 12)
+	for i, advance := range []uint16{500, 600, 700} {
+		be.PutUint16(hmtx[i*4:], advance)
+	}
+
+	// cmap формату 4: сегмент "A"-"B" і обов'язковий завершальний сегмент 0xFFFF
+	const segCount = 2
+	subtable := make([]byte, 14+segCount*8+2)
+	be.PutUint16(subtable, 4)
+	be.PutUint16(subtable[2:], uint16(len(subtable)))
+	be.PutUint16(subtable[6:], segCount*2)
+	ends, starts := 14, 14+segCount*2+2
+	deltas := starts + segCount*2
+	be.PutUint16(subtable[ends:], 'B')
+	be.PutUint16(subtable[ends+2:], 0xFFFF)
+	be.PutUint16(subtable[starts:], 'A')
+	be.PutUint16(subtable[starts+2:], 0xFFFF)
+	be.PutUint16(subtable[deltas:], uint16(0x10000+1-'A')) // -64 по модулю 65536
+	be.PutUint16(subtable[deltas+2:], 1)
+	cmap := make([]byte, 12, 12+len(subtable))
+	be.PutUint16(cmap[2:], 1)
+	be.PutUint16(cmap[4:], 3)
+	be.PutUint16(cmap[6:], 1)
+	be.PutUint32(cmap[8:], 12)
+	cmap = append(cmap, subtable...)
+
+	tables := map[string][]byte{"cmap": cmap, "head": head, "hhea": hhea, "hmtx": hmtx, "maxp": maxp}
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	data := make([]byte, 12+len(tags)*16)
+	be.PutUint16(data[4:], uint16(len(tags)))
+	for i, tag := range tags {
+		record := data[12+i*16:]
+		copy(record, tag)
+		be.PutUint32(record[8:], uint32(len(data)))
+		be.PutUint32(record[12:], uint32(len(tables[tag])))
+		data = append(data, tables[tag]...)
+	}
+	return data
+}
+
+func TestParseTrueTypeFont(t *testing.T) {
+	font, err := parseTrueTypeFont(testFont(1000), "Test")
+	if err != nil {
+		t.Fatalf("parseTrueTypeFont: %v", err)
+	}
+
+	for r, want := range map[rune]uint16{'A': 1, 'B': 2, 'C': 0, 'Ж': 0} {
+		if got := font.glyph(r); got != want {
+			t.Errorf("glyph(%q) = %d, want %d", r, got, want)
+		}
+	}
+	if got := font.advance(2); got != 700 {
+		t.Errorf("advance(2) = %d, want 700", got)
+	}
+	if got := font.advance(10); got != 0 {
+		t.Errorf("advance of a missing glyph = %d, want 0", got)
+	}
+	if font.ascent != 800 || font.descent != -200 || font.bbox != [4]int{-50, -200, 900, 800} {
+		t.Errorf("metrics = ascent %d, descent %d, bbox %v", font.ascent, font.descent, font.bbox)
+	}
+}
+
+func TestParseTrueTypeFontScalesUnitsPerEm(t *testing.T) {
+	font, err := parseTrueTypeFont(testFont(2000), "Test")
+	if err != nil {
+		t.Fatalf("parseTrueTypeFont: %v", err)
+	}
+	if got := font.advance(1); got != 300 {
+		t.Errorf("advance(1) = %d, want 300", got)
+	}
+	if got := font.scale(800); got != 400 {
+		t.Errorf("scale(800) = %d, want 400", got)
+	}
+}
+
+func TestParseTrueTypeFontRejectsZeroUnitsPerEm(t *testing.T) {
+	if _, err := parseTrueTypeFont(testFont(0), "Test"); err == nil {
+		t.Fatal("expected an error for unitsPerEm == 0")
+	}
+}
+
+func TestParseTrueTypeFontTruncated(t *testing.T) {
+	data := testFont(1000)
+	for n := 0; n < len(data); n++ {
+		// Обрізаний файл має давати помилку або коректний шрифт, але не паніку
+		parseTrueTypeFont(data[:n], "Test")
+	}
+}
+
+func TestParseTrueTypeFontCorrupted(t *testing.T) {
+	data := testFont(1000)
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		corrupted := append([]byte(nil), data...)
+		for j := 0; j < 4; j++ {
+			corrupted[random.Intn(len(corrupted))] = byte(random.Intn(256))
+		}
+		font, err := parseTrueTypeFont(corrupted, "Test")
+		if err == nil {
+			// Розібраний шрифт має безпечно працювати з будь-яким гліфом
+			font.advance(font.glyph('A'))
+			font.scale(font.ascent)
+		}
+	}
+}
+
+func TestFontName(t *testing.T) {
+	tests := map[string]string{
+		"/usr/share/fonts/DejaVuSans.ttf": "DejaVuSans",
+		"/fonts/Noto Sans-Bold.otf":       "NotoSans-Bold",
+		"/fonts/Шрифт.ttf":                "EmbeddedFont",
+	}
+	for path, want := range tests {
+		if got := fontName(path); got != want {
+			t.Errorf("fontName(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestLoadSystemFont(t *testing.T) {
+	for _, path := range fontCandidates {
+		font, err := loadTrueTypeFont(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("loadTrueTypeFont(%s): %v", path, err)
+		}
+		doc := newDocument(font)
+		if !doc.CanRender("Місячна виписка") {
+			t.Errorf("%s cannot render Cyrillic", path)
+		}
+		return
+	}
+	t.Skip("no system font with Cyrillic installed")
+}
@@ -0,0 +1,60 @@
+package repo
+
+import (
+	"cashWise/db"
+	"context"
+	"fmt"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// GetAccountBalance - обчислює залишок рахунку за транзакціями до дати before (не включно).
+// Другим значенням повертає кількість врахованих транзакцій, щоб відрізнити нульовий залишок від порожньої історії.
+func GetAccountBalance(userID int, account string, before string) (float64, int, error) {
+	match := bson.M{"userID": userID, "account": account}
+	if before != "" {
+		match["date"] = bson.M{"$lt": before}
+	}
+
+	// Доходи та вхідні перекази збільшують залишок, витрати та вихідні перекази - зменшують
+	signedAmount := bson.M{"$cond": bson.A{
+		bson.M{"$or": bson.A{
+			bson.M{"$eq": bson.A{"$type", "income"}},
+			bson.M{"$and": bson.A{
+				bson.M{"$eq": bson.A{"$type", TransferType}},
+				bson.M{"$eq": bson.A{"$transferSide", "in"}},
+			}},
+		}},
+		"$amount",
+		bson.M{"$multiply": bson.A{"$amount", -1}},
+	}}
+
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$group": bson.M{
+			"_id":     nil,
+			"balance": bson.M{"$sum": signedAmount},
+			"count":   bson.M{"$sum": 1},
+		}},
+	}
+
+	cursor, err := db.GetTransactionCollection().Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		log.Printf("Error calculating balance of account %s for userID %d: %v", account, userID, err)
+		return 0, 0, fmt.Errorf("error calculating account balance: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	var result []struct {
+		Balance float64 `bson:"balance"`
+		Count   int     `bson:"count"`
+	}
+	if err := cursor.All(context.TODO(), &result); err != nil {
+		return 0, 0, fmt.Errorf("error decoding account balance: %v", err)
+	}
+	if len(result) == 0 {
+		return 0, 0, nil
+	}
+	return result[0].Balance, result[0].Count, nil
+}
@@ -0,0 +1,156 @@
+package repo
+
+import (
+	"bytes"
+	"cashWise/db"
+	"cashWise/models"
+	"context"
+	"fmt"
+	"io"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SaveAttachment - записує вміст і мініатюру у сховище, потім опис вкладення в БД.
+// Якщо опис зберегти не вдалося, записані файли видаляються.
+func SaveAttachment(attachment models.Attachment, data []byte, thumbnail []byte) (models.Attachment, error) {
+	attachmentID, err := getNextSequence("attachmentID")
+	if err != nil {
+		return attachment, fmt.Errorf("failed to get next attachment ID: %v", err)
+	}
+	attachment.AttachmentID = attachmentID
+	attachment.StorageKey = blobKey(attachment.UserID, attachmentID, "")
+
+	storage := AttachmentStorage()
+	if err := storage.Put(attachment.StorageKey, attachment.ContentType, bytes.NewReader(data)); err != nil {
+		log.Printf("Error storing attachment %d: %v", attachmentID, err)
+		return attachment, fmt.Errorf("error storing attachment: %v", err)
+	}
+	if thumbnail != nil {
+		attachment.ThumbnailKey = blobKey(attachment.UserID, attachmentID, "-thumb")
+		if err := storage.Put(attachment.ThumbnailKey, "image/jpeg", bytes.NewReader(thumbnail)); err != nil {
+			// Без мініатюри вкладення все одно корисне
+			log.Printf("Error storing thumbnail of attachment %d: %v", attachmentID, err)
+			attachment.ThumbnailKey = ""
+		}
+	}
+	attachment.HasThumbnail = attachment.ThumbnailKey != ""
+
+	if _, err := db.GetAttachmentCollection().InsertOne(context.TODO(), attachment); err != nil {
+		log.Printf("Error saving attachment %d: %v", attachmentID, err)
+		deleteAttachmentBlobs([]models.Attachment{attachment})
+		return attachment, fmt.Errorf("error saving attachment: %v", err)
+	}
+	return attachment, nil
+}
+
+// GetAttachments - вкладення транзакції в порядку додавання
+func GetAttachments(userID int, transactionID int) ([]models.Attachment, error) {
+	filter := bson.M{"userID": userID, "transactionID": transactionID}
+	opts := options.Find().SetSort(bson.M{"attachmentID": 1})
+	cursor, err := db.GetAttachmentCollection().Find(context.TODO(), filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching attachments: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	attachments := []models.Attachment{}
+	if err := cursor.All(context.TODO(), &attachments); err != nil {
+		return nil, fmt.Errorf("error decoding attachments: %v", err)
+	}
+	return attachments, nil
+}
+
+// CountAttachments - кількість вкладень транзакції
+func CountAttachments(userID int, transactionID int) (int64, error) {
+	filter := bson.M{"userID": userID, "transactionID": transactionID}
+	return db.GetAttachmentCollection().CountDocuments(context.TODO(), filter)
+}
+
+// GetAttachmentByID - отримує опис вкладення за ID
+func GetAttachmentByID(userID int, attachmentID int) (models.Attachment, error) {
+	var attachment models.Attachment
+	filter := bson.M{"userID": userID, "attachmentID": attachmentID}
+	err := db.GetAttachmentCollection().FindOne(context.TODO(), filter).Decode(&attachment)
+	return attachment, err
+}
+
+// OpenAttachment - відкриває вміст вкладення або його мініатюру для читання
+func OpenAttachment(attachment models.Attachment, thumbnail bool) (io.ReadCloser, error) {
+	key := attachment.StorageKey
+	if thumbnail {
+		if attachment.ThumbnailKey == "" {
+			return nil, ErrBlobNotFound
+		}
+		key = attachment.ThumbnailKey
+	}
+	return AttachmentStorage().Open(key)
+}
+
+// DeleteAttachment - видаляє вкладення з БД і сховища
+func DeleteAttachment(userID int, attachmentID int) error {
+	attachment, err := GetAttachmentByID(userID, attachmentID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"userID": userID, "attachmentID": attachmentID}
+	if _, err := db.GetAttachmentCollection().DeleteOne(context.TODO(), filter); err != nil {
+		return fmt.Errorf("error deleting attachment: %v", err)
+	}
+	deleteAttachmentBlobs([]models.Attachment{attachment})
+	return nil
+}
+
+// deleteTransactionAttachments - прибирає вкладення видалених транзакцій. Транзакції вже видалено,
+// тож помилки лише записуються в журнал: залишені файли не заважають роботі.
+func deleteTransactionAttachments(userID int, transactionIDs []int) {
+	if len(transactionIDs) == 0 {
+		return
+	}
+	filter := bson.M{"userID": userID, "transactionID": bson.M{"$in": transactionIDs}}
+	cursor, err := db.GetAttachmentCollection().Find(context.TODO(), filter)
+	if err != nil {
+		log.Printf("Error fetching attachments of deleted transactions %v: %v", transactionIDs, err)
+		return
+	}
+	var attachments []models.Attachment
+	if err := cursor.All(context.TODO(), &attachments); err != nil {
+		log.Printf("Error decoding attachments of deleted transactions %v: %v", transactionIDs, err)
+		return
+	}
+	if len(attachments) == 0 {
+		return
+	}
+
+	if _, err := db.GetAttachmentCollection().DeleteMany(context.TODO(), filter); err != nil {
+		log.Printf("Error deleting attachments of deleted transactions %v: %v", transactionIDs, err)
+		return
+	}
+	deleteAttachmentBlobs(attachments)
+}
+
+// deleteAttachmentBlobs - видаляє вміст і мініатюри вкладень зі сховища
+func deleteAttachmentBlobs(attachments []models.Attachment) {
+	storage := AttachmentStorage()
+	for _, attachment := range attachments {
+		for _, key := range []string{attachment.StorageKey, attachment.ThumbnailKey} {
+			if key == "" {
+				continue
+			}
+			if err := storage.Delete(key); err != nil {
+				log.Printf("Error deleting blob %s of attachment %d: %v", key, attachment.AttachmentID, err)
+			}
+		}
+	}
+}
+
+// moveAttachments - переносить вкладення на іншу транзакцію в межах сесії, наприклад при злитті дублікатів
+func moveAttachments(sessCtx mongo.SessionContext, userID int, fromIDs []int, toID int) error {
+	filter := bson.M{"userID": userID, "transactionID": bson.M{"$in": fromIDs}}
+	_, err := db.GetAttachmentCollection().UpdateMany(sessCtx, filter, bson.M{"$set": bson.M{"transactionID": toID}})
+	return err
+}
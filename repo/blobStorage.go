@@ -0,0 +1,137 @@
+package repo
+
+import (
+	"cashWise/db"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrBlobNotFound - файла з таким ключем немає у сховищі
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStorage - сховище вмісту вкладень. Ключі формує repo, тож реалізації можуть вважати їх безпечними
+// відносними шляхами з "/" як роздільником.
+type BlobStorage interface {
+	Put(key string, contentType string, data io.Reader) error
+	Open(key string) (io.ReadCloser, error) // ErrBlobNotFound, якщо ключа немає
+	Delete(key string) error                // Відсутній ключ не є помилкою
+}
+
+// LocalBlobStorage - файли в каталозі на диску сервера
+type LocalBlobStorage struct {
+	Dir string
+}
+
+func (s LocalBlobStorage) path(key string) string {
+	return filepath.Join(s.Dir, filepath.FromSlash(key))
+}
+
+// Put - записує файл через тимчасовий, щоб перерваний запис не лишив обрізаного вкладення
+func (s LocalBlobStorage) Put(key string, contentType string, data io.Reader) error {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func (s LocalBlobStorage) Open(key string) (io.ReadCloser, error) {
+	file, err := os.Open(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	return file, err
+}
+
+func (s LocalBlobStorage) Delete(key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// GridFSBlobStorage - файли в GridFS тієї ж бази; ключ слугує ID файла
+type GridFSBlobStorage struct {
+	Bucket *gridfs.Bucket
+}
+
+func (s GridFSBlobStorage) Put(key string, contentType string, data io.Reader) error {
+	opts := options.GridFSUpload().SetMetadata(bson.M{"contentType": contentType})
+	return s.Bucket.UploadFromStreamWithID(key, key, data, opts)
+}
+
+func (s GridFSBlobStorage) Open(key string) (io.ReadCloser, error) {
+	stream, err := s.Bucket.OpenDownloadStream(key)
+	if errors.Is(err, gridfs.ErrFileNotFound) {
+		return nil, ErrBlobNotFound
+	}
+	return stream, err
+}
+
+func (s GridFSBlobStorage) Delete(key string) error {
+	err := s.Bucket.Delete(key)
+	if errors.Is(err, gridfs.ErrFileNotFound) {
+		return nil
+	}
+	return err
+}
+
+var (
+	attachmentStorage     BlobStorage
+	attachmentStorageOnce sync.Once
+)
+
+// SetAttachmentStorage - замінює сховище вкладень, вибране за змінними середовища
+func SetAttachmentStorage(storage BlobStorage) {
+	attachmentStorageOnce.Do(func() {})
+	attachmentStorage = storage
+}
+
+// AttachmentStorage - сховище вкладень: GridFS з ATTACHMENT_STORAGE=gridfs, інакше каталог
+// ATTACHMENT_DIR (за замовчуванням ./attachments)
+func AttachmentStorage() BlobStorage {
+	attachmentStorageOnce.Do(func() {
+		if os.Getenv("ATTACHMENT_STORAGE") == "gridfs" {
+			bucket, err := gridfs.NewBucket(db.Client.Database("cashWiseDB"), options.GridFSBucket().SetName("attachments"))
+			if err == nil {
+				attachmentStorage = GridFSBlobStorage{Bucket: bucket}
+				return
+			}
+			log.Printf("Could not open GridFS bucket, storing attachments on disk: %v", err)
+		}
+		dir := os.Getenv("ATTACHMENT_DIR")
+		if dir == "" {
+			dir = "attachments"
+		}
+		attachmentStorage = LocalBlobStorage{Dir: dir}
+	})
+	return attachmentStorage
+}
+
+// blobKey - ключ вмісту вкладення у сховищі
+func blobKey(userID int, attachmentID int, suffix string) string {
+	return fmt.Sprintf("%d/%d%s", userID, attachmentID, suffix)
+}
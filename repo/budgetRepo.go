@@ -8,17 +8,36 @@ import (
 	"log"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// createBudget - створює новий бюджет у базі даних
-func CreateBudget(newBudget models.Budget) error {
+// CreateBudget - створює новий бюджет у базі даних з ID з лічильника і повертає його
+func CreateBudget(newBudget models.Budget) (models.Budget, error) {
 	collection := db.GetBudgetCollection()
+
+	// Бюджети, створені до появи лічильника, отримували ID від клієнта, тож зайняті ID пропускаємо
+	for {
+		budgetID, err := getNextSequence("budgetID")
+		if err != nil {
+			return newBudget, fmt.Errorf("failed to get next budget ID: %v", err)
+		}
+		taken, err := collection.CountDocuments(context.TODO(), bson.M{"budgetID": budgetID})
+		if err != nil {
+			return newBudget, fmt.Errorf("error checking budget ID: %v", err)
+		}
+		if taken == 0 {
+			newBudget.BudgetID = budgetID
+			break
+		}
+	}
+
 	_, err := collection.InsertOne(context.TODO(), newBudget)
 	if err != nil {
 		log.Printf("Error creating budget: %v", err)
-		return fmt.Errorf("error creating budget: %v", err)
+		return newBudget, fmt.Errorf("error creating budget: %v", err)
 	}
-	return nil
+	return newBudget, nil
 }
 
 // EditBudget - оновлює бюджет у базі даних
@@ -43,6 +62,20 @@ func EditBudget(updatedBudget models.Budget) error {
 	if updatedBudget.Period != "" {
 		update["$set"].(bson.M)["period"] = updatedBudget.Period
 	}
+	if updatedBudget.StartDate != "" {
+		update["$set"].(bson.M)["startDate"] = updatedBudget.StartDate
+	}
+	if updatedBudget.CarryOver != "" {
+		update["$set"].(bson.M)["carryOver"] = updatedBudget.CarryOver
+	}
+	if updatedBudget.Thresholds != nil {
+		update["$set"].(bson.M)["thresholds"] = updatedBudget.Thresholds
+	}
+	// Категорії оновлюються лише разом з прапорцем підкатегорій; порожній список - усі витрати
+	if updatedBudget.CategoryIDs != nil {
+		update["$set"].(bson.M)["categoryIDs"] = updatedBudget.CategoryIDs
+		update["$set"].(bson.M)["includeSubcategories"] = updatedBudget.IncludeSubcategories
+	}
 
 	// Виконуємо оновлення лише якщо є щось для оновлення
 	if len(update["$set"].(bson.M)) == 0 {
@@ -68,6 +101,11 @@ func DeleteBudget(budgetID int) error {
 		log.Printf("Error deleting budget: %v", err)
 		return fmt.Errorf("error deleting budget: %v", err)
 	}
+
+	// Історія без бюджету нікому не потрібна; помилка лише залишить зайві записи
+	if _, err := db.GetBudgetSnapshotCollection().DeleteMany(context.TODO(), filter); err != nil {
+		log.Printf("Error deleting snapshots of budget %d: %v", budgetID, err)
+	}
 	return nil
 }
 
@@ -85,15 +123,177 @@ func GetBudgetByID(budgetID int) (models.Budget, error) {
 	return budget, nil
 }
 
-// checkLimit - перевіряє ліміт бюджету і повертає повідомлення
-func CheckLimit(budgetID int) (string, error) {
-	budget, err := GetBudgetByID(budgetID)
+// GetBudgetSpending - сума витрат користувача за період [from, to]. Розділені транзакції
+// враховуються частинами; порожній categoryIDs означає всі витрати.
+func GetBudgetSpending(userID int, categoryIDs []int, from, to string) (float64, error) {
+	pipeline := []bson.M{{"$match": bson.M{
+		"userID": userID,
+		"type":   "expense",
+		"date":   bson.M{"$gte": from, "$lte": to},
+	}}}
+	pipeline = append(pipeline, splitLinesStages()...)
+	if len(categoryIDs) > 0 {
+		pipeline = append(pipeline, bson.M{"$match": bson.M{"lines.categoryID": bson.M{"$in": categoryIDs}}})
+	}
+	pipeline = append(pipeline, bson.M{"$group": bson.M{"_id": nil, "spent": bson.M{"$sum": "$lines.amount"}}})
+
+	cursor, err := db.GetTransactionCollection().Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		log.Printf("Error aggregating budget spending for userID %d: %v", userID, err)
+		return 0, fmt.Errorf("error aggregating budget spending: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	var result []struct {
+		Spent float64 `bson:"spent"`
+	}
+	if err := cursor.All(context.TODO(), &result); err != nil {
+		return 0, fmt.Errorf("error decoding budget spending: %v", err)
+	}
+	if len(result) == 0 {
+		return 0, nil
+	}
+	return result[0].Spent, nil
+}
+
+// GetBudgetsByUserID - отримує всі бюджети користувача
+func GetBudgetsByUserID(userID int) ([]models.Budget, error) {
+	collection := db.GetBudgetCollection()
+
+	cursor, err := collection.Find(context.TODO(), bson.M{"userID": userID})
+	if err != nil {
+		log.Printf("Error fetching budgets for userID %d: %v", userID, err)
+		return nil, fmt.Errorf("error fetching budgets: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	var budgets []models.Budget
+	if err := cursor.All(context.TODO(), &budgets); err != nil {
+		return nil, fmt.Errorf("error decoding budgets: %v", err)
+	}
+	return budgets, nil
+}
+
+// GetAllBudgets - отримує бюджети всіх користувачів для фонового закриття періодів
+func GetAllBudgets() ([]models.Budget, error) {
+	cursor, err := db.GetBudgetCollection().Find(context.TODO(), bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching budgets: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	var budgets []models.Budget
+	if err := cursor.All(context.TODO(), &budgets); err != nil {
+		return nil, fmt.Errorf("error decoding budgets: %v", err)
+	}
+	return budgets, nil
+}
+
+// SetBudgetPeriod - встановлює початок поточного періоду бюджету та перенесену в нього суму
+func SetBudgetPeriod(budgetID int, periodStart string, carriedOver float64) error {
+	filter := bson.M{"budgetID": budgetID}
+	update := bson.M{"$set": bson.M{"periodStart": periodStart, "carriedOver": carriedOver}}
+	if _, err := db.GetBudgetCollection().UpdateOne(context.TODO(), filter, update); err != nil {
+		return fmt.Errorf("error updating budget period: %v", err)
+	}
+	return nil
+}
+
+// CloseBudgetPeriod - атомарно зберігає підсумок закритого періоду і переводить бюджет на наступний.
+// Якщо період уже закрив інший процес (periodStart бюджету змінився), нічого не робить і повертає false.
+func CloseBudgetPeriod(snapshot models.BudgetSnapshot, nextStart string, carriedOver float64) (bool, error) {
+	closed := false
+	err := runInTransaction(func(sessCtx mongo.SessionContext) error {
+		filter := bson.M{"budgetID": snapshot.BudgetID, "periodStart": snapshot.From}
+		update := bson.M{"$set": bson.M{"periodStart": nextStart, "carriedOver": carriedOver}}
+		result, err := db.GetBudgetCollection().UpdateOne(sessCtx, filter, update)
+		if err != nil {
+			return fmt.Errorf("error advancing budget period: %v", err)
+		}
+		if result.MatchedCount == 0 {
+			return nil
+		}
+
+		if _, err := db.GetBudgetSnapshotCollection().InsertOne(sessCtx, snapshot); err != nil {
+			return fmt.Errorf("error saving budget snapshot: %v", err)
+		}
+		closed = true
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error closing period %s of budget %d: %v", snapshot.From, snapshot.BudgetID, err)
+		return false, err
+	}
+	return closed, nil
+}
+
+// GetBudgetSnapshots - підсумки закритих періодів бюджету, від найновішого
+func GetBudgetSnapshots(budgetID int) ([]models.BudgetSnapshot, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "from", Value: -1}})
+	cursor, err := db.GetBudgetSnapshotCollection().Find(context.TODO(), bson.M{"budgetID": budgetID}, opts)
+	if err != nil {
+		log.Printf("Error fetching snapshots of budget %d: %v", budgetID, err)
+		return nil, fmt.Errorf("error fetching budget history: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	snapshots := []models.BudgetSnapshot{}
+	if err := cursor.All(context.TODO(), &snapshots); err != nil {
+		return nil, fmt.Errorf("error decoding budget history: %v", err)
+	}
+	return snapshots, nil
+}
+
+// CreateBudgetTemplate - зберігає шаблон бюджетів користувача з ID з лічильника
+func CreateBudgetTemplate(template models.BudgetTemplate) (models.BudgetTemplate, error) {
+	templateID, err := getNextSequence("budgetTemplateID")
+	if err != nil {
+		return template, fmt.Errorf("failed to get next template ID: %v", err)
+	}
+	template.TemplateID = templateID
+
+	if _, err := db.GetBudgetTemplateCollection().InsertOne(context.TODO(), template); err != nil {
+		log.Printf("Error creating budget template for userID %d: %v", template.UserID, err)
+		return template, fmt.Errorf("error creating budget template: %v", err)
+	}
+	return template, nil
+}
+
+// GetBudgetTemplates - збережені шаблони бюджетів користувача
+func GetBudgetTemplates(userID int) ([]models.BudgetTemplate, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "templateID", Value: 1}})
+	cursor, err := db.GetBudgetTemplateCollection().Find(context.TODO(), bson.M{"userID": userID}, opts)
 	if err != nil {
-		return "", err
+		log.Printf("Error fetching budget templates for userID %d: %v", userID, err)
+		return nil, fmt.Errorf("error fetching budget templates: %v", err)
 	}
+	defer cursor.Close(context.TODO())
 
-	if budget.InitialBalance > budget.Limit {
-		return "Limit exceeded!", nil
+	templates := []models.BudgetTemplate{}
+	if err := cursor.All(context.TODO(), &templates); err != nil {
+		return nil, fmt.Errorf("error decoding budget templates: %v", err)
 	}
-	return "Limit is within range.", nil
+	return templates, nil
+}
+
+// GetBudgetTemplateByID - збережений шаблон бюджетів користувача за ID
+func GetBudgetTemplateByID(userID int, templateID int) (models.BudgetTemplate, error) {
+	var template models.BudgetTemplate
+	filter := bson.M{"userID": userID, "templateID": templateID}
+	err := db.GetBudgetTemplateCollection().FindOne(context.TODO(), filter).Decode(&template)
+	return template, err
+}
+
+// DeleteBudgetTemplate - видаляє збережений шаблон бюджетів
+func DeleteBudgetTemplate(userID int, templateID int) error {
+	filter := bson.M{"userID": userID, "templateID": templateID}
+	result, err := db.GetBudgetTemplateCollection().DeleteOne(context.TODO(), filter)
+	if err != nil {
+		log.Printf("Error deleting budget template %d: %v", templateID, err)
+		return fmt.Errorf("error deleting budget template: %v", err)
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"cashWise/db"
 	"cashWise/models"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 
@@ -43,15 +44,15 @@ func getNextSequence(sequenceName string) (int, error) {
 	return result.Seq, nil
 }
 
-// AddCategory - додає нову категорію з автоінкрементом і іконкою
-func AddCategory(category models.Category) error {
+// AddCategory - додає нову категорію з автоінкрементом і іконкою та повертає її з присвоєним ID
+func AddCategory(category models.Category) (models.Category, error) {
 	collection := db.GetCategoryCollection()
 
 	// Отримуємо новий CategoryID
 	categoryID, err := getNextSequence("categoryID")
 	if err != nil {
 		log.Printf("Error getting next sequence for categoryID: %v", err)
-		return err
+		return category, err
 	}
 	category.CategoryID = categoryID
 
@@ -65,10 +66,10 @@ func AddCategory(category models.Category) error {
 	_, err = collection.InsertOne(context.TODO(), category)
 	if err != nil {
 		log.Printf("Error inserting category: %v", err)
-		return err
+		return category, err
 	}
 	log.Println("Category added successfully with ID and Icon")
-	return nil
+	return category, nil
 }
 
 // GetCategories - отримує список всіх категорій для користувача
@@ -144,7 +145,27 @@ func UpdateCategory(userID int, categoryID int, updatedCategory models.Category)
 	return nil
 }
 
-// DeleteCategory - видаляє категорію за її ID та userID
+// SetCategoryParent - переносить категорію разом з її підкатегоріями під іншу батьківську; 0 - на верхній рівень
+func SetCategoryParent(userID int, categoryID int, parentID int) error {
+	update := bson.M{"$set": bson.M{"parentID": parentID}}
+	if parentID == 0 {
+		update = bson.M{"$unset": bson.M{"parentID": ""}}
+	}
+
+	filter := bson.M{"userID": userID, "categoryID": categoryID}
+	result, err := db.GetCategoryCollection().UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		log.Printf("Error moving category %d: %v", categoryID, err)
+		return fmt.Errorf("error moving category: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// DeleteCategory - видаляє категорію за її ID та userID. Посилання на неї не змінюються,
+// тож видаляти так можна лише категорію, яка ніде не використовується (див. GetCategoryUsage).
 func DeleteCategory(userID int, categoryID int) error {
 	collection := db.GetCategoryCollection()
 
@@ -152,16 +173,130 @@ func DeleteCategory(userID int, categoryID int) error {
 	filter := bson.M{"userID": userID, "categoryID": categoryID}
 
 	// Видалення категорії з колекції
-	_, err := collection.DeleteOne(context.TODO(), filter)
+	result, err := collection.DeleteOne(context.TODO(), filter)
 	if err != nil {
 		log.Printf("Error deleting category: %v", err)
 		return err
 	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
 
 	log.Println("Category deleted successfully")
 	return nil
 }
 
+// categoryReference - поле документів, що посилається на категорію. Для масивів path містить
+// позиційний оператор $[ref], а arrayFilter - умову на елемент масиву.
+type categoryReference struct {
+	kind        string // Ключ у models.CategoryUsage
+	collection  *mongo.Collection
+	field       string // Поле для пошуку
+	path        string // Поле для оновлення
+	arrayFilter string // Умова для $[ref]; порожня для звичайних полів
+}
+
+// categoryReferences - усі місця, де зберігається ID категорії
+func categoryReferences() []categoryReference {
+	return []categoryReference{
+		{"transactions", db.GetTransactionCollection(), "categoryID", "categoryID", ""},
+		{"transactionSplits", db.GetTransactionCollection(), "splits.categoryID", "splits.$[ref].categoryID", "ref.categoryID"},
+		{"recurring", db.GetRecurringCollection(), "categoryID", "categoryID", ""},
+		{"recurringSplits", db.GetRecurringCollection(), "splits.categoryID", "splits.$[ref].categoryID", "ref.categoryID"},
+		{"recurringExceptions", db.GetRecurringCollection(), "exceptions.categoryID", "exceptions.$[ref].categoryID", "ref.categoryID"},
+		{"rules", db.GetRuleCollection(), "actions.categoryID", "actions.categoryID", ""},
+		{"payees", db.GetPayeeCollection(), "defaultCategoryID", "defaultCategoryID", ""},
+		{"importProfiles", db.GetImportProfileCollection(), "defaultCategoryID", "defaultCategoryID", ""},
+		{"savedSearches", db.GetSavedSearchCollection(), "query.categoryIDs", "query.categoryIDs.$[ref]", "ref"},
+		{"budgets", db.GetBudgetCollection(), "categoryIDs", "categoryIDs.$[ref]", "ref"},
+		{"budgetTemplates", db.GetBudgetTemplateCollection(), "items.categoryIDs", "items.$[].categoryIDs.$[ref]", "ref"},
+		{"envelopeMovementsFrom", db.GetEnvelopeMovementCollection(), "fromCategoryID", "fromCategoryID", ""},
+		{"envelopeMovementsTo", db.GetEnvelopeMovementCollection(), "toCategoryID", "toCategoryID", ""},
+	}
+}
+
+// GetCategoryUsage - рахує записи, що посилаються на категорію, разом з її підкатегоріями
+func GetCategoryUsage(userID int, categoryID int) (models.CategoryUsage, error) {
+	usage := models.CategoryUsage{}
+	for _, reference := range categoryReferences() {
+		count, err := reference.collection.CountDocuments(context.TODO(), bson.M{"userID": userID, reference.field: categoryID})
+		if err != nil {
+			return nil, fmt.Errorf("error counting %s of category: %v", reference.kind, err)
+		}
+		usage[reference.kind] = count
+	}
+
+	count, err := db.GetCategoryCollection().CountDocuments(context.TODO(), bson.M{"userID": userID, "parentID": categoryID})
+	if err != nil {
+		return nil, fmt.Errorf("error counting subcategories: %v", err)
+	}
+	usage["subcategories"] = count
+	return usage, nil
+}
+
+// ReplaceCategory - атомарно переводить усі посилання з категорії на replacementID, переносить її
+// підкатегорії до її батьківської категорії і видаляє її. Повертає кількість змінених записів.
+func ReplaceCategory(userID int, categoryID int, replacementID int) (models.CategoryUsage, error) {
+	usage := models.CategoryUsage{}
+	err := runInTransaction(func(sessCtx mongo.SessionContext) error {
+		var category models.Category
+		filter := bson.M{"userID": userID, "categoryID": categoryID}
+		if err := db.GetCategoryCollection().FindOne(sessCtx, filter).Decode(&category); err != nil {
+			return err
+		}
+
+		for _, reference := range categoryReferences() {
+			update := bson.M{"$set": bson.M{reference.path: replacementID}}
+			opts := options.Update()
+			if reference.arrayFilter != "" {
+				opts.SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{reference.arrayFilter: categoryID}}})
+			}
+			result, err := reference.collection.UpdateMany(sessCtx, bson.M{"userID": userID, reference.field: categoryID}, update, opts)
+			if err != nil {
+				return fmt.Errorf("error reassigning %s: %v", reference.kind, err)
+			}
+			usage[reference.kind] = result.ModifiedCount
+		}
+
+		childUpdate := bson.M{"$set": bson.M{"parentID": category.ParentID}}
+		if category.ParentID == 0 {
+			childUpdate = bson.M{"$unset": bson.M{"parentID": ""}}
+		}
+		result, err := db.GetCategoryCollection().UpdateMany(sessCtx, bson.M{"userID": userID, "parentID": categoryID}, childUpdate)
+		if err != nil {
+			return fmt.Errorf("error moving subcategories: %v", err)
+		}
+		usage["subcategories"] = result.ModifiedCount
+
+		_, err = db.GetCategoryCollection().DeleteOne(sessCtx, filter)
+		return err
+	})
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		log.Printf("Error replacing category %d with %d: %v", categoryID, replacementID, err)
+		return nil, fmt.Errorf("error replacing category: %v", err)
+	}
+	return usage, err
+}
+
+// SetCategoryArchived - архівує категорію замість видалення або повертає її з архіву
+func SetCategoryArchived(userID int, categoryID int, archived bool) error {
+	update := bson.M{"$set": bson.M{"archived": true}}
+	if !archived {
+		update = bson.M{"$unset": bson.M{"archived": ""}}
+	}
+
+	filter := bson.M{"userID": userID, "categoryID": categoryID}
+	result, err := db.GetCategoryCollection().UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		log.Printf("Error archiving category %d: %v", categoryID, err)
+		return fmt.Errorf("error archiving category: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
 // GetAllCategoriesByUserIDLogic - логіка отримання категорій з бази даних
 func GetAllCategoriesByUserIDLogic(userID int) ([]models.Category, error) {
 	collection := db.GetCategoryCollection()
@@ -198,6 +333,15 @@ func GetAllCategoriesByUserIDLogic(userID int) ([]models.Category, error) {
 	return categories, nil
 }
 
+// ListCategories - отримує сторінку категорій користувача з сортуванням у MongoDB; архівні - лише з includeArchived
+func ListCategories(userID int, includeArchived bool, params ListParams) (Page, error) {
+	filter := bson.M{"userID": userID}
+	if !includeArchived {
+		filter["archived"] = bson.M{"$ne": true}
+	}
+	return findPage(db.GetCategoryCollection(), filter, "categoryID", params)
+}
+
 // GetCategoriesByName - отримує всі категорії за назвою
 func GetCategoriesByName(categoryName string) ([]models.Category, error) {
 	collection := db.GetCategoryCollection()
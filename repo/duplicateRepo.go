@@ -0,0 +1,105 @@
+package repo
+
+import (
+	"cashWise/db"
+	"cashWise/models"
+	"context"
+	"fmt"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// findTransactions - отримує транзакції за фільтром
+func findTransactions(filter bson.M) ([]models.Transaction, error) {
+	cursor, err := db.GetTransactionCollection().Find(context.TODO(), filter, newestFirst())
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	transactions := []models.Transaction{}
+	if err := cursor.All(context.TODO(), &transactions); err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// GetFlaggedDuplicates - отримує транзакції, позначені як ймовірні дублікати
+func GetFlaggedDuplicates(userID int) ([]models.Transaction, error) {
+	transactions, err := findTransactions(bson.M{"userID": userID, "duplicateOf": bson.M{"$gt": 0}})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching flagged duplicates: %v", err)
+	}
+	return transactions, nil
+}
+
+// GetTransactionsByIDs - отримує транзакції користувача за списком ID
+func GetTransactionsByIDs(userID int, transactionIDs []int) ([]models.Transaction, error) {
+	transactions, err := findTransactions(bson.M{"userID": userID, "transactionID": bson.M{"$in": transactionIDs}})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching transactions: %v", err)
+	}
+	return transactions, nil
+}
+
+// ClearDuplicateFlag - знімає позначку дубліката, коли користувач підтвердив, що це окрема транзакція
+func ClearDuplicateFlag(userID int, transactionID int) error {
+	filter := bson.M{"userID": userID, "transactionID": transactionID, "duplicateOf": bson.M{"$gt": 0}}
+	result, err := db.GetTransactionCollection().UpdateOne(context.TODO(), filter, bson.M{"$unset": bson.M{"duplicateOf": ""}})
+	if err != nil {
+		return fmt.Errorf("error clearing duplicate flag: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// MergeTransactions - атомарно зберігає об'єднану транзакцію та видаляє решту.
+// Позначки дублікатів, що вказували на видалені транзакції, переносяться на збережену.
+func MergeTransactions(userID int, kept models.Transaction, removeIDs []int) error {
+	collection := db.GetTransactionCollection()
+
+	err := runInTransaction(func(sessCtx mongo.SessionContext) error {
+		keptFilter := bson.M{"userID": userID, "transactionID": kept.TransactionID}
+		set := bson.M{"description": kept.Description}
+		if len(kept.Tags) > 0 {
+			set["tags"] = kept.Tags
+		}
+		for field, value := range map[string]string{
+			"externalID":    kept.ExternalID,
+			"bankReference": kept.BankReference,
+			"counterparty":  kept.Counterparty,
+		} {
+			if value != "" {
+				set[field] = value
+			}
+		}
+		update := bson.M{"$set": set, "$unset": bson.M{"duplicateOf": ""}}
+		if _, err := collection.UpdateOne(sessCtx, keptFilter, update); err != nil {
+			return err
+		}
+
+		removeFilter := bson.M{"userID": userID, "transactionID": bson.M{"$in": removeIDs}}
+		if _, err := collection.DeleteMany(sessCtx, removeFilter); err != nil {
+			return err
+		}
+		// Чеки видалених дублікатів лишаються при збереженій транзакції
+		if err := moveAttachments(sessCtx, userID, removeIDs, kept.TransactionID); err != nil {
+			return err
+		}
+
+		_, err := collection.UpdateMany(sessCtx,
+			bson.M{"userID": userID, "duplicateOf": bson.M{"$in": removeIDs}},
+			bson.M{"$set": bson.M{"duplicateOf": kept.TransactionID}},
+		)
+		return err
+	})
+	if err != nil {
+		log.Printf("Error merging transactions into %d: %v", kept.TransactionID, err)
+		return fmt.Errorf("error merging transactions: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"cashWise/db"
+	"cashWise/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AddEnvelopeMovement - зберігає переміщення грошей між конвертами з новим ID
+func AddEnvelopeMovement(movement models.EnvelopeMovement) (models.EnvelopeMovement, error) {
+	movementID, err := getNextSequence("envelopeMovementID")
+	if err != nil {
+		return movement, fmt.Errorf("failed to get next movement ID: %v", err)
+	}
+	movement.MovementID = movementID
+
+	if _, err := db.GetEnvelopeMovementCollection().InsertOne(context.TODO(), movement); err != nil {
+		log.Printf("Error saving envelope movement for userID %d: %v", movement.UserID, err)
+		return movement, fmt.Errorf("error saving envelope movement: %v", err)
+	}
+	return movement, nil
+}
+
+// GetEnvelopeMovements - переміщення користувача за місяці від fromMonth до toMonth включно
+// (YYYY-MM) у порядку створення; порожня межа не обмежує вибірку
+func GetEnvelopeMovements(userID int, fromMonth, toMonth string) ([]models.EnvelopeMovement, error) {
+	filter := bson.M{"userID": userID}
+	if fromMonth != "" || toMonth != "" {
+		monthFilter := bson.M{}
+		if fromMonth != "" {
+			monthFilter["$gte"] = fromMonth
+		}
+		if toMonth != "" {
+			monthFilter["$lte"] = toMonth
+		}
+		filter["month"] = monthFilter
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "movementID", Value: 1}})
+	cursor, err := db.GetEnvelopeMovementCollection().Find(context.TODO(), filter, opts)
+	if err != nil {
+		log.Printf("Error fetching envelope movements for userID %d: %v", userID, err)
+		return nil, fmt.Errorf("error fetching envelope movements: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	movements := []models.EnvelopeMovement{}
+	if err := cursor.All(context.TODO(), &movements); err != nil {
+		return nil, fmt.Errorf("error decoding envelope movements: %v", err)
+	}
+	return movements, nil
+}
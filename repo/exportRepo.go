@@ -0,0 +1,47 @@
+package repo
+
+import (
+	"cashWise/db"
+	"cashWise/models"
+	"context"
+	"fmt"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StreamTransactions - передає у fn транзакції, що відповідають умовам пошуку, по одній прямо з курсора MongoDB,
+// не завантажуючи всю вибірку в пам'ять. Обробка зупиняється на першій помилці fn.
+func StreamTransactions(userID int, search models.TransactionSearch, sortField string, desc bool, fn func(models.Transaction) error) error {
+	if sortField == "" {
+		sortField = "date"
+	}
+	direction := 1
+	if desc {
+		direction = -1
+	}
+	sort := bson.D{{Key: sortField, Value: direction}}
+	if sortField != "transactionID" {
+		sort = append(sort, bson.E{Key: "transactionID", Value: direction})
+	}
+
+	opts := options.Find().SetSort(sort).SetBatchSize(500)
+	cursor, err := db.GetTransactionCollection().Find(context.TODO(), BuildSearchFilter(userID, search), opts)
+	if err != nil {
+		log.Printf("Error streaming transactions for userID %d: %v", userID, err)
+		return fmt.Errorf("error fetching transactions: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	for cursor.Next(context.TODO()) {
+		var transaction models.Transaction
+		if err := cursor.Decode(&transaction); err != nil {
+			return fmt.Errorf("error decoding transaction: %v", err)
+		}
+		if err := fn(transaction); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
@@ -185,3 +185,8 @@ func GetGoalsByUserID(userID int) ([]models.Goal, error) {
 
 	return goals, nil
 }
+
+// ListGoals - отримує сторінку цілей користувача з сортуванням у MongoDB
+func ListGoals(userID int, params ListParams) (Page, error) {
+	return findPage(db.GetGoalCollection(), bson.M{"userID": userID}, "goalID", params)
+}
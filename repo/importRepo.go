@@ -0,0 +1,174 @@
+package repo
+
+import (
+	"cashWise/db"
+	"cashWise/models"
+	"context"
+	"fmt"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CreateImportProfile - зберігає профіль зіставлення колонок
+func CreateImportProfile(profile models.ImportProfile) (models.ImportProfile, error) {
+	profileID, err := getNextSequence("importProfileID")
+	if err != nil {
+		return profile, fmt.Errorf("failed to get next import profile ID: %v", err)
+	}
+	profile.ProfileID = profileID
+
+	_, err = db.GetImportProfileCollection().InsertOne(context.TODO(), profile)
+	if err != nil {
+		log.Printf("Error saving import profile: %v", err)
+		return profile, fmt.Errorf("error saving import profile: %v", err)
+	}
+	return profile, nil
+}
+
+// GetImportProfiles - отримує всі профілі імпорту користувача
+func GetImportProfiles(userID int) ([]models.ImportProfile, error) {
+	cursor, err := db.GetImportProfileCollection().Find(context.TODO(), bson.M{"userID": userID})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching import profiles: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	profiles := []models.ImportProfile{}
+	if err := cursor.All(context.TODO(), &profiles); err != nil {
+		return nil, fmt.Errorf("error decoding import profiles: %v", err)
+	}
+	return profiles, nil
+}
+
+// GetImportProfileByID - отримує профіль імпорту за ID
+func GetImportProfileByID(userID int, profileID int) (models.ImportProfile, error) {
+	var profile models.ImportProfile
+	filter := bson.M{"userID": userID, "profileID": profileID}
+	err := db.GetImportProfileCollection().FindOne(context.TODO(), filter).Decode(&profile)
+	return profile, err
+}
+
+// DeleteImportProfile - видаляє профіль імпорту
+func DeleteImportProfile(userID int, profileID int) error {
+	filter := bson.M{"userID": userID, "profileID": profileID}
+	result, err := db.GetImportProfileCollection().DeleteOne(context.TODO(), filter)
+	if err != nil {
+		return fmt.Errorf("error deleting import profile: %v", err)
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// GetNextImportBatchID - резервує ID для нового імпорту
+func GetNextImportBatchID() (int, error) {
+	return getNextSequence("importBatchID")
+}
+
+// SaveImportBatch - зберігає підсумок імпорту
+func SaveImportBatch(batch models.ImportBatch) error {
+	_, err := db.GetImportBatchCollection().InsertOne(context.TODO(), batch)
+	if err != nil {
+		log.Printf("Error saving import batch: %v", err)
+		return fmt.Errorf("error saving import batch: %v", err)
+	}
+	return nil
+}
+
+// GetImportBatches - отримує історію імпортів користувача
+func GetImportBatches(userID int) ([]models.ImportBatch, error) {
+	cursor, err := db.GetImportBatchCollection().Find(context.TODO(), bson.M{"userID": userID})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching import batches: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	batches := []models.ImportBatch{}
+	if err := cursor.All(context.TODO(), &batches); err != nil {
+		return nil, fmt.Errorf("error decoding import batches: %v", err)
+	}
+	return batches, nil
+}
+
+// RollbackImportBatch - видаляє всі транзакції імпорту і позначає його відкоченим. Отримувачі,
+// яких створив імпорт, видаляються, якщо на них більше не посилається жодна транзакція.
+func RollbackImportBatch(userID int, batchID int) (int64, error) {
+	batchFilter := bson.M{"userID": userID, "batchID": batchID}
+	var batch models.ImportBatch
+	if err := db.GetImportBatchCollection().FindOne(context.TODO(), batchFilter).Decode(&batch); err != nil {
+		return 0, err
+	}
+
+	// ID транзакцій імпорту потрібні, щоб після відкату прибрати їхні вкладення
+	transactionIDs, err := db.GetTransactionCollection().Distinct(context.TODO(), "transactionID", bson.M{"userID": userID, "importBatchID": batchID})
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	err = runInTransaction(func(sessCtx mongo.SessionContext) error {
+		result, err := db.GetTransactionCollection().DeleteMany(sessCtx, bson.M{"userID": userID, "importBatchID": batchID})
+		if err != nil {
+			return err
+		}
+		deleted = result.DeletedCount
+
+		if len(batch.PayeeIDs) > 0 {
+			payeeFilter := bson.M{"userID": userID, "payeeID": bson.M{"$in": batch.PayeeIDs}}
+			used, err := db.GetTransactionCollection().Distinct(sessCtx, "payeeID", payeeFilter)
+			if err != nil {
+				return err
+			}
+			payeeFilter["payeeID"] = bson.M{"$in": batch.PayeeIDs, "$nin": used}
+			if _, err := db.GetPayeeCollection().DeleteMany(sessCtx, payeeFilter); err != nil {
+				return err
+			}
+		}
+
+		_, err = db.GetImportBatchCollection().UpdateOne(sessCtx, batchFilter, bson.M{"$set": bson.M{"status": "rolledBack"}})
+		return err
+	})
+	if err != nil {
+		log.Printf("Error rolling back import batch %d: %v", batchID, err)
+		return 0, fmt.Errorf("error rolling back import batch: %v", err)
+	}
+
+	ids := make([]int, 0, len(transactionIDs))
+	for _, value := range transactionIDs {
+		switch id := value.(type) {
+		case int32:
+			ids = append(ids, int(id))
+		case int64:
+			ids = append(ids, int(id))
+		}
+	}
+	deleteTransactionAttachments(userID, ids)
+	return deleted, nil
+}
+
+// GetExistingExternalIDs - повертає ID операцій банку, які вже є серед транзакцій користувача
+func GetExistingExternalIDs(userID int, account string, externalIDs []string) (map[string]bool, error) {
+	existing := map[string]bool{}
+	if len(externalIDs) == 0 {
+		return existing, nil
+	}
+
+	filter := bson.M{"userID": userID, "externalID": bson.M{"$in": externalIDs}}
+	if account != "" {
+		filter["account"] = account // FITID унікальний лише в межах рахунку
+	}
+
+	values, err := db.GetTransactionCollection().Distinct(context.TODO(), "externalID", filter)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching imported transactions: %v", err)
+	}
+	for _, value := range values {
+		if id, ok := value.(string); ok {
+			existing[id] = true
+		}
+	}
+	return existing, nil
+}
@@ -0,0 +1,97 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"cashWise/db"
+	"cashWise/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxNotifications - скільки останніх сповіщень повертає список
+const maxNotifications = 100
+
+// AddNotification - зберігає сповіщення. Якщо сповіщення з таким самим Key вже є, нічого
+// не зберігає і повертає false. Повтор відсікає upsert за key, тож унікальний індекс лише
+// підстраховує від одночасних вставок, а ID з лічильника береться лише для нових сповіщень.
+func AddNotification(notification models.Notification) (bool, error) {
+	collection := db.GetNotificationCollection()
+	filter := bson.M{"key": notification.Key}
+
+	if notification.Key != "" {
+		err := collection.FindOne(context.TODO(), filter).Err()
+		if err == nil {
+			return false, nil
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return false, fmt.Errorf("error checking notification: %v", err)
+		}
+	}
+
+	notificationID, err := getNextSequence("notificationID")
+	if err != nil {
+		return false, fmt.Errorf("failed to get next notification ID: %v", err)
+	}
+	notification.NotificationID = notificationID
+
+	// Сповіщення без ключа не дедуплікуються
+	if notification.Key == "" {
+		if _, err := collection.InsertOne(context.TODO(), notification); err != nil {
+			log.Printf("Error saving notification for userID %d: %v", notification.UserID, err)
+			return false, fmt.Errorf("error saving notification: %v", err)
+		}
+		return true, nil
+	}
+
+	update := bson.M{"$setOnInsert": notification}
+	result, err := collection.UpdateOne(context.TODO(), filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		log.Printf("Error saving notification for userID %d: %v", notification.UserID, err)
+		return false, fmt.Errorf("error saving notification: %v", err)
+	}
+	return result.UpsertedCount > 0, nil
+}
+
+// GetNotifications - останні сповіщення користувача, від найновішого
+func GetNotifications(userID int, unreadOnly bool) ([]models.Notification, error) {
+	filter := bson.M{"userID": userID}
+	if unreadOnly {
+		filter["read"] = false
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "notificationID", Value: -1}}).SetLimit(maxNotifications)
+	cursor, err := db.GetNotificationCollection().Find(context.TODO(), filter, opts)
+	if err != nil {
+		log.Printf("Error fetching notifications for userID %d: %v", userID, err)
+		return nil, fmt.Errorf("error fetching notifications: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	notifications := []models.Notification{}
+	if err := cursor.All(context.TODO(), &notifications); err != nil {
+		return nil, fmt.Errorf("error decoding notifications: %v", err)
+	}
+	return notifications, nil
+}
+
+// MarkNotificationRead - позначає сповіщення прочитаним
+func MarkNotificationRead(userID int, notificationID int) error {
+	filter := bson.M{"userID": userID, "notificationID": notificationID}
+	result, err := db.GetNotificationCollection().UpdateOne(context.TODO(), filter, bson.M{"$set": bson.M{"read": true}})
+	if err != nil {
+		return fmt.Errorf("error updating notification: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package repo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ListParams - параметри сторінки для спискових запитів
+type ListParams struct {
+	Limit  int      // Розмір сторінки; 0 - без обмеження
+	After  string   // Непрозорий курсор, отриманий з попередньої сторінки
+	Sort   string   // Поле БД для сортування
+	Desc   bool     // Сортування за спаданням
+	Fields []string // Поля для повернення; порожній список - усі поля
+}
+
+// Page - одна сторінка результатів
+type Page struct {
+	Items      []bson.M
+	NextCursor string // Порожній, якщо це остання сторінка
+	Total      int64  // Кількість документів за фільтром без урахування курсора
+}
+
+// pageCursor - вміст курсора: значення поля сортування та ID останнього елемента сторінки
+type pageCursor struct {
+	Value interface{} `json:"v"`
+	ID    int         `json:"id"`
+}
+
+func encodeCursor(cursor pageCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(encoded string) (pageCursor, error) {
+	var cursor pageCursor
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, errors.New("invalid cursor")
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, errors.New("invalid cursor")
+	}
+	return cursor, nil
+}
+
+// toInt - приводить числове значення з документа до int
+func toInt(value interface{}) int {
+	switch v := value.(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+// findPage - вибирає одну сторінку документів, відсортованих у MongoDB за params.Sort та idField.
+// idField - унікальне числове поле, що робить порядок стабільним для однакових значень сортування.
+func findPage(collection *mongo.Collection, filter bson.M, idField string, params ListParams) (Page, error) {
+	page := Page{Items: []bson.M{}}
+
+	total, err := collection.CountDocuments(context.TODO(), filter)
+	if err != nil {
+		return page, fmt.Errorf("error counting documents: %v", err)
+	}
+	page.Total = total
+
+	sortField := params.Sort
+	if sortField == "" {
+		sortField = idField
+	}
+	direction, comparison := 1, "$gt"
+	if params.Desc {
+		direction, comparison = -1, "$lt"
+	}
+
+	// Продовжуємо після останнього елемента попередньої сторінки
+	query := filter
+	if params.After != "" {
+		cursor, err := decodeCursor(params.After)
+		if err != nil {
+			return page, err
+		}
+		var after bson.M
+		if sortField == idField {
+			after = bson.M{idField: bson.M{comparison: cursor.ID}}
+		} else {
+			after = bson.M{"$or": bson.A{
+				bson.M{sortField: bson.M{comparison: cursor.Value}},
+				bson.M{sortField: cursor.Value, idField: bson.M{comparison: cursor.ID}},
+			}}
+		}
+		query = bson.M{"$and": bson.A{filter, after}}
+	}
+
+	sort := bson.D{{Key: sortField, Value: direction}}
+	if sortField != idField {
+		sort = append(sort, bson.E{Key: idField, Value: direction})
+	}
+
+	// Поля сортування потрібні для курсора, тому вибираємо їх завжди, а зайві прибираємо нижче
+	projection := bson.M{"_id": 0}
+	if len(params.Fields) > 0 {
+		for _, field := range params.Fields {
+			projection[field] = 1
+		}
+		projection[sortField] = 1
+		projection[idField] = 1
+	}
+
+	// Беремо на один елемент більше, щоб дізнатися, чи є наступна сторінка
+	opts := options.Find().
+		SetSort(sort).
+		SetProjection(projection)
+	if params.Limit > 0 {
+		opts.SetLimit(int64(params.Limit + 1))
+	}
+
+	cursor, err := collection.Find(context.TODO(), query, opts)
+	if err != nil {
+		log.Printf("Error fetching page: %v", err)
+		return page, fmt.Errorf("error fetching page: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	if err := cursor.All(context.TODO(), &page.Items); err != nil {
+		return page, fmt.Errorf("error decoding page: %v", err)
+	}
+
+	if params.Limit > 0 && len(page.Items) > params.Limit {
+		page.Items = page.Items[:params.Limit]
+		last := page.Items[len(page.Items)-1]
+		page.NextCursor = encodeCursor(pageCursor{Value: last[sortField], ID: toInt(last[idField])})
+	}
+
+	KeepFields(page.Items, params.Fields)
+	return page, nil
+}
+
+// KeepFields - залишає в документах лише вказані поля; порожній список залишає все
+func KeepFields(items []bson.M, fields []string) {
+	if len(fields) == 0 {
+		return
+	}
+	keep := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		keep[field] = true
+	}
+	for _, item := range items {
+		for key := range item {
+			if !keep[key] {
+				delete(item, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package repo
+
+import (
+	"cashWise/db"
+	"cashWise/models"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrPayeeAliasTaken - псевдонім уже належить іншому отримувачу користувача (унікальний індекс
+// {userID, aliases} підстраховує перевірку в service.ValidatePayee від гонок)
+var ErrPayeeAliasTaken = errors.New("alias already belongs to another payee")
+
+// CreatePayee - зберігає нового отримувача з наступним payeeID
+func CreatePayee(payee models.Payee) (models.Payee, error) {
+	payeeID, err := getNextSequence("payeeID")
+	if err != nil {
+		return payee, fmt.Errorf("failed to get next payee ID: %v", err)
+	}
+	payee.PayeeID = payeeID
+
+	_, err = db.GetPayeeCollection().InsertOne(context.TODO(), payee)
+	if mongo.IsDuplicateKeyError(err) {
+		return payee, ErrPayeeAliasTaken
+	}
+	if err != nil {
+		log.Printf("Error saving payee: %v", err)
+		return payee, fmt.Errorf("error saving payee: %v", err)
+	}
+	return payee, nil
+}
+
+// GetPayees - отримує довідник отримувачів користувача, відсортований за назвою
+func GetPayees(userID int) ([]models.Payee, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "payeeID", Value: 1}})
+	cursor, err := db.GetPayeeCollection().Find(context.TODO(), bson.M{"userID": userID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching payees: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	payees := []models.Payee{}
+	if err := cursor.All(context.TODO(), &payees); err != nil {
+		return nil, fmt.Errorf("error decoding payees: %v", err)
+	}
+	return payees, nil
+}
+
+// GetPayeeByID - отримує отримувача за ID
+func GetPayeeByID(userID int, payeeID int) (models.Payee, error) {
+	var payee models.Payee
+	filter := bson.M{"userID": userID, "payeeID": payeeID}
+	err := db.GetPayeeCollection().FindOne(context.TODO(), filter).Decode(&payee)
+	return payee, err
+}
+
+// UpdatePayee - замінює назву, псевдоніми та категорію за замовчуванням отримувача
+func UpdatePayee(payee models.Payee) error {
+	filter := bson.M{"userID": payee.UserID, "payeeID": payee.PayeeID}
+	result, err := db.GetPayeeCollection().ReplaceOne(context.TODO(), filter, payee)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrPayeeAliasTaken
+	}
+	if err != nil {
+		log.Printf("Error updating payee %d: %v", payee.PayeeID, err)
+		return fmt.Errorf("error updating payee: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// DeletePayee - видаляє отримувача і відв'язує від нього транзакції
+func DeletePayee(userID int, payeeID int) error {
+	err := runInTransaction(func(sessCtx mongo.SessionContext) error {
+		result, err := db.GetPayeeCollection().DeleteOne(sessCtx, bson.M{"userID": userID, "payeeID": payeeID})
+		if err != nil {
+			return err
+		}
+		if result.DeletedCount == 0 {
+			return mongo.ErrNoDocuments
+		}
+
+		filter := bson.M{"userID": userID, "payeeID": payeeID}
+		_, err = db.GetTransactionCollection().UpdateMany(sessCtx, filter, bson.M{"$unset": bson.M{"payeeID": ""}})
+		return err
+	})
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		log.Printf("Error deleting payee %d: %v", payeeID, err)
+		return fmt.Errorf("error deleting payee: %v", err)
+	}
+	return err
+}
+
+// MergePayees - переносить псевдоніми та транзакції отримувачів sourceIDs на targetID і видаляє їх.
+// Повертає кількість перенесених транзакцій.
+func MergePayees(userID int, targetID int, sourceIDs []int) (int64, error) {
+	var moved int64
+	err := runInTransaction(func(sessCtx mongo.SessionContext) error {
+		var target models.Payee
+		err := db.GetPayeeCollection().FindOne(sessCtx, bson.M{"userID": userID, "payeeID": targetID}).Decode(&target)
+		if err != nil {
+			return err
+		}
+
+		sourceFilter := bson.M{"userID": userID, "payeeID": bson.M{"$in": sourceIDs}}
+		cursor, err := db.GetPayeeCollection().Find(sessCtx, sourceFilter)
+		if err != nil {
+			return err
+		}
+		var sources []models.Payee
+		if err := cursor.All(sessCtx, &sources); err != nil {
+			return err
+		}
+		if len(sources) != len(sourceIDs) {
+			return mongo.ErrNoDocuments
+		}
+
+		var aliases []string
+		for _, source := range sources {
+			aliases = append(aliases, source.Aliases...)
+			if target.DefaultCategoryID == 0 {
+				target.DefaultCategoryID = source.DefaultCategoryID
+			}
+		}
+		// Джерела видаляються до перенесення псевдонімів, інакше спрацює унікальний індекс псевдонімів
+		if _, err := db.GetPayeeCollection().DeleteMany(sessCtx, sourceFilter); err != nil {
+			return err
+		}
+		update := bson.M{
+			"$addToSet": bson.M{"aliases": bson.M{"$each": aliases}},
+			"$set":      bson.M{"defaultCategoryID": target.DefaultCategoryID},
+		}
+		if _, err := db.GetPayeeCollection().UpdateOne(sessCtx, bson.M{"userID": userID, "payeeID": targetID}, update); err != nil {
+			return err
+		}
+
+		result, err := db.GetTransactionCollection().UpdateMany(sessCtx, sourceFilter, bson.M{"$set": bson.M{"payeeID": targetID}})
+		if err != nil {
+			return err
+		}
+		moved = result.ModifiedCount
+		return nil
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return 0, ErrPayeeAliasTaken
+	}
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		log.Printf("Error merging payees for userID %d: %v", userID, err)
+		return 0, fmt.Errorf("error merging payees: %v", err)
+	}
+	return moved, err
+}
+
+// SplitPayee - створює нового отримувача з псевдонімами, забраними в sourceID, і переносить на нього
+// транзакції transactionIDs
+func SplitPayee(userID int, sourceID int, payee models.Payee, transactionIDs []int) (models.Payee, error) {
+	payeeID, err := getNextSequence("payeeID")
+	if err != nil {
+		return payee, fmt.Errorf("failed to get next payee ID: %v", err)
+	}
+	payee.PayeeID = payeeID
+	payee.UserID = userID
+
+	err = runInTransaction(func(sessCtx mongo.SessionContext) error {
+		sourceFilter := bson.M{"userID": userID, "payeeID": sourceID}
+		update := bson.M{"$pull": bson.M{"aliases": bson.M{"$in": payee.Aliases}}}
+		result, err := db.GetPayeeCollection().UpdateOne(sessCtx, sourceFilter, update)
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return mongo.ErrNoDocuments
+		}
+
+		if _, err := db.GetPayeeCollection().InsertOne(sessCtx, payee); err != nil {
+			return err
+		}
+
+		if len(transactionIDs) == 0 {
+			return nil
+		}
+		filter := bson.M{"userID": userID, "payeeID": sourceID, "transactionID": bson.M{"$in": transactionIDs}}
+		_, err = db.GetTransactionCollection().UpdateMany(sessCtx, filter, bson.M{"$set": bson.M{"payeeID": payeeID}})
+		return err
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return payee, ErrPayeeAliasTaken
+	}
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		log.Printf("Error splitting payee %d: %v", sourceID, err)
+		return payee, fmt.Errorf("error splitting payee: %v", err)
+	}
+	return payee, err
+}
+
+// GetPayeeTransactions - отримує всі транзакції отримувача, від найновіших
+func GetPayeeTransactions(userID int, payeeID int) ([]models.Transaction, error) {
+	transactions, err := findTransactions(bson.M{"userID": userID, "payeeID": payeeID})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching payee transactions: %v", err)
+	}
+	return transactions, nil
+}
+
+// GetPayeeSpending - підсумок витрат в отримувача за період і розбивка за місяцями
+func GetPayeeSpending(userID int, payeeID int, startDate, endDate string) (models.PayeeStats, error) {
+	match := bson.M{"userID": userID, "payeeID": payeeID, "type": "expense"}
+	if startDate != "" || endDate != "" {
+		dateFilter := bson.M{}
+		if startDate != "" {
+			dateFilter["$gte"] = startDate
+		}
+		if endDate != "" {
+			dateFilter["$lte"] = endDate
+		}
+		match["date"] = dateFilter
+	}
+
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$facet": bson.M{
+			"summary": bson.A{bson.M{"$group": bson.M{
+				"_id":       nil,
+				"total":     bson.M{"$sum": "$amount"},
+				"count":     bson.M{"$sum": 1},
+				"firstDate": bson.M{"$min": "$date"},
+				"lastDate":  bson.M{"$max": "$date"},
+			}}},
+			"months": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":   bson.M{"$substr": bson.A{"$date", 0, 7}}, // YYYY-MM
+					"total": bson.M{"$sum": "$amount"},
+					"count": bson.M{"$sum": 1},
+				}},
+				bson.M{"$sort": bson.M{"_id": 1}},
+			},
+		}},
+	}
+
+	stats := models.PayeeStats{Months: []models.PayeeMonth{}}
+	cursor, err := db.GetTransactionCollection().Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		log.Printf("Error aggregating payee %d spending: %v", payeeID, err)
+		return stats, fmt.Errorf("error aggregating payee spending: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	var results []struct {
+		Summary []struct {
+			Total     float64 `bson:"total"`
+			Count     int     `bson:"count"`
+			FirstDate string  `bson:"firstDate"`
+			LastDate  string  `bson:"lastDate"`
+		} `bson:"summary"`
+		Months []models.PayeeMonth `bson:"months"`
+	}
+	if err := cursor.All(context.TODO(), &results); err != nil {
+		return stats, fmt.Errorf("error decoding payee spending: %v", err)
+	}
+	if len(results) == 0 {
+		return stats, nil
+	}
+	if len(results[0].Summary) > 0 {
+		summary := results[0].Summary[0]
+		stats.Total, stats.Count = summary.Total, summary.Count
+		stats.FirstDate, stats.LastDate = summary.FirstDate, summary.LastDate
+	}
+	if results[0].Months != nil {
+		stats.Months = results[0].Months
+	}
+	return stats, nil
+}
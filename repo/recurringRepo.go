@@ -0,0 +1,182 @@
+package repo
+
+import (
+	"cashWise/db"
+	"cashWise/models"
+	"context"
+	"fmt"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CreateRecurring - створює шаблон транзакції, що повторюється
+func CreateRecurring(recurring models.RecurringTransaction) (models.RecurringTransaction, error) {
+	collection := db.GetRecurringCollection()
+
+	recurringID, err := getNextSequence("recurringID")
+	if err != nil {
+		return recurring, fmt.Errorf("failed to get next recurring ID: %v", err)
+	}
+	recurring.RecurringID = recurringID
+	recurring.Active = true
+	// Як і в AddTransaction: основна категорія - категорія першої частини, щоб винятки зі зміненою сумою мали категорію
+	if len(recurring.Splits) > 0 {
+		recurring.CategoryID = recurring.Splits[0].CategoryID
+	}
+
+	_, err = collection.InsertOne(context.TODO(), recurring)
+	if err != nil {
+		log.Printf("Error creating recurring transaction: %v", err)
+		return recurring, fmt.Errorf("error creating recurring transaction: %v", err)
+	}
+	return recurring, nil
+}
+
+// GetRecurringByID - отримує шаблон за ID та userID
+func GetRecurringByID(userID int, recurringID int) (models.RecurringTransaction, error) {
+	var recurring models.RecurringTransaction
+	filter := bson.M{"userID": userID, "recurringID": recurringID}
+	err := db.GetRecurringCollection().FindOne(context.TODO(), filter).Decode(&recurring)
+	return recurring, err
+}
+
+// GetRecurringByUserID - отримує всі шаблони користувача
+func GetRecurringByUserID(userID int) ([]models.RecurringTransaction, error) {
+	return findRecurring(bson.M{"userID": userID})
+}
+
+// GetActiveRecurring - отримує всі активні шаблони для генератора
+func GetActiveRecurring() ([]models.RecurringTransaction, error) {
+	return findRecurring(bson.M{"active": true})
+}
+
+func findRecurring(filter bson.M) ([]models.RecurringTransaction, error) {
+	cursor, err := db.GetRecurringCollection().Find(context.TODO(), filter)
+	if err != nil {
+		log.Printf("Error finding recurring transactions: %v", err)
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	var recurring []models.RecurringTransaction
+	if err := cursor.All(context.TODO(), &recurring); err != nil {
+		return nil, fmt.Errorf("error decoding recurring transactions: %v", err)
+	}
+	return recurring, nil
+}
+
+// UpdateRecurring - оновлює шаблон, змінюючи лише передані поля; active == nil залишає стан без змін
+func UpdateRecurring(userID int, recurringID int, updated models.RecurringTransaction, active *bool) error {
+	update := bson.M{"$set": bson.M{}}
+
+	if updated.CategoryID != 0 {
+		update["$set"].(bson.M)["categoryID"] = updated.CategoryID
+	}
+	if updated.Type != "" {
+		update["$set"].(bson.M)["type"] = updated.Type
+	}
+	if updated.Amount != 0 {
+		update["$set"].(bson.M)["amount"] = updated.Amount
+	}
+	if updated.Description != "" {
+		update["$set"].(bson.M)["description"] = updated.Description
+	}
+	if updated.Account != "" {
+		update["$set"].(bson.M)["account"] = updated.Account
+	}
+	if len(updated.Splits) > 0 {
+		update["$set"].(bson.M)["splits"] = updated.Splits
+		update["$set"].(bson.M)["categoryID"] = updated.Splits[0].CategoryID
+	}
+	if updated.Schedule.Frequency != "" {
+		update["$set"].(bson.M)["schedule"] = updated.Schedule
+	}
+	if active != nil {
+		update["$set"].(bson.M)["active"] = *active
+	}
+	if len(update["$set"].(bson.M)) == 0 {
+		return fmt.Errorf("no fields to update")
+	}
+
+	filter := bson.M{"userID": userID, "recurringID": recurringID}
+	result, err := db.GetRecurringCollection().UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return fmt.Errorf("error updating recurring transaction: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// DeleteRecurring - видаляє шаблон (вже створені транзакції залишаються)
+func DeleteRecurring(userID int, recurringID int) error {
+	filter := bson.M{"userID": userID, "recurringID": recurringID}
+	result, err := db.GetRecurringCollection().DeleteOne(context.TODO(), filter)
+	if err != nil {
+		return fmt.Errorf("error deleting recurring transaction: %v", err)
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// SetRecurringException - зберігає пропуск або зміну одного повторення, замінюючи попередню для тієї ж дати
+func SetRecurringException(userID int, recurringID int, exception models.RecurringException) error {
+	collection := db.GetRecurringCollection()
+	filter := bson.M{"userID": userID, "recurringID": recurringID}
+
+	_, err := collection.UpdateOne(context.TODO(), filter, bson.M{"$pull": bson.M{"exceptions": bson.M{"date": exception.Date}}})
+	if err != nil {
+		return fmt.Errorf("error updating recurring exceptions: %v", err)
+	}
+
+	result, err := collection.UpdateOne(context.TODO(), filter, bson.M{"$push": bson.M{"exceptions": exception}})
+	if err != nil {
+		return fmt.Errorf("error updating recurring exceptions: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// SetRecurringLastGenerated - запам'ятовує дату останнього створеного повторення
+func SetRecurringLastGenerated(recurringID int, date string) error {
+	filter := bson.M{"recurringID": recurringID}
+	_, err := db.GetRecurringCollection().UpdateOne(context.TODO(), filter, bson.M{"$set": bson.M{"lastGenerated": date}})
+	return err
+}
+
+// RecurringOccurrenceExists - перевіряє, чи транзакцію для повторення вже створено
+func RecurringOccurrenceExists(userID int, recurringID int, date string) (bool, error) {
+	filter := bson.M{"userID": userID, "recurringID": recurringID, "date": date}
+	count, err := db.GetTransactionCollection().CountDocuments(context.TODO(), filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// recurringTypes - типи транзакцій, які може створювати шаблон (перекази мають власні ендпоінти)
+var recurringTypes = map[string]bool{"income": true, "expense": true, "goal": true}
+
+// ValidateRecurring - перевіряє тип, суму, категорію та частини шаблону тими ж правилами, що й AddTransaction
+func ValidateRecurring(recurring models.RecurringTransaction) error {
+	if !recurringTypes[recurring.Type] {
+		return fmt.Errorf("type must be income, expense or goal")
+	}
+	if recurring.Amount <= 0 {
+		return fmt.Errorf("amount must be positive")
+	}
+	if len(recurring.Splits) > 0 {
+		return validateSplits(recurring.Amount, recurring.Splits)
+	}
+	if recurring.CategoryID == 0 {
+		return fmt.Errorf("categoryID is required")
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package repo
+
+import (
+	"cashWise/db"
+	"cashWise/models"
+	"context"
+	"fmt"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// splitLinesStages - етапи агрегації, що розкладають транзакції на рядки {categoryID, amount}:
+// транзакція з розбиттям дає свої частини, звичайна - один рядок з власною категорією
+func splitLinesStages() []bson.M {
+	return []bson.M{
+		{"$project": bson.M{
+			"lines": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{bson.M{"$size": bson.M{"$ifNull": bson.A{"$splits", bson.A{}}}}, 0}},
+				"$splits",
+				bson.A{bson.M{"categoryID": "$categoryID", "amount": "$amount"}},
+			}},
+		}},
+		{"$unwind": "$lines"},
+	}
+}
+
+// GetCategoryTotals - рахує суми за категоріями на рівні частин транзакцій
+func GetCategoryTotals(userID int, transactionTypes []string, startDate, endDate string) ([]models.CategoryTotal, error) {
+	collection := db.GetTransactionCollection()
+
+	match := bson.M{
+		"userID": userID,
+		"type":   bson.M{"$in": transactionTypes},
+	}
+	if startDate != "" || endDate != "" {
+		dateFilter := bson.M{}
+		if startDate != "" {
+			dateFilter["$gte"] = startDate
+		}
+		if endDate != "" {
+			dateFilter["$lte"] = endDate
+		}
+		match["date"] = dateFilter
+	}
+
+	pipeline := []bson.M{{"$match": match}}
+	pipeline = append(pipeline, splitLinesStages()...)
+	pipeline = append(pipeline,
+		bson.M{"$group": bson.M{
+			"_id":   "$lines.categoryID",
+			"total": bson.M{"$sum": "$lines.amount"},
+			"count": bson.M{"$sum": 1},
+		}},
+		bson.M{"$sort": bson.M{"total": -1}},
+	)
+
+	cursor, err := collection.Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		log.Printf("Error aggregating category totals for userID %d: %v", userID, err)
+		return nil, fmt.Errorf("error aggregating category totals: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	var totals []models.CategoryTotal
+	if err := cursor.All(context.TODO(), &totals); err != nil {
+		return nil, fmt.Errorf("error decoding category totals: %v", err)
+	}
+	return totals, nil
+}
+
+// GetBalanceBefore - залишок користувача (доходи мінус витрати та внески на цілі) за всіма транзакціями
+// до дати before, не включно
+func GetBalanceBefore(userID int, before string) (float64, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{
+			"userID": userID,
+			"type":   bson.M{"$in": bson.A{"income", "expense", "goal"}},
+			"date":   bson.M{"$lt": before},
+		}},
+		{"$group": bson.M{
+			"_id": nil,
+			"balance": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{"$type", "income"}},
+				"$amount",
+				bson.M{"$multiply": bson.A{"$amount", -1}},
+			}}},
+		}},
+	}
+
+	cursor, err := db.GetTransactionCollection().Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		log.Printf("Error calculating balance for userID %d: %v", userID, err)
+		return 0, fmt.Errorf("error calculating balance: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	var result []struct {
+		Balance float64 `bson:"balance"`
+	}
+	if err := cursor.All(context.TODO(), &result); err != nil {
+		return 0, fmt.Errorf("error decoding balance: %v", err)
+	}
+	if len(result) == 0 {
+		return 0, nil
+	}
+	return result[0].Balance, nil
+}
@@ -0,0 +1,108 @@
+package repo
+
+import (
+	"cashWise/db"
+	"cashWise/models"
+	"context"
+	"fmt"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CreateRule - зберігає нове правило категоризації
+func CreateRule(rule models.CategoryRule) (models.CategoryRule, error) {
+	ruleID, err := getNextSequence("ruleID")
+	if err != nil {
+		return rule, fmt.Errorf("failed to get next rule ID: %v", err)
+	}
+	rule.RuleID = ruleID
+
+	_, err = db.GetRuleCollection().InsertOne(context.TODO(), rule)
+	if err != nil {
+		log.Printf("Error saving rule: %v", err)
+		return rule, fmt.Errorf("error saving rule: %v", err)
+	}
+	return rule, nil
+}
+
+// GetRules - отримує правила користувача в порядку перевірки: за пріоритетом, потім за часом створення
+func GetRules(userID int) ([]models.CategoryRule, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "priority", Value: 1}, {Key: "ruleID", Value: 1}})
+	cursor, err := db.GetRuleCollection().Find(context.TODO(), bson.M{"userID": userID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching rules: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	rules := []models.CategoryRule{}
+	if err := cursor.All(context.TODO(), &rules); err != nil {
+		return nil, fmt.Errorf("error decoding rules: %v", err)
+	}
+	return rules, nil
+}
+
+// GetRuleByID - отримує правило за ID
+func GetRuleByID(userID int, ruleID int) (models.CategoryRule, error) {
+	var rule models.CategoryRule
+	filter := bson.M{"userID": userID, "ruleID": ruleID}
+	err := db.GetRuleCollection().FindOne(context.TODO(), filter).Decode(&rule)
+	return rule, err
+}
+
+// UpdateRule - замінює умови та дії правила
+func UpdateRule(rule models.CategoryRule) error {
+	filter := bson.M{"userID": rule.UserID, "ruleID": rule.RuleID}
+	result, err := db.GetRuleCollection().ReplaceOne(context.TODO(), filter, rule)
+	if err != nil {
+		log.Printf("Error updating rule %d: %v", rule.RuleID, err)
+		return fmt.Errorf("error updating rule: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// DeleteRule - видаляє правило
+func DeleteRule(userID int, ruleID int) error {
+	filter := bson.M{"userID": userID, "ruleID": ruleID}
+	result, err := db.GetRuleCollection().DeleteOne(context.TODO(), filter)
+	if err != nil {
+		return fmt.Errorf("error deleting rule: %v", err)
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// SaveCategorizedTransactions - записує категорію, теги та опис транзакцій, змінених правилами, одним пакетом
+func SaveCategorizedTransactions(userID int, transactions []models.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	writes := make([]mongo.WriteModel, 0, len(transactions))
+	for _, transaction := range transactions {
+		set := bson.M{
+			"categoryID":  transaction.CategoryID,
+			"description": transaction.Description,
+		}
+		if len(transaction.Tags) > 0 {
+			set["tags"] = transaction.Tags
+		}
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"userID": userID, "transactionID": transaction.TransactionID}).
+			SetUpdate(bson.M{"$set": set}))
+	}
+
+	_, err := db.GetTransactionCollection().BulkWrite(context.TODO(), writes, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		log.Printf("Error saving categorized transactions for userID %d: %v", userID, err)
+		return fmt.Errorf("error updating transactions: %v", err)
+	}
+	return nil
+}
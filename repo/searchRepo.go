@@ -0,0 +1,117 @@
+package repo
+
+import (
+	"cashWise/db"
+	"cashWise/models"
+	"context"
+	"fmt"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// BuildSearchFilter - перетворює умови пошуку на фільтр MongoDB
+func BuildSearchFilter(userID int, search models.TransactionSearch) bson.M {
+	filter := bson.M{"userID": userID}
+
+	if search.From != "" || search.To != "" {
+		dateFilter := bson.M{}
+		if search.From != "" {
+			dateFilter["$gte"] = search.From
+		}
+		if search.To != "" {
+			dateFilter["$lte"] = search.To
+		}
+		filter["date"] = dateFilter
+	}
+
+	// Категорія може бути як у самої транзакції, так і в одній з її частин
+	if len(search.CategoryIDs) > 0 {
+		filter["$or"] = bson.A{
+			bson.M{"categoryID": bson.M{"$in": search.CategoryIDs}},
+			bson.M{"splits.categoryID": bson.M{"$in": search.CategoryIDs}},
+		}
+	}
+	if len(search.Types) > 0 {
+		filter["type"] = bson.M{"$in": search.Types}
+	}
+	if len(search.Accounts) > 0 {
+		filter["account"] = bson.M{"$in": search.Accounts}
+	}
+	if search.MinAmount != nil || search.MaxAmount != nil {
+		amountFilter := bson.M{}
+		if search.MinAmount != nil {
+			amountFilter["$gte"] = *search.MinAmount
+		}
+		if search.MaxAmount != nil {
+			amountFilter["$lte"] = *search.MaxAmount
+		}
+		filter["amount"] = amountFilter
+	}
+	if len(search.Tags) > 0 {
+		filter["tags"] = bson.M{"$all": NormalizeTags(search.Tags)}
+	}
+	if search.Text != "" {
+		filter["$text"] = bson.M{"$search": search.Text}
+	}
+
+	return filter
+}
+
+// SearchTransactions - отримує сторінку транзакцій, що відповідають умовам пошуку
+func SearchTransactions(userID int, search models.TransactionSearch, params ListParams) (Page, error) {
+	return findPage(db.GetTransactionCollection(), BuildSearchFilter(userID, search), "transactionID", params)
+}
+
+// CreateSavedSearch - зберігає пошук під назвою
+func CreateSavedSearch(search models.SavedSearch) (models.SavedSearch, error) {
+	searchID, err := getNextSequence("searchID")
+	if err != nil {
+		return search, fmt.Errorf("failed to get next search ID: %v", err)
+	}
+	search.SearchID = searchID
+
+	_, err = db.GetSavedSearchCollection().InsertOne(context.TODO(), search)
+	if err != nil {
+		log.Printf("Error saving search: %v", err)
+		return search, fmt.Errorf("error saving search: %v", err)
+	}
+	return search, nil
+}
+
+// GetSavedSearches - отримує всі збережені пошуки користувача
+func GetSavedSearches(userID int) ([]models.SavedSearch, error) {
+	cursor, err := db.GetSavedSearchCollection().Find(context.TODO(), bson.M{"userID": userID})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching saved searches: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	searches := []models.SavedSearch{}
+	if err := cursor.All(context.TODO(), &searches); err != nil {
+		return nil, fmt.Errorf("error decoding saved searches: %v", err)
+	}
+	return searches, nil
+}
+
+// GetSavedSearchByID - отримує збережений пошук за ID
+func GetSavedSearchByID(userID int, searchID int) (models.SavedSearch, error) {
+	var search models.SavedSearch
+	filter := bson.M{"userID": userID, "searchID": searchID}
+	err := db.GetSavedSearchCollection().FindOne(context.TODO(), filter).Decode(&search)
+	return search, err
+}
+
+// DeleteSavedSearch - видаляє збережений пошук
+func DeleteSavedSearch(userID int, searchID int) error {
+	filter := bson.M{"userID": userID, "searchID": searchID}
+	result, err := db.GetSavedSearchCollection().DeleteOne(context.TODO(), filter)
+	if err != nil {
+		return fmt.Errorf("error deleting saved search: %v", err)
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
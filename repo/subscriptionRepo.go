@@ -0,0 +1,42 @@
+package repo
+
+import (
+	"cashWise/db"
+	"cashWise/models"
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetSubscriptionDecisions - повертає рішення користувача щодо знайдених платежів за ключем
+func GetSubscriptionDecisions(userID int) (map[string]models.SubscriptionDecision, error) {
+	cursor, err := db.GetSubscriptionCollection().Find(context.TODO(), bson.M{"userID": userID})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching subscription decisions: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	var decisions []models.SubscriptionDecision
+	if err := cursor.All(context.TODO(), &decisions); err != nil {
+		return nil, fmt.Errorf("error decoding subscription decisions: %v", err)
+	}
+
+	result := make(map[string]models.SubscriptionDecision, len(decisions))
+	for _, decision := range decisions {
+		result[decision.Key] = decision
+	}
+	return result, nil
+}
+
+// SaveSubscriptionDecision - зберігає або замінює рішення щодо платежу
+func SaveSubscriptionDecision(decision models.SubscriptionDecision) error {
+	filter := bson.M{"userID": decision.UserID, "key": decision.Key}
+	opts := options.Replace().SetUpsert(true)
+	_, err := db.GetSubscriptionCollection().ReplaceOne(context.TODO(), filter, decision, opts)
+	if err != nil {
+		return fmt.Errorf("error saving subscription decision: %v", err)
+	}
+	return nil
+}
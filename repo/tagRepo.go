@@ -0,0 +1,171 @@
+package repo
+
+import (
+	"cashWise/db"
+	"cashWise/models"
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// NormalizeTags - теги в нижньому регістрі без зайвих пробілів і повторів, у порядку першої появи
+func NormalizeTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.Join(strings.Fields(tag), " "))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// GetTags - теги користувача, що починаються з prefix, від найуживаніших
+func GetTags(userID int, prefix string, limit int) ([]models.TagCount, error) {
+	match := bson.M{"userID": userID}
+	tagMatch := bson.M{}
+	if prefix = strings.ToLower(strings.TrimSpace(prefix)); prefix != "" {
+		tagMatch["tags"] = bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)}
+		match["tags"] = tagMatch["tags"]
+	}
+
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$unwind": "$tags"},
+		{"$match": tagMatch}, // Після розгортання лишаємо тільки теги з префіксом
+		{"$group": bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}},
+		{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		{"$limit": limit},
+	}
+
+	cursor, err := db.GetTransactionCollection().Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		log.Printf("Error fetching tags for userID %d: %v", userID, err)
+		return nil, fmt.Errorf("error fetching tags: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	tags := []models.TagCount{}
+	if err := cursor.All(context.TODO(), &tags); err != nil {
+		return nil, fmt.Errorf("error decoding tags: %v", err)
+	}
+	return tags, nil
+}
+
+// MergeTags - замінює теги sources на target в усіх транзакціях і правилах користувача.
+// Повертає кількість змінених транзакцій.
+func MergeTags(userID int, sources []string, target string) (int64, error) {
+	var removed []string
+	for _, source := range sources {
+		if source != target {
+			removed = append(removed, source)
+		}
+	}
+	if len(removed) == 0 {
+		return 0, nil
+	}
+
+	var modified int64
+	err := runInTransaction(func(sessCtx mongo.SessionContext) error {
+		// Спочатку додаємо новий тег, потім прибираємо старі, щоб тег не дублювався там, де вже був
+		locations := []struct {
+			collection *mongo.Collection
+			field      string
+		}{
+			{db.GetTransactionCollection(), "tags"},
+			{db.GetRuleCollection(), "actions.tags"},
+		}
+		for i, location := range locations {
+			filter := bson.M{"userID": userID, location.field: bson.M{"$in": removed}}
+			result, err := location.collection.UpdateMany(sessCtx, filter, bson.M{"$addToSet": bson.M{location.field: target}})
+			if err != nil {
+				return err
+			}
+			if i == 0 {
+				modified = result.MatchedCount
+			}
+			_, err = location.collection.UpdateMany(sessCtx, filter, bson.M{"$pull": bson.M{location.field: bson.M{"$in": removed}}})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error merging tags for userID %d: %v", userID, err)
+		return 0, fmt.Errorf("error merging tags: %v", err)
+	}
+	return modified, nil
+}
+
+// DeleteTag - прибирає тег з усіх транзакцій і правил користувача
+func DeleteTag(userID int, tag string) (int64, error) {
+	var modified int64
+	err := runInTransaction(func(sessCtx mongo.SessionContext) error {
+		filter := bson.M{"userID": userID, "tags": tag}
+		result, err := db.GetTransactionCollection().UpdateMany(sessCtx, filter, bson.M{"$pull": bson.M{"tags": tag}})
+		if err != nil {
+			return err
+		}
+		modified = result.ModifiedCount
+
+		ruleFilter := bson.M{"userID": userID, "actions.tags": tag}
+		_, err = db.GetRuleCollection().UpdateMany(sessCtx, ruleFilter, bson.M{"$pull": bson.M{"actions.tags": tag}})
+		return err
+	})
+	if err != nil {
+		log.Printf("Error deleting tag for userID %d: %v", userID, err)
+		return 0, fmt.Errorf("error deleting tag: %v", err)
+	}
+	return modified, nil
+}
+
+// GetTagTotals - суми транзакцій заданих типів за тегами; транзакція з кількома тегами враховується в кожному
+func GetTagTotals(userID int, transactionTypes []string, startDate, endDate string) ([]models.TagTotal, error) {
+	match := bson.M{
+		"userID": userID,
+		"type":   bson.M{"$in": transactionTypes},
+		"tags":   bson.M{"$exists": true, "$ne": bson.A{}},
+	}
+	if startDate != "" || endDate != "" {
+		dateFilter := bson.M{}
+		if startDate != "" {
+			dateFilter["$gte"] = startDate
+		}
+		if endDate != "" {
+			dateFilter["$lte"] = endDate
+		}
+		match["date"] = dateFilter
+	}
+
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$unwind": "$tags"},
+		{"$group": bson.M{
+			"_id":   "$tags",
+			"total": bson.M{"$sum": "$amount"},
+			"count": bson.M{"$sum": 1},
+		}},
+		{"$sort": bson.D{{Key: "total", Value: -1}, {Key: "_id", Value: 1}}},
+	}
+
+	cursor, err := db.GetTransactionCollection().Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		log.Printf("Error aggregating tag totals for userID %d: %v", userID, err)
+		return nil, fmt.Errorf("error aggregating tag totals: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	totals := []models.TagTotal{}
+	if err := cursor.All(context.TODO(), &totals); err != nil {
+		return nil, fmt.Errorf("error decoding tag totals: %v", err)
+	}
+	return totals, nil
+}
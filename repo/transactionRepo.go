@@ -6,7 +6,7 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"sort"
+	"math"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -39,11 +39,16 @@ func GetNextTransactionID() (int, error) {
 func AddTransaction(transaction models.Transaction) error {
 	collection := db.GetTransactionCollection()
 
-	// Перевіряємо, чи є categoryID у транзакції
-	if transaction.CategoryID == 0 {
-		return fmt.Errorf("categoryID is required")
+	if err := ValidateTransaction(transaction); err != nil {
+		return err
+	}
+	// Основна категорія - категорія першої частини, щоб старі клієнти бачили хоча б одну
+	if len(transaction.Splits) > 0 {
+		transaction.CategoryID = transaction.Splits[0].CategoryID
 	}
 
+	transaction.Tags = NormalizeTags(transaction.Tags)
+
 	// Отримуємо новий transactionID
 	transactionID, err := GetNextTransactionID()
 	if err != nil {
@@ -66,12 +71,15 @@ func EditTransaction(transactionID int, transaction models.Transaction) error {
 	filter := bson.M{"transactionID": transactionID}
 
 	// Перевіряємо чи існує транзакція з таким ID
-	count, err := collection.CountDocuments(context.TODO(), filter)
+	var existing models.Transaction
+	err := collection.FindOne(context.TODO(), filter).Decode(&existing)
 	if err != nil {
-		return err
+		return err // mongo.ErrNoDocuments, якщо транзакція з таким ID не знайдена
 	}
-	if count == 0 {
-		return mongo.ErrNoDocuments // Транзакція з таким ID не знайдена
+
+	// Частини переказу редагуються лише разом через EditTransfer
+	if existing.TransferID != 0 {
+		return fmt.Errorf("transaction is part of transfer %d, edit the transfer instead", existing.TransferID)
 	}
 
 	// Створюємо мапу для оновлення, враховуючи лише ті поля, які змінюються
@@ -87,6 +95,35 @@ func EditTransaction(transactionID int, transaction models.Transaction) error {
 	if transaction.Description != "" {
 		update["$set"].(bson.M)["description"] = transaction.Description
 	}
+	if transaction.Tags != nil {
+		update["$set"].(bson.M)["tags"] = NormalizeTags(transaction.Tags) // Порожній масив знімає всі теги
+	}
+	if transaction.PayeeID != 0 {
+		update["$set"].(bson.M)["payeeID"] = transaction.PayeeID
+	}
+	if transaction.GoalID != 0 {
+		if existing.Type != "goal" {
+			return fmt.Errorf("goalID is allowed only for goal transactions")
+		}
+		update["$set"].(bson.M)["goalID"] = transaction.GoalID
+	}
+
+	// Сума частин має збігатися з сумою транзакції (новою або поточною)
+	amount := existing.Amount
+	if transaction.Amount != 0 {
+		amount = transaction.Amount
+	}
+	splits := existing.Splits
+	if len(transaction.Splits) > 0 {
+		splits = transaction.Splits
+		update["$set"].(bson.M)["splits"] = splits
+		update["$set"].(bson.M)["categoryID"] = splits[0].CategoryID
+	}
+	if len(splits) > 0 {
+		if err := validateSplits(amount, splits); err != nil {
+			return err
+		}
+	}
 
 	// Оновлюємо тільки змінені поля
 	_, err = collection.UpdateOne(context.TODO(), filter, update)
@@ -112,41 +149,78 @@ func DeleteTransaction(userID int, transactionID int) error {
 		return mongo.ErrNoDocuments // Транзакція з таким ID не знайдена
 	}
 
+	// Якщо транзакція є частиною переказу, видаляємо обидві частини
+	transferID, err := getTransferIDOfTransaction(filter)
+	if err != nil {
+		return err
+	}
+	if transferID != 0 {
+		return DeleteTransfer(userID, transferID)
+	}
+
 	// Видаляємо транзакцію
 	_, err = collection.DeleteOne(context.TODO(), filter)
 	if err != nil {
 		return err
 	}
 
+	// Чеки та інші вкладення без транзакції нікому не потрібні
+	deleteTransactionAttachments(userID, []int{transactionID})
+	return nil
+}
+
+// ValidateTransaction - перевіряє, чи є categoryID у транзакції або її частинах, так само, як AddTransaction
+func ValidateTransaction(transaction models.Transaction) error {
+	if transaction.GoalID != 0 && transaction.Type != "goal" {
+		return fmt.Errorf("goalID is allowed only for goal transactions")
+	}
+	if len(transaction.Splits) > 0 {
+		return validateSplits(transaction.Amount, transaction.Splits)
+	}
+	if transaction.CategoryID == 0 {
+		return fmt.Errorf("categoryID is required")
+	}
 	return nil
 }
 
-func sortTransactionsByDate(transactions []models.Transaction) ([]models.Transaction, error) {
-	sort.Slice(transactions, func(i, j int) bool {
-		dateI, errI := time.Parse("2006-01-02", transactions[i].Date)
-		dateJ, errJ := time.Parse("2006-01-02", transactions[j].Date)
-		if errI != nil || errJ != nil {
-			log.Printf("Error parsing date: %v, %v", errI, errJ)
-			return false
+// validateSplits - перевіряє, що частини мають категорії і в сумі дають суму транзакції
+func validateSplits(amount float64, splits []models.TransactionSplit) error {
+	var total float64
+	for i, split := range splits {
+		if split.CategoryID == 0 {
+			return fmt.Errorf("split %d: categoryID is required", i+1)
 		}
-		return dateI.After(dateJ) // Найновіші транзакції спочатку
-	})
-	return transactions, nil
+		if split.Amount <= 0 {
+			return fmt.Errorf("split %d: amount must be positive", i+1)
+		}
+		total += split.Amount
+	}
+
+	// Порівнюємо з точністю до копійки
+	if math.Abs(total-amount) > 0.005 {
+		return fmt.Errorf("splits sum %.2f does not match transaction amount %.2f", total, amount)
+	}
+	return nil
+}
+
+// newestFirst - сортування транзакцій у MongoDB: найновіші спочатку
+func newestFirst() *options.FindOptions {
+	return options.Find().SetSort(bson.D{{Key: "date", Value: -1}, {Key: "transactionID", Value: -1}})
 }
 
-// GetTransactionsByDateAndUserID - отримує транзакції за період та userID
+// GetTransactionsByDateAndUserID - отримує транзакції за період та userID, обидві дати включно
 func GetTransactionsByDateAndUserID(startDate, endDate string, userID int) ([]models.Transaction, error) {
 	collection := db.GetTransactionCollection()
 
 	filter := bson.M{
 		"date": bson.M{
 			"$gte": startDate,
-			"$lt":  endDate,
+			"$lte": endDate,
 		},
 		"userID": userID,
 	}
 
-	cursor, err := collection.Find(context.TODO(), filter)
+	cursor, err := collection.Find(context.TODO(), filter, newestFirst())
 	if err != nil {
 		return nil, err
 	}
@@ -165,7 +239,7 @@ func GetTransactionsByDateAndUserID(startDate, endDate string, userID int) ([]mo
 		return nil, err
 	}
 
-	return sortTransactionsByDate(transactions)
+	return transactions, nil
 }
 
 // GetAllTransactions - отримує всі транзакції для користувача
@@ -173,7 +247,7 @@ func GetAllTransactions(userID int) ([]models.Transaction, error) {
 	collection := db.GetTransactionCollection()
 
 	filter := bson.M{"userID": userID}
-	cursor, err := collection.Find(context.TODO(), filter)
+	cursor, err := collection.Find(context.TODO(), filter, newestFirst())
 	if err != nil {
 		return nil, err
 	}
@@ -192,7 +266,28 @@ func GetAllTransactions(userID int) ([]models.Transaction, error) {
 		return nil, err
 	}
 
-	return sortTransactionsByDate(transactions)
+	return transactions, nil
+}
+
+// ListTransactions - отримує сторінку транзакцій користувача з сортуванням у MongoDB; tags залишає лише транзакції з усіма тегами
+func ListTransactions(userID int, tags []string, params ListParams) (Page, error) {
+	filter := bson.M{"userID": userID}
+	if len(tags) > 0 {
+		filter["tags"] = bson.M{"$all": NormalizeTags(tags)}
+	}
+	return findPage(db.GetTransactionCollection(), filter, "transactionID", params)
+}
+
+// ListTransactionsByDate - сторінка транзакцій користувача за період, обидві дати включно
+func ListTransactionsByDate(userID int, startDate, endDate string, params ListParams) (Page, error) {
+	filter := bson.M{"userID": userID, "date": bson.M{"$gte": startDate, "$lte": endDate}}
+	return findPage(db.GetTransactionCollection(), filter, "transactionID", params)
+}
+
+// ListTransactionsByType - сторінка транзакцій користувача заданого типу
+func ListTransactionsByType(userID int, transactionType string, params ListParams) (Page, error) {
+	filter := bson.M{"userID": userID, "type": transactionType}
+	return findPage(db.GetTransactionCollection(), filter, "transactionID", params)
 }
 
 // GetAllTransactionsByUser - отримує всі транзакції для користувача
@@ -205,7 +300,7 @@ func GetAllTransactionsByUser(userID int) ([]models.Transaction, error) {
 	}
 
 	// Отримуємо всі транзакції
-	cursor, err := collection.Find(context.TODO(), filter)
+	cursor, err := collection.Find(context.TODO(), filter, newestFirst())
 	if err != nil {
 		return nil, err
 	}
@@ -224,7 +319,7 @@ func GetAllTransactionsByUser(userID int) ([]models.Transaction, error) {
 		return nil, err
 	}
 
-	return sortTransactionsByDate(transactions)
+	return transactions, nil
 }
 
 // Функція для фільтрації витрат
@@ -327,7 +422,7 @@ func GetTransactionsByUserIDAndType(userID int, transactionType string) ([]model
 	}
 
 	var transactions []models.Transaction
-	cursor, err := collection.Find(context.TODO(), filter)
+	cursor, err := collection.Find(context.TODO(), filter, newestFirst())
 	if err != nil {
 		log.Printf("Error fetching transactions for userID %d and type %s: %v", userID, transactionType, err)
 		return nil, fmt.Errorf("error fetching transactions for userID %d and type %s: %v", userID, transactionType, err)
@@ -348,7 +443,7 @@ func GetTransactionsByUserIDAndType(userID int, transactionType string) ([]model
 		return nil, fmt.Errorf("cursor error: %v", err)
 	}
 
-	return sortTransactionsByDate(transactions)
+	return transactions, nil
 }
 
 func GetTransactionsByUserIDAndTypeWithinDateRange(userID int, transactionType string, startDate, endDate time.Time) ([]models.Transaction, error) {
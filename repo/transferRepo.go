@@ -0,0 +1,274 @@
+package repo
+
+import (
+	"cashWise/db"
+	"cashWise/models"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TransferType - тип транзакцій-частин переказу; такі транзакції не входять у доходи, витрати та бюджети
+const TransferType = "transfer"
+
+// runInTransaction - виконує fn у багатодокументній транзакції MongoDB. Окремий сервер (standalone)
+// транзакцій не підтримує, тож там fn виконується в звичайній сесії без атомарності.
+func runInTransaction(fn func(sessCtx mongo.SessionContext) error) error {
+	session, err := db.Client.StartSession()
+	if err != nil {
+		return fmt.Errorf("error starting session: %v", err)
+	}
+	defer session.EndSession(context.TODO())
+
+	if !db.TransactionsSupported {
+		return mongo.WithSession(context.TODO(), session, fn)
+	}
+
+	_, err = session.WithTransaction(context.TODO(), func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+	return err
+}
+
+// validateTransfer - перевіряє переказ і розраховує суму зарахування
+func validateTransfer(transfer *models.Transfer) error {
+	if transfer.FromAccount == "" || transfer.ToAccount == "" {
+		return errors.New("fromAccount and toAccount are required")
+	}
+	if transfer.FromAccount == transfer.ToAccount {
+		return errors.New("fromAccount and toAccount must differ")
+	}
+	if transfer.Amount <= 0 {
+		return errors.New("amount must be positive")
+	}
+	if transfer.Date == "" {
+		return errors.New("date is required")
+	}
+
+	// Якщо валюти однакові, курс завжди 1
+	if transfer.ToCurrency == "" {
+		transfer.ToCurrency = transfer.FromCurrency
+	}
+	if transfer.FromCurrency == transfer.ToCurrency {
+		transfer.Rate = 1
+	} else if transfer.Rate <= 0 {
+		return errors.New("rate is required when currencies differ")
+	}
+	transfer.ToAmount = transfer.Amount * transfer.Rate
+	return nil
+}
+
+// transferLegs - формує дві частини переказу: списання та зарахування
+func transferLegs(transfer models.Transfer) (models.Transaction, models.Transaction) {
+	out := models.Transaction{
+		UserID:       transfer.UserID,
+		Type:         TransferType,
+		Amount:       transfer.Amount,
+		Date:         transfer.Date,
+		Description:  transfer.Description,
+		Account:      transfer.FromAccount,
+		Currency:     transfer.FromCurrency,
+		TransferID:   transfer.TransferID,
+		TransferSide: "out",
+		ExchangeRate: transfer.Rate,
+	}
+	in := models.Transaction{
+		UserID:       transfer.UserID,
+		Type:         TransferType,
+		Amount:       transfer.ToAmount,
+		Date:         transfer.Date,
+		Description:  transfer.Description,
+		Account:      transfer.ToAccount,
+		Currency:     transfer.ToCurrency,
+		TransferID:   transfer.TransferID,
+		TransferSide: "in",
+		ExchangeRate: transfer.Rate,
+	}
+	return out, in
+}
+
+// CreateTransfer - атомарно створює обидві частини переказу
+func CreateTransfer(transfer models.Transfer) (models.Transfer, error) {
+	if err := validateTransfer(&transfer); err != nil {
+		return transfer, err
+	}
+
+	transferID, err := getNextSequence("transferID")
+	if err != nil {
+		return transfer, fmt.Errorf("failed to get next transfer ID: %v", err)
+	}
+	transfer.TransferID = transferID
+
+	out, in := transferLegs(transfer)
+	if out.TransactionID, err = GetNextTransactionID(); err != nil {
+		return transfer, err
+	}
+	if in.TransactionID, err = GetNextTransactionID(); err != nil {
+		return transfer, err
+	}
+
+	collection := db.GetTransactionCollection()
+	err = runInTransaction(func(sessCtx mongo.SessionContext) error {
+		_, err := collection.InsertMany(sessCtx, []interface{}{out, in})
+		return err
+	})
+	if err != nil {
+		log.Printf("Error creating transfer: %v", err)
+		return transfer, fmt.Errorf("error creating transfer: %v", err)
+	}
+	return transfer, nil
+}
+
+// GetTransferByID - збирає переказ з його частин
+func GetTransferByID(userID int, transferID int) (models.Transfer, error) {
+	transfer := models.Transfer{TransferID: transferID, UserID: userID}
+
+	filter := bson.M{"userID": userID, "transferID": transferID}
+	cursor, err := db.GetTransactionCollection().Find(context.TODO(), filter)
+	if err != nil {
+		return transfer, err
+	}
+	defer cursor.Close(context.TODO())
+
+	var legs []models.Transaction
+	if err := cursor.All(context.TODO(), &legs); err != nil {
+		return transfer, err
+	}
+	if len(legs) == 0 {
+		return transfer, mongo.ErrNoDocuments
+	}
+
+	for _, leg := range legs {
+		transfer.Date = leg.Date
+		transfer.Description = leg.Description
+		transfer.Rate = leg.ExchangeRate
+		if leg.TransferSide == "out" {
+			transfer.FromAccount = leg.Account
+			transfer.FromCurrency = leg.Currency
+			transfer.Amount = leg.Amount
+		} else {
+			transfer.ToAccount = leg.Account
+			transfer.ToCurrency = leg.Currency
+			transfer.ToAmount = leg.Amount
+		}
+	}
+	return transfer, nil
+}
+
+// EditTransfer - оновлює обидві частини переказу разом
+func EditTransfer(userID int, transferID int, updated models.Transfer) (models.Transfer, error) {
+	current, err := GetTransferByID(userID, transferID)
+	if err != nil {
+		return current, err
+	}
+
+	fromCurrency, toCurrency := current.FromCurrency, current.ToCurrency
+
+	// Беремо лише передані поля, решту залишаємо як було
+	if updated.FromAccount != "" {
+		current.FromAccount = updated.FromAccount
+	}
+	if updated.ToAccount != "" {
+		current.ToAccount = updated.ToAccount
+	}
+	if updated.FromCurrency != "" {
+		current.FromCurrency = updated.FromCurrency
+	}
+	if updated.ToCurrency != "" {
+		current.ToCurrency = updated.ToCurrency
+	}
+	if updated.Amount != 0 {
+		current.Amount = updated.Amount
+	}
+	if updated.Rate != 0 {
+		current.Rate = updated.Rate
+	}
+	if updated.Date != "" {
+		current.Date = updated.Date
+	}
+	if updated.Description != "" {
+		current.Description = updated.Description
+	}
+	// Старий курс стосується старої пари валют, тож для нової пари курс треба передати явно
+	pairChanged := current.FromCurrency != fromCurrency || current.ToCurrency != toCurrency
+	if pairChanged && updated.Rate == 0 && current.FromCurrency != current.ToCurrency {
+		return current, errors.New("rate is required when the currency pair changes")
+	}
+	if err := validateTransfer(&current); err != nil {
+		return current, err
+	}
+
+	out, in := transferLegs(current)
+	collection := db.GetTransactionCollection()
+	err = runInTransaction(func(sessCtx mongo.SessionContext) error {
+		for _, leg := range []models.Transaction{out, in} {
+			filter := bson.M{"userID": userID, "transferID": transferID, "transferSide": leg.TransferSide}
+			update := bson.M{"$set": bson.M{
+				"amount":       leg.Amount,
+				"date":         leg.Date,
+				"description":  leg.Description,
+				"account":      leg.Account,
+				"currency":     leg.Currency,
+				"exchangeRate": leg.ExchangeRate,
+			}}
+			if _, err := collection.UpdateOne(sessCtx, filter, update); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error updating transfer: %v", err)
+		return current, fmt.Errorf("error updating transfer: %v", err)
+	}
+	return current, nil
+}
+
+// DeleteTransfer - видаляє обидві частини переказу разом
+func DeleteTransfer(userID int, transferID int) error {
+	collection := db.GetTransactionCollection()
+	filter := bson.M{"userID": userID, "transferID": transferID}
+
+	// ID частин потрібні, щоб після видалення прибрати їхні вкладення
+	parts, err := findTransactions(filter)
+	if err != nil {
+		return err
+	}
+
+	err = runInTransaction(func(sessCtx mongo.SessionContext) error {
+		result, err := collection.DeleteMany(sessCtx, filter)
+		if err != nil {
+			return err
+		}
+		if result.DeletedCount == 0 {
+			return mongo.ErrNoDocuments
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	transactionIDs := make([]int, 0, len(parts))
+	for _, part := range parts {
+		transactionIDs = append(transactionIDs, part.TransactionID)
+	}
+	deleteTransactionAttachments(userID, transactionIDs)
+	return nil
+}
+
+// getTransferIDOfTransaction - повертає transferID транзакції або 0, якщо вона не є частиною переказу
+func getTransferIDOfTransaction(filter bson.M) (int, error) {
+	var transaction models.Transaction
+	opts := options.FindOne().SetProjection(bson.M{"transferID": 1})
+	err := db.GetTransactionCollection().FindOne(context.TODO(), filter, opts).Decode(&transaction)
+	if err != nil {
+		return 0, err
+	}
+	return transaction.TransferID, nil
+}
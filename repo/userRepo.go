@@ -207,14 +207,16 @@ func GetUserAndSettings(userID int) (map[string]interface{}, error) {
 
 	// Формуємо результат
 	userData := map[string]interface{}{
-		"userID":         user.UserID,
-		"fullName":       user.FullName,
-		"email":          user.Email,
-		"profilePicture": user.ProfilePicture,
-		"role":           user.Role,
-		"darkTheme":      settings.DarkTheme,
-		"termsCondition": settings.TermsCondition,
-		"notifications":  settings.Notifications, // Перевір, чи правильне ім'я поля в твоїй моделі
+		"userID":           user.UserID,
+		"fullName":         user.FullName,
+		"email":            user.Email,
+		"profilePicture":   user.ProfilePicture,
+		"role":             user.Role,
+		"darkTheme":        settings.DarkTheme,
+		"termsCondition":   settings.TermsCondition,
+		"notifications":    settings.Notifications, // Перевір, чи правильне ім'я поля в твоїй моделі
+		"fiscalMonthStart": settings.FiscalMonthStart,
+		"weekStart":        settings.WeekStart,
 	}
 
 	return userData, nil
@@ -234,3 +236,43 @@ func GetSettingsByUserID(userID int) (*models.Settings, error) {
 
 	return &settings, nil
 }
+
+// UpdatePeriodSettings - оновлює день початку фінансового місяця та перший день тижня
+func UpdatePeriodSettings(userID int, fiscalMonthStart *int, weekStart *int) error {
+	update := bson.M{"$set": bson.M{}}
+	if fiscalMonthStart != nil {
+		update["$set"].(bson.M)["fiscalMonthStart"] = *fiscalMonthStart
+	}
+	if weekStart != nil {
+		update["$set"].(bson.M)["weekStart"] = *weekStart
+	}
+	if len(update["$set"].(bson.M)) == 0 {
+		return errors.New("no fields to update")
+	}
+
+	result, err := db.GetSettingCollection().UpdateOne(context.TODO(), bson.M{"userID": userID}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update period settings for userID %d: %v", userID, err)
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("settings not found")
+	}
+	return nil
+}
+
+// SetEnvelopeStart - вмикає конвертний бюджет з дати start або вимикає його, якщо start порожній
+func SetEnvelopeStart(userID int, start string) error {
+	update := bson.M{"$set": bson.M{"envelopeStart": start}}
+	if start == "" {
+		update = bson.M{"$unset": bson.M{"envelopeStart": ""}}
+	}
+
+	result, err := db.GetSettingCollection().UpdateOne(context.TODO(), bson.M{"userID": userID}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update envelope mode for userID %d: %v", userID, err)
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("settings not found")
+	}
+	return nil
+}
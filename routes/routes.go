@@ -32,14 +32,20 @@ func InitializeRoutes() *mux.Router {
 	r.HandleFunc("/settings/toggle-dark-theme", handlers.HandleToggleDarkTheme).Methods("POST")
 	r.HandleFunc("/settings/toggle-terms-condition", handlers.ToggleTermsConditionHandler).Methods("POST")
 	r.HandleFunc("/settings/notification", handlers.ToggleNotificationsHandler).Methods("POST")
+	r.HandleFunc("/settings/periods", handlers.UpdatePeriodSettingsHandler).Methods("PUT")
+	r.HandleFunc("/settings/envelope-mode", handlers.UpdateEnvelopeModeHandler).Methods("PUT")
 
 	r.HandleFunc("/getUserAndSettings", handlers.GetUserAndSettingsHandler).Methods("GET")
 
 	// Маршрути для категорій
 	r.HandleFunc("/create-category", handlers.CreateCategory).Methods("POST")
 	r.HandleFunc("/edit-category/{categoryID}", handlers.EditCategory).Methods("PUT")
-	r.HandleFunc("/delete-category/{categoryID}", handlers.DeleteCategory).Methods("DELETE")
+	r.HandleFunc("/delete-category/{categoryID}", handlers.DeleteCategory).Methods("DELETE")   // replaceWith або archive=true для категорії, що використовується
+	r.HandleFunc("/categories/{categoryID}/restore", handlers.RestoreCategory).Methods("POST") // Повернути з архіву
 	r.HandleFunc("/categories", handlers.GetAllCategoriesByUserID).Methods("GET")
+	r.HandleFunc("/categories/suggest", handlers.SuggestCategories).Methods("GET")       // Підказка категорії за описом
+	r.HandleFunc("/categories/tree", handlers.GetCategoryTree).Methods("GET")            // Категорії з підкатегоріями
+	r.HandleFunc("/categories/{categoryID}/move", handlers.MoveCategory).Methods("POST") // Перенести гілку під іншу категорію
 	r.HandleFunc("/category", handlers.GetCategoryByName).Methods("GET")
 	r.HandleFunc("/get/category", handlers.GetCategoryByID).Methods("GET")
 
@@ -57,8 +63,92 @@ func InitializeRoutes() *mux.Router {
 	r.HandleFunc("/transactions-goal", handlers.GetTransactionsByUserIDAndTypeHandler).Methods("GET") // Отримати всі транзакції за userID та type
 	r.HandleFunc("/transaction/details", handlers.GetTransactionWithCategoryHandler).Methods("GET")
 	r.HandleFunc("/transactionsIcon", handlers.GetTransactionsHandler).Methods("GET")
+	r.HandleFunc("/transactions/search", handlers.SearchTransactions).Methods("GET")                      // Пошук за довільними умовами
+	r.HandleFunc("/transactions/export", handlers.ExportTransactions).Methods("GET")                      // Експорт у CSV, XLSX або JSON
+	r.HandleFunc("/transactions/duplicates", handlers.GetDuplicates).Methods("GET")                       // Ймовірні дублікати
+	r.HandleFunc("/transactions/duplicates/merge", handlers.MergeDuplicates).Methods("POST")              // Злити дублікати в одну транзакцію
+	r.HandleFunc("/transactions/duplicates/{transactionID}", handlers.DismissDuplicate).Methods("DELETE") // Це не дублікат
+
+	// Чеки та інші вкладення транзакцій
+	r.HandleFunc("/transaction/{transactionID}/attachments", handlers.UploadAttachment).Methods("POST") // multipart, поле file
+	r.HandleFunc("/transaction/{transactionID}/attachments", handlers.GetAttachments).Methods("GET")
+	r.HandleFunc("/attachments/{attachmentID}", handlers.DownloadAttachment).Methods("GET") // thumbnail=true - мініатюра
+	r.HandleFunc("/attachments/{attachmentID}", handlers.DeleteAttachment).Methods("DELETE")
+
+	// Імпорт банківських виписок
+	r.HandleFunc("/import/csv", handlers.ImportCSV).Methods("POST")
+	r.HandleFunc("/import/ofx", handlers.ImportOFX).Methods("POST") // OFX та QFX
+	r.HandleFunc("/import/qif", handlers.ImportQIF).Methods("POST")
+	r.HandleFunc("/import/camt053", handlers.ImportCAMT).Methods("POST")
+	r.HandleFunc("/import/mt940", handlers.ImportMT940).Methods("POST")
+	r.HandleFunc("/import/profiles", handlers.CreateImportProfile).Methods("POST")
+	r.HandleFunc("/import/profiles", handlers.GetImportProfiles).Methods("GET")
+	r.HandleFunc("/import/profiles/{profileID}", handlers.DeleteImportProfile).Methods("DELETE")
+	r.HandleFunc("/import/batches", handlers.GetImportBatches).Methods("GET")
+	r.HandleFunc("/import/batches/{batchID}", handlers.RollbackImportBatch).Methods("DELETE") // Відкотити імпорт
+
+	// Теги транзакцій
+	r.HandleFunc("/tags", handlers.GetTags).Methods("GET") // Автодоповнення за prefix
+	r.HandleFunc("/tags/merge", handlers.MergeTags).Methods("POST")
+	r.HandleFunc("/tags/{tag}", handlers.RenameTag).Methods("PUT")
+	r.HandleFunc("/tags/{tag}", handlers.DeleteTag).Methods("DELETE")
+
+	// Довідник отримувачів
+	r.HandleFunc("/payees", handlers.CreatePayee).Methods("POST")
+	r.HandleFunc("/payees", handlers.GetPayees).Methods("GET")
+	r.HandleFunc("/payees/merge", handlers.MergePayees).Methods("POST")
+	r.HandleFunc("/payees/{payeeID}", handlers.UpdatePayee).Methods("PUT")
+	r.HandleFunc("/payees/{payeeID}", handlers.DeletePayee).Methods("DELETE")
+	r.HandleFunc("/payees/{payeeID}/split", handlers.SplitPayee).Methods("POST")
+	r.HandleFunc("/payees/{payeeID}/stats", handlers.GetPayeeStats).Methods("GET") // Витрати за місяцями
+
+	// Правила автоматичної категоризації
+	r.HandleFunc("/rules", handlers.CreateRule).Methods("POST")
+	r.HandleFunc("/rules", handlers.GetRules).Methods("GET")
+	r.HandleFunc("/rules/test", handlers.TestRule).Methods("POST")    // Перевірити правило на історії
+	r.HandleFunc("/rules/apply", handlers.ApplyRules).Methods("POST") // Застосувати правила до наявних транзакцій
+	r.HandleFunc("/rules/{ruleID}", handlers.UpdateRule).Methods("PUT")
+	r.HandleFunc("/rules/{ruleID}", handlers.DeleteRule).Methods("DELETE")
+
+	// Збережені пошуки
+	r.HandleFunc("/searches", handlers.CreateSavedSearch).Methods("POST")
+	r.HandleFunc("/searches", handlers.GetSavedSearches).Methods("GET")
+	r.HandleFunc("/searches/{searchID}/run", handlers.RunSavedSearch).Methods("GET")
+	r.HandleFunc("/searches/{searchID}", handlers.DeleteSavedSearch).Methods("DELETE")
+
+	// Перекази між рахунками
+	r.HandleFunc("/transfer", handlers.CreateTransfer).Methods("POST")                // Створити переказ
+	r.HandleFunc("/transfer/{transferID}", handlers.GetTransfer).Methods("GET")       // Отримати переказ
+	r.HandleFunc("/transfer/{transferID}", handlers.EditTransfer).Methods("PUT")      // Редагувати обидві частини
+	r.HandleFunc("/transfer/{transferID}", handlers.DeleteTransfer).Methods("DELETE") // Видалити обидві частини
+
+	// Транзакції, що повторюються
+	r.HandleFunc("/recurring", handlers.CreateRecurringHandler).Methods("POST")                               // Створити шаблон
+	r.HandleFunc("/recurring", handlers.GetRecurringHandler).Methods("GET")                                   // Отримати всі шаблони
+	r.HandleFunc("/recurring/upcoming", handlers.GetUpcomingOccurrencesHandler).Methods("GET")                // Повторення на N днів вперед
+	r.HandleFunc("/recurring/{recurringID}", handlers.EditRecurringHandler).Methods("PUT")                    // Оновити шаблон
+	r.HandleFunc("/recurring/{recurringID}", handlers.DeleteRecurringHandler).Methods("DELETE")               // Видалити шаблон
+	r.HandleFunc("/recurring/{recurringID}/occurrences/{date}", handlers.SetOccurrenceHandler).Methods("PUT") // Пропустити або змінити одне повторення
+
+	// Регулярні платежі, знайдені в історії витрат
+	r.HandleFunc("/subscriptions", handlers.GetSubscriptionsHandler).Methods("GET")
+	r.HandleFunc("/subscriptions/{key}/confirm", handlers.ConfirmSubscriptionHandler).Methods("POST")
+	r.HandleFunc("/subscriptions/{key}/dismiss", handlers.DismissSubscriptionHandler).Methods("POST")
+
+	// Звіт за категоріями (з урахуванням розбиття транзакцій)
+	r.HandleFunc("/reports/categories", handlers.GetCategoryReport).Methods("GET")
+	r.HandleFunc("/reports/tags", handlers.GetTagReport).Methods("GET")             // Суми за тегами
+	r.HandleFunc("/reports/statement.pdf", handlers.GetStatementPDF).Methods("GET") // Місячна виписка у PDF
 
 	r.HandleFunc("/budgets", handlers.CreateBudget).Methods("POST") // Створити бюджет
+	r.HandleFunc("/budgets", handlers.GetBudgets).Methods("GET")    // Бюджети користувача з фактичними витратами
+
+	// Шаблони бюджетів і масове створення; до маршрутів з {budgetID}, щоб не сприйматися як ID
+	r.HandleFunc("/budgets/templates", handlers.GetBudgetTemplates).Methods("GET")
+	r.HandleFunc("/budgets/templates", handlers.CreateBudgetTemplate).Methods("POST")
+	r.HandleFunc("/budgets/templates/apply", handlers.ApplyBudgetTemplate).Methods("POST")
+	r.HandleFunc("/budgets/templates/{templateID}", handlers.DeleteBudgetTemplate).Methods("DELETE")
+	r.HandleFunc("/budgets/restore-limits", handlers.RestoreLastMonthLimits).Methods("POST")
 
 	// Отримання бюджету за ID
 	r.HandleFunc("/budgets/{budgetID}", handlers.GetBudgetByID).Methods("GET")
@@ -68,6 +158,19 @@ func InitializeRoutes() *mux.Router {
 	r.HandleFunc("/budgets/{budgetID}", handlers.DeleteBudget).Methods("DELETE")
 	// Перевірка ліміту бюджету
 	r.HandleFunc("/budgets/{budgetID}/check-limit", handlers.CheckLimit).Methods("GET")
+	// Витрачено, залишок і денний ліміт бюджету за період
+	r.HandleFunc("/budgets/{budgetID}/status", handlers.GetBudgetStatus).Methods("GET")
+	// Підсумки закритих періодів бюджету
+	r.HandleFunc("/budgets/{budgetID}/history", handlers.GetBudgetHistory).Methods("GET")
+	// Категорії, що входять у бюджет
+	r.HandleFunc("/budgets/{budgetID}/categories", handlers.GetCategoriesByBudgetID).Methods("GET")
+
+	// Конвертний бюджет
+	r.HandleFunc("/envelopes", handlers.GetEnvelopes).Methods("GET")                            // Доступні гроші в конвертах за місяць
+	r.HandleFunc("/envelopes/assign", handlers.AssignEnvelope).Methods("POST")                  // Розподілити з пулу в конверт
+	r.HandleFunc("/envelopes/move", handlers.MoveEnvelopeMoney).Methods("POST")                 // Перемістити між конвертами
+	r.HandleFunc("/envelopes/movements", handlers.GetEnvelopeMovements).Methods("GET")          // Історія переміщень
+	r.HandleFunc("/envelopes/copy-last-month", handlers.CopyLastMonthEnvelopes).Methods("POST") // Повторити розподіл минулого місяця
 
 	//Получение текущих накоплений
 	r.HandleFunc("/goals", handlers.CreateGoalHandler).Methods("POST")            // Створення нової фінансової цілі
@@ -80,6 +183,10 @@ func InitializeRoutes() *mux.Router {
 	// Роут для нагадування про транзакції "goal"
 	r.HandleFunc("/goal-reminder", handlers.ToggleGoalReminderHandler).Methods("GET")
 
+	// Сповіщення користувача (зокрема про пороги бюджетів)
+	r.HandleFunc("/notifications", handlers.GetNotifications).Methods("GET")
+	r.HandleFunc("/notifications/{notificationID}/read", handlers.MarkNotificationRead).Methods("POST")
+
 	return r
 }
 
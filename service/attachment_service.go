@@ -0,0 +1,152 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "image/gif" // Декодери форматів для мініатюр
+	_ "image/png"
+
+	"cashWise/models"
+	"cashWise/repo"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MaxAttachmentSize - максимальний розмір одного вкладення
+const MaxAttachmentSize = 10 << 20
+
+// maxAttachmentsPerTransaction - скільки файлів можна прикріпити до однієї транзакції
+const maxAttachmentsPerTransaction = 20
+
+// thumbnailSize - найбільша сторона мініатюри в пікселях
+const thumbnailSize = 256
+
+// maxThumbnailPixels - зображення, більші за це, не розпаковуються в пам'ять для мініатюри
+const maxThumbnailPixels = 50_000_000
+
+// attachmentTypes - дозволені типи вкладень, визначені за вмістом файла
+var attachmentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+// AddAttachment - перевіряє файл за вмістом і прикріплює його до транзакції. Для зображень
+// створюється мініатюра; якщо зображення не вдалося розібрати, вкладення зберігається без неї.
+func AddAttachment(userID int, transactionID int, fileName string, data []byte) (models.Attachment, error) {
+	if len(data) == 0 {
+		return models.Attachment{}, fmt.Errorf("file is empty")
+	}
+	if len(data) > MaxAttachmentSize {
+		return models.Attachment{}, fmt.Errorf("file is larger than %d bytes", MaxAttachmentSize)
+	}
+
+	// Заголовку Content-Type від клієнта не довіряємо: тип визначаємо за першими байтами
+	contentType := http.DetectContentType(data)
+	if !attachmentTypes[contentType] {
+		return models.Attachment{}, fmt.Errorf("unsupported file type %s: only images and PDF are allowed", contentType)
+	}
+
+	transactions, err := repo.GetTransactionsByIDs(userID, []int{transactionID})
+	if err != nil {
+		return models.Attachment{}, err
+	}
+	if len(transactions) == 0 {
+		return models.Attachment{}, mongo.ErrNoDocuments
+	}
+	count, err := repo.CountAttachments(userID, transactionID)
+	if err != nil {
+		return models.Attachment{}, err
+	}
+	if count >= maxAttachmentsPerTransaction {
+		return models.Attachment{}, fmt.Errorf("transaction already has %d attachments", count)
+	}
+
+	var thumbnail []byte
+	if strings.HasPrefix(contentType, "image/") {
+		thumbnail, _ = makeThumbnail(data)
+	}
+
+	attachment := models.Attachment{
+		UserID:        userID,
+		TransactionID: transactionID,
+		FileName:      attachmentFileName(fileName, contentType),
+		ContentType:   contentType,
+		Size:          int64(len(data)),
+		CreatedAt:     time.Now().UTC().Format(time.RFC3339),
+	}
+	return repo.SaveAttachment(attachment, data, thumbnail)
+}
+
+// attachmentFileName - ім'я файла без шляху; без імені підставляється назва за типом
+func attachmentFileName(fileName string, contentType string) string {
+	fileName = strings.TrimSpace(filepath.Base(strings.ReplaceAll(fileName, "\\", "/")))
+	if fileName != "" && fileName != "." && fileName != "/" {
+		return fileName
+	}
+	return "attachment." + strings.TrimPrefix(strings.TrimPrefix(contentType, "image/"), "application/")
+}
+
+// makeThumbnail - зменшене JPEG-зображення, що вміщується в квадрат thumbnailSize. Прозорі
+// ділянки заливаються білим; webp стандартна бібліотека не розбирає, тож мініатюри для нього немає.
+func makeThumbnail(data []byte) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width*config.Height > maxThumbnailPixels {
+		return nil, fmt.Errorf("image is too large for a thumbnail")
+	}
+	source, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := source.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return nil, fmt.Errorf("image is empty")
+	}
+	scale := float64(thumbnailSize) / float64(max(width, height))
+	if scale > 1 {
+		scale = 1
+	}
+	thumbWidth, thumbHeight := max(1, int(float64(width)*scale)), max(1, int(float64(height)*scale))
+
+	// Кожен піксель мініатюри - середнє рівномірної сітки точок відповідного блоку оригіналу
+	thumb := image.NewRGBA(image.Rect(0, 0, thumbWidth, thumbHeight))
+	for y := 0; y < thumbHeight; y++ {
+		y0, y1 := y*height/thumbHeight, max((y+1)*height/thumbHeight, y*height/thumbHeight+1)
+		for x := 0; x < thumbWidth; x++ {
+			x0, x1 := x*width/thumbWidth, max((x+1)*width/thumbWidth, x*width/thumbWidth+1)
+			var r, g, b, samples uint32
+			for sy := y0; sy < y1; sy += max(1, (y1-y0)/4) {
+				for sx := x0; sx < x1; sx += max(1, (x1-x0)/4) {
+					pr, pg, pb, pa := source.At(bounds.Min.X+sx, bounds.Min.Y+sy).RGBA()
+					// Кольори вже помножені на альфу, тож білий фон додається як 0xffff - a
+					r += (pr + 0xffff - pa) >> 8
+					g += (pg + 0xffff - pa) >> 8
+					b += (pb + 0xffff - pa) >> 8
+					samples++
+				}
+			}
+			thumb.Set(x, y, color.RGBA{R: uint8(r / samples), G: uint8(g / samples), B: uint8(b / samples), A: 0xff})
+		}
+	}
+
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, thumb, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,422 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"cashWise/models"
+	"cashWise/repo"
+)
+
+// budgetPeriod - період бюджету: відповідний календарний період ResolvePeriod і тривалість
+// для періодів від власної дати початку (у місяцях або, для тижневого, у днях)
+type budgetPeriod struct {
+	calendar string
+	months   int
+	days     int
+}
+
+// budgetPeriods - підтримувані періоди бюджету
+var budgetPeriods = map[string]budgetPeriod{
+	"weekly":    {"week", 0, 7},
+	"monthly":   {"month", 1, 0},
+	"quarterly": {"quarter", 3, 0},
+	"yearly":    {"year", 12, 0},
+}
+
+// budgetCarryOver - допустимі режими перенесення залишку
+var budgetCarryOver = map[string]bool{"": true, "unspent": true, "overspent": true, "all": true}
+
+// budgetPeriodRange - межі періоду бюджету, що містить date. Без startDate періоди календарні
+// з урахуванням налаштувань користувача, інакше відлічуються від startDate. Бюджети, створені
+// до появи фіксованих періодів, з порожнім чи довільним period вважаються місячними.
+func budgetPeriodRange(budget models.Budget, date time.Time) (DateRange, error) {
+	period, ok := budgetPeriods[budget.Period]
+	if !ok {
+		period = budgetPeriods["monthly"]
+	}
+	if budget.StartDate == "" {
+		return ResolvePeriod(period.calendar, date, PeriodOptionsForUser(budget.UserID))
+	}
+
+	anchor, err := time.Parse(dateLayout, budget.StartDate)
+	if err != nil {
+		return DateRange{}, fmt.Errorf("startDate must be in YYYY-MM-DD format")
+	}
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	if period.days > 0 {
+		offset := floorDiv(int(day.Sub(anchor).Hours()/24), period.days) * period.days
+		start := anchor.AddDate(0, 0, offset)
+		return DateRange{Start: start, End: start.AddDate(0, 0, period.days-1)}, nil
+	}
+
+	// Місячні періоди починаються в день startDate, а в коротших місяцях - в останній день місяця
+	offset := floorDiv((day.Year()-anchor.Year())*12+int(day.Month()-anchor.Month()), period.months) * period.months
+	start := monthlyDate(anchor.Year(), anchor.Month()+time.Month(offset), anchor.Day())
+	if start.After(day) {
+		offset -= period.months
+		start = monthlyDate(anchor.Year(), anchor.Month()+time.Month(offset), anchor.Day())
+	}
+	next := monthlyDate(anchor.Year(), anchor.Month()+time.Month(offset+period.months), anchor.Day())
+	return DateRange{Start: start, End: next.AddDate(0, 0, -1)}, nil
+}
+
+// floorDiv - ділення з округленням донизу, щоб дати до startDate теж потрапляли у свій період
+func floorDiv(a, b int) int {
+	if a < 0 && a%b != 0 {
+		return a/b - 1
+	}
+	return a / b
+}
+
+// CurrentBudgetPeriod - межі періоду бюджету, що триває зараз
+func CurrentBudgetPeriod(budget models.Budget) (DateRange, error) {
+	return budgetPeriodRange(budget, time.Now().UTC())
+}
+
+// ValidateBudget - перевіряє ліміт, період, режим перенесення, пороги сповіщень і те, що всі категорії бюджету існують
+func ValidateBudget(budget models.Budget) error {
+	if budget.Limit <= 0 {
+		return fmt.Errorf("limit must be positive")
+	}
+	if _, ok := budgetPeriods[budget.Period]; !ok {
+		return fmt.Errorf("invalid period %q, must be weekly, monthly, quarterly or yearly", budget.Period)
+	}
+	if !budgetCarryOver[budget.CarryOver] {
+		return fmt.Errorf("invalid carryOver %q, must be unspent, overspent or all", budget.CarryOver)
+	}
+	if _, err := CurrentBudgetPeriod(budget); err != nil {
+		return err
+	}
+	for i, threshold := range budget.Thresholds {
+		if threshold <= 0 {
+			return fmt.Errorf("thresholds must be positive percentages")
+		}
+		for _, other := range budget.Thresholds[:i] {
+			if other == threshold {
+				return fmt.Errorf("threshold %g%% is listed twice", threshold)
+			}
+		}
+	}
+	if len(budget.CategoryIDs) == 0 {
+		return nil
+	}
+
+	hierarchy, err := loadCategoryHierarchy(budget.UserID)
+	if err != nil {
+		return err
+	}
+	for _, categoryID := range budget.CategoryIDs {
+		if _, ok := hierarchy.byID[categoryID]; !ok {
+			return fmt.Errorf("category %d not found", categoryID)
+		}
+	}
+	return nil
+}
+
+// CreateBudget - створює перевірений бюджет з ID з лічильника; перший період - той, що триває зараз
+func CreateBudget(budget models.Budget) (models.Budget, error) {
+	period, err := CurrentBudgetPeriod(budget)
+	if err != nil {
+		return budget, err
+	}
+	budget.BudgetID = 0
+	budget.PeriodStart, budget.CarriedOver = period.StartString(), 0
+	return repo.CreateBudget(budget)
+}
+
+// budgetCategoryIDs - категорії, витрати за якими входять у бюджет; nil - усі витрати
+func budgetCategoryIDs(budget models.Budget) ([]int, error) {
+	if len(budget.CategoryIDs) == 0 || !budget.IncludeSubcategories {
+		return budget.CategoryIDs, nil
+	}
+	hierarchy, err := loadCategoryHierarchy(budget.UserID)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int
+	for _, categoryID := range budget.CategoryIDs {
+		if _, ok := hierarchy.byID[categoryID]; !ok {
+			ids = append(ids, categoryID)
+			continue
+		}
+		ids = append(ids, hierarchy.subtree(categoryID)...)
+	}
+	return ids, nil
+}
+
+// GetBudgetCategories - категорії, витрати за якими входять у бюджет
+func GetBudgetCategories(budget models.Budget) ([]models.Category, error) {
+	categories, err := repo.GetCategories(budget.UserID)
+	if err != nil {
+		return nil, err
+	}
+	categoryIDs, err := budgetCategoryIDs(budget)
+	if err != nil || len(categoryIDs) == 0 {
+		return categories, err
+	}
+
+	selected := []models.Category{}
+	for _, category := range categories {
+		if containsID(categoryIDs, category.CategoryID) {
+			selected = append(selected, category)
+		}
+	}
+	return selected, nil
+}
+
+// GetBudgetSpent - фактичні витрати за бюджетом у діапазоні дат
+func GetBudgetSpent(budget models.Budget, dates DateRange) (float64, error) {
+	categoryIDs, err := budgetCategoryIDs(budget)
+	if err != nil {
+		return 0, err
+	}
+	return repo.GetBudgetSpending(budget.UserID, categoryIDs, dates.StartString(), dates.EndString())
+}
+
+// GetBudgetStatus - стан бюджету в періоді, що містить date: витрачено, залишок, відсоток
+// використання та денний ліміт на решту періоду, включно з сьогоднішнім днем. Перенесена
+// сума враховується лише для поточного періоду бюджету.
+func GetBudgetStatus(budget models.Budget, date time.Time) (models.BudgetStatus, error) {
+	dates, err := budgetPeriodRange(budget, date)
+	if err != nil {
+		return models.BudgetStatus{}, err
+	}
+	// Після зміни розкладу поточний період може початися посередині: рахуємо лише від periodStart
+	if start, err := time.Parse(dateLayout, budget.PeriodStart); err == nil && start.After(dates.Start) && !start.After(dates.End) {
+		dates.Start = start
+	}
+	carried := 0.0
+	if dates.StartString() == budget.PeriodStart {
+		carried = budget.CarriedOver
+	}
+	return budgetStatus(budget, dates, carried, date)
+}
+
+// budgetStatus - стан бюджету за діапазоном dates на дату today з перенесеною сумою carried
+func budgetStatus(budget models.Budget, dates DateRange, carried float64, today time.Time) (models.BudgetStatus, error) {
+	spent, err := GetBudgetSpent(budget, dates)
+	if err != nil {
+		return models.BudgetStatus{}, err
+	}
+
+	available := budget.Limit + carried
+	status := models.BudgetStatus{
+		Budget:      budget,
+		From:        dates.StartString(),
+		To:          dates.EndString(),
+		Available:   math.Round(available*100) / 100,
+		Spent:       math.Round(spent*100) / 100,
+		Remaining:   math.Round((available-spent)*100) / 100,
+		PercentUsed: 100,
+		Exceeded:    spent > available,
+	}
+	// Якщо перевитрата минулого періоду з'їла весь ліміт, бюджет уже вичерпано
+	if available > 0 {
+		status.PercentUsed = math.Round(spent/available*10000) / 100
+	}
+
+	day := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	if !day.After(dates.End) {
+		if day.Before(dates.Start) {
+			day = dates.Start
+		}
+		status.DaysLeft = int(dates.End.Sub(day).Hours()/24) + 1
+	}
+	if status.DaysLeft > 0 && status.Remaining > 0 {
+		status.DailyAllowance = math.Floor(status.Remaining/float64(status.DaysLeft)*100) / 100
+	}
+	return status, nil
+}
+
+// budgetCarry - сума, що переходить у наступний період за режимом carryOver
+func budgetCarry(budget models.Budget, remaining float64) float64 {
+	switch budget.CarryOver {
+	case "all":
+		return remaining
+	case "unspent":
+		return math.Max(remaining, 0)
+	case "overspent":
+		return math.Min(remaining, 0)
+	}
+	return 0
+}
+
+// RollOverBudget - закриває всі періоди бюджету, що завершилися до today: для кожного зберігає
+// незмінний підсумок і переносить залишок у наступний період. Повертає бюджет з актуальним періодом.
+func RollOverBudget(budget models.Budget, today time.Time) (models.Budget, error) {
+	current, err := budgetPeriodRange(budget, today)
+	if err != nil {
+		return budget, err
+	}
+	// Бюджет без periodStart створено до появи історії: відлік починається з поточного періоду
+	if budget.PeriodStart == "" {
+		if err := repo.SetBudgetPeriod(budget.BudgetID, current.StartString(), 0); err != nil {
+			return budget, err
+		}
+		budget.PeriodStart, budget.CarriedOver = current.StartString(), 0
+		return budget, nil
+	}
+
+	for budget.PeriodStart < current.StartString() {
+		start, err := time.Parse(dateLayout, budget.PeriodStart)
+		if err != nil {
+			return budget, fmt.Errorf("invalid periodStart %q of budget %d", budget.PeriodStart, budget.BudgetID)
+		}
+		dates, err := budgetPeriodRange(budget, start)
+		if err != nil {
+			return budget, err
+		}
+		dates.Start = start
+		status, err := budgetStatus(budget, dates, budget.CarriedOver, today)
+		if err != nil {
+			return budget, err
+		}
+
+		carry := math.Round(budgetCarry(budget, status.Remaining)*100) / 100
+		next := dates.End.AddDate(0, 0, 1).Format(dateLayout)
+		closed, err := repo.CloseBudgetPeriod(budgetSnapshot(budget, status, carry), next, carry)
+		if err != nil {
+			return budget, err
+		}
+		if !closed {
+			// Період закрив паралельний запит - продовжуємо з того стану, який він залишив
+			if budget, err = repo.GetBudgetByID(budget.BudgetID); err != nil {
+				return budget, err
+			}
+			continue
+		}
+		budget.PeriodStart, budget.CarriedOver = next, carry
+	}
+	return budget, nil
+}
+
+// budgetSnapshot - незмінний підсумок закритого періоду бюджету
+func budgetSnapshot(budget models.Budget, status models.BudgetStatus, carry float64) models.BudgetSnapshot {
+	return models.BudgetSnapshot{
+		BudgetID:    budget.BudgetID,
+		UserID:      budget.UserID,
+		Name:        budget.Name,
+		Period:      budget.Period,
+		From:        status.From,
+		To:          status.To,
+		Limit:       budget.Limit,
+		CarriedIn:   budget.CarriedOver,
+		Available:   status.Available,
+		Spent:       status.Spent,
+		Remaining:   status.Remaining,
+		PercentUsed: status.PercentUsed,
+		Exceeded:    status.Exceeded,
+		CarriedOut:  carry,
+		ClosedAt:    time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// RestartBudgetPeriod - готує бюджет до зміни розкладу (period або startDate): закриває завершені
+// періоди за старим розкладом, а період, що триває, - до початку поточного періоду нового розкладу.
+// Новий відлік починається без перенесення. Якщо новий період почався не пізніше за поточний
+// periodStart, період, що триває, просто продовжується за новим розкладом.
+func RestartBudgetPeriod(existing models.Budget, changed models.Budget, today time.Time) error {
+	existing, err := RollOverBudget(existing, today)
+	if err != nil {
+		return err
+	}
+	current, err := budgetPeriodRange(changed, today)
+	if err != nil {
+		return err
+	}
+	nextStart := current.StartString()
+	if nextStart <= existing.PeriodStart {
+		return nil
+	}
+	// periodStart ніколи не має опинитися на або перед початком уже збереженого підсумку
+	snapshots, err := repo.GetBudgetSnapshots(existing.BudgetID)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) > 0 && nextStart <= snapshots[0].From {
+		return nil
+	}
+
+	start, err := time.Parse(dateLayout, existing.PeriodStart)
+	if err != nil {
+		return fmt.Errorf("invalid periodStart %q of budget %d", existing.PeriodStart, existing.BudgetID)
+	}
+	dates := DateRange{Start: start, End: current.Start.AddDate(0, 0, -1)}
+	status, err := budgetStatus(existing, dates, existing.CarriedOver, today)
+	if err != nil {
+		return err
+	}
+	_, err = repo.CloseBudgetPeriod(budgetSnapshot(existing, status, 0), nextStart, 0)
+	return err
+}
+
+// GetBudgetHistory - підсумки закритих періодів бюджету, від найновішого
+func GetBudgetHistory(budget models.Budget) ([]models.BudgetSnapshot, error) {
+	if _, err := RollOverBudget(budget, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return repo.GetBudgetSnapshots(budget.BudgetID)
+}
+
+// GetBudgetStatuses - стан усіх бюджетів користувача на поточну дату
+func GetBudgetStatuses(userID int) ([]models.BudgetStatus, error) {
+	budgets, err := repo.GetBudgetsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	statuses := []models.BudgetStatus{}
+	now := time.Now().UTC()
+	for _, budget := range budgets {
+		// Один зламаний бюджет не має ховати стан решти
+		if budget, err = RollOverBudget(budget, now); err != nil {
+			log.Printf("Skipping status of budget %d: %v", budget.BudgetID, err)
+			continue
+		}
+		status, err := GetBudgetStatus(budget, now)
+		if err != nil {
+			log.Printf("Skipping status of budget %d: %v", budget.BudgetID, err)
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// rolloverMutex - не дає двом запускам закривати періоди одночасно
+var rolloverMutex sync.Mutex
+
+// RollOverBudgets - закриває завершені періоди бюджетів усіх користувачів
+func RollOverBudgets(today time.Time) error {
+	rolloverMutex.Lock()
+	defer rolloverMutex.Unlock()
+
+	budgets, err := repo.GetAllBudgets()
+	if err != nil {
+		return err
+	}
+	for _, budget := range budgets {
+		if _, err := RollOverBudget(budget, today); err != nil {
+			log.Printf("Error rolling over budget %d: %v", budget.BudgetID, err)
+		}
+	}
+	return nil
+}
+
+// StartBudgetRollover - запускає фонове закриття періодів бюджетів з заданим інтервалом
+func StartBudgetRollover(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := RollOverBudgets(time.Now().UTC()); err != nil {
+				log.Printf("Budget rollover error: %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
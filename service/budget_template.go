@@ -0,0 +1,281 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"cashWise/models"
+	"cashWise/repo"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// builtInBudgetItem - бюджет вбудованого шаблону. Категорії задано назвами обома мовами і
+// зіставляються з категоріями користувача; без назв бюджет охоплює всі витрати.
+type builtInBudgetItem struct {
+	name       map[string]string
+	percent    float64
+	categories []string
+}
+
+// builtInBudgetTemplate - вбудований шаблон, назви та опис за мовою
+type builtInBudgetTemplate struct {
+	key         string
+	name        map[string]string
+	description map[string]string
+	items       []builtInBudgetItem
+}
+
+// builtInBudgetTemplates - шаблони, доступні всім користувачам. Назви категорій відповідають
+// стандартному набору defaultCategories.
+var builtInBudgetTemplates = []builtInBudgetTemplate{
+	{
+		key:  "50-30-20",
+		name: map[string]string{"uk": "Правило 50/30/20", "en": "50/30/20 rule"},
+		description: map[string]string{
+			"uk": "50% доходу на потреби, 30% на бажання, решта 20% - заощадження",
+			"en": "50% of income for needs, 30% for wants, the remaining 20% goes to savings",
+		},
+		items: []builtInBudgetItem{
+			{map[string]string{"uk": "Потреби", "en": "Needs"}, 50, []string{
+				"Житло", "Housing", "Продукти", "Groceries", "Транспорт", "Transport",
+				"Здоров'я", "Health", "Зв'язок та інтернет", "Phone and internet",
+			}},
+			{map[string]string{"uk": "Бажання", "en": "Wants"}, 30, []string{
+				"Кафе та ресторани", "Restaurants", "Розваги", "Entertainment",
+				"Одяг", "Clothing", "Подарунки", "Gifts", "Освіта", "Education",
+			}},
+		},
+	},
+	{
+		key:  "80-20",
+		name: map[string]string{"uk": "Правило 80/20", "en": "80/20 rule"},
+		description: map[string]string{
+			"uk": "Усі витрати в межах 80% доходу, 20% - заощадження",
+			"en": "All spending within 80% of income, 20% goes to savings",
+		},
+		items: []builtInBudgetItem{
+			{map[string]string{"uk": "Витрати", "en": "Spending"}, 80, nil},
+		},
+	},
+}
+
+// GetBudgetTemplates - вбудовані шаблони з категоріями, зіставленими з категоріями користувача,
+// і збережені шаблони користувача. Бюджет вбудованого шаблону, для якого не знайшлося жодної
+// категорії, пропускається, щоб не перетворитися на бюджет на всі витрати.
+func GetBudgetTemplates(userID int, lang string) ([]models.BudgetTemplate, error) {
+	categories, err := repo.GetCategories(userID)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]int, len(categories))
+	for _, category := range categories {
+		if !category.Archived {
+			byName[strings.ToLower(category.Name)] = category.CategoryID
+		}
+	}
+
+	templates := []models.BudgetTemplate{}
+	for _, builtIn := range builtInBudgetTemplates {
+		template := models.BudgetTemplate{
+			Key:         builtIn.key,
+			Name:        localized(builtIn.name, lang),
+			Description: localized(builtIn.description, lang),
+			Items:       []models.BudgetTemplateItem{},
+		}
+		for _, item := range builtIn.items {
+			var categoryIDs []int
+			for _, name := range item.categories {
+				if categoryID, ok := byName[strings.ToLower(name)]; ok && !containsID(categoryIDs, categoryID) {
+					categoryIDs = append(categoryIDs, categoryID)
+				}
+			}
+			if len(item.categories) > 0 && len(categoryIDs) == 0 {
+				continue
+			}
+			template.Items = append(template.Items, models.BudgetTemplateItem{
+				Name:                 localized(item.name, lang),
+				Percent:              item.percent,
+				CategoryIDs:          categoryIDs,
+				IncludeSubcategories: len(categoryIDs) > 0,
+			})
+		}
+		templates = append(templates, template)
+	}
+
+	saved, err := repo.GetBudgetTemplates(userID)
+	if err != nil {
+		return nil, err
+	}
+	return append(templates, saved...), nil
+}
+
+// localized - текст мовою lang, а якщо його немає - українською
+func localized(texts map[string]string, lang string) string {
+	if text, ok := texts[lang]; ok {
+		return text
+	}
+	return texts["uk"]
+}
+
+// ValidateBudgetTemplate - перевіряє назву, частки (разом не більше 100%) і категорії шаблону
+func ValidateBudgetTemplate(template models.BudgetTemplate) error {
+	if strings.TrimSpace(template.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(template.Items) == 0 {
+		return fmt.Errorf("template must contain at least one budget")
+	}
+
+	hierarchy, err := loadCategoryHierarchy(template.UserID)
+	if err != nil {
+		return err
+	}
+	total := 0.0
+	for _, item := range template.Items {
+		if strings.TrimSpace(item.Name) == "" {
+			return fmt.Errorf("every budget in the template needs a name")
+		}
+		if item.Percent <= 0 {
+			return fmt.Errorf("percent of %q must be positive", item.Name)
+		}
+		total += item.Percent
+		for _, categoryID := range item.CategoryIDs {
+			if _, ok := hierarchy.byID[categoryID]; !ok {
+				return fmt.Errorf("category %d not found", categoryID)
+			}
+		}
+	}
+	if total > 100 {
+		return fmt.Errorf("template shares add up to %g%%, more than 100%%", total)
+	}
+	return nil
+}
+
+// findBudgetTemplate - збережений шаблон за templateID або вбудований за key
+func findBudgetTemplate(userID int, templateID int, key string, lang string) (models.BudgetTemplate, error) {
+	if templateID != 0 {
+		return repo.GetBudgetTemplateByID(userID, templateID)
+	}
+	templates, err := GetBudgetTemplates(userID, lang)
+	if err != nil {
+		return models.BudgetTemplate{}, err
+	}
+	for _, template := range templates {
+		if template.Key != "" && template.Key == key {
+			return template, nil
+		}
+	}
+	return models.BudgetTemplate{}, fmt.Errorf("budget template %q not found", key)
+}
+
+// ApplyBudgetTemplate - створює бюджети шаблону з лімітами як частками цільового доходу income.
+// base задає спільні параметри нових бюджетів: період, дату початку, перенесення та пороги.
+// Усі бюджети перевіряються до створення першого з них, а якщо створення все ж зірветься,
+// уже створені бюджети видаляються.
+func ApplyBudgetTemplate(userID int, templateID int, key string, lang string, income float64, base models.Budget) ([]models.Budget, error) {
+	if income <= 0 {
+		return nil, fmt.Errorf("income must be positive")
+	}
+	template, err := findBudgetTemplate(userID, templateID, key, lang)
+	if err != nil {
+		return nil, err
+	}
+	if len(template.Items) == 0 {
+		return nil, fmt.Errorf("template %q has no budgets matching your categories", template.Name)
+	}
+	if base.Period == "" {
+		base.Period = "monthly"
+	}
+
+	budgets := make([]models.Budget, 0, len(template.Items))
+	for _, item := range template.Items {
+		budget := base
+		budget.UserID = userID
+		budget.Name = item.Name
+		budget.Limit = math.Round(income*item.Percent) / 100
+		budget.CategoryIDs = item.CategoryIDs
+		budget.IncludeSubcategories = item.IncludeSubcategories
+		if err := ValidateBudget(budget); err != nil {
+			return nil, fmt.Errorf("budget %q: %v", item.Name, err)
+		}
+		budgets = append(budgets, budget)
+	}
+
+	created := []models.Budget{}
+	for _, budget := range budgets {
+		saved, err := CreateBudget(budget)
+		if err != nil {
+			// Шаблон застосовується повністю або ніяк: прибираємо вже створені бюджети
+			for _, budget := range created {
+				if err := repo.DeleteBudget(budget.BudgetID); err != nil {
+					log.Printf("Error rolling back budget %d of template %q: %v", budget.BudgetID, template.Name, err)
+				}
+			}
+			return nil, fmt.Errorf("budget %q: %v", budget.Name, err)
+		}
+		created = append(created, saved)
+	}
+	return created, nil
+}
+
+// RestoreLastMonthLimits - повертає вказаним бюджетам ліміти минулого місяця: для кожного бюджету
+// береться підсумок закритого періоду, що охоплював минулий місяць, і бюджет отримує тодішній ліміт.
+// Решта бюджетів не змінюється, тож свідомо змінені ліміти лишаються, якщо їх не вказано. Бюджети
+// без такого періоду (період триває ще з минулого місяця або бюджет створено цього місяця)
+// повертаються в skipped.
+func RestoreLastMonthLimits(userID int, budgetIDs []int) ([]models.Budget, map[int]string, error) {
+	if len(budgetIDs) == 0 {
+		return nil, nil, fmt.Errorf("budgetIDs are required")
+	}
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC)
+	lastMonth := DateRange{Start: start, End: start.AddDate(0, 1, -1)}
+
+	// Усі бюджети перевіряються до зміни першого з них
+	budgets := make([]models.Budget, 0, len(budgetIDs))
+	for _, budgetID := range budgetIDs {
+		budget, err := repo.GetBudgetByID(budgetID)
+		if err != nil || budget.UserID != userID {
+			return nil, nil, fmt.Errorf("budget %d: %w", budgetID, mongo.ErrNoDocuments)
+		}
+		budgets = append(budgets, budget)
+	}
+
+	restored := []models.Budget{}
+	skipped := map[int]string{}
+	for _, budget := range budgets {
+		// Спершу закриваємо завершені періоди, щоб підсумок минулого місяця вже був в історії
+		budget, err := RollOverBudget(budget, now)
+		if err != nil {
+			return restored, skipped, fmt.Errorf("budget %d: %v", budget.BudgetID, err)
+		}
+		snapshots, err := repo.GetBudgetSnapshots(budget.BudgetID)
+		if err != nil {
+			return restored, skipped, err
+		}
+		limit := 0.0
+		for _, snapshot := range snapshots {
+			if snapshot.From > lastMonth.EndString() || snapshot.To < lastMonth.StartString() {
+				continue
+			}
+			limit = snapshot.Limit
+			break // Найновіший період, що охоплював минулий місяць
+		}
+		if limit <= 0 {
+			skipped[budget.BudgetID] = "no closed period covers last month"
+			continue
+		}
+		if limit != budget.Limit {
+			if err := repo.EditBudget(models.Budget{BudgetID: budget.BudgetID, Limit: limit}); err != nil {
+				return restored, skipped, err
+			}
+			budget.Limit = limit
+		}
+		restored = append(restored, budget)
+	}
+	return restored, skipped, nil
+}
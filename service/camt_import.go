@@ -0,0 +1,279 @@
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"cashWise/models"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Структури camt.053 містять лише потрібні нам елементи; простори імен версій (.001.02 - .001.08) ігноруються
+
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	ID       string        `xml:"Id"`
+	Account  camtAccount   `xml:"Acct"`
+	Balances []camtBalance `xml:"Bal"`
+	Entries  []camtEntry   `xml:"Ntry"`
+}
+
+type camtAccount struct {
+	IBAN     string `xml:"Id>IBAN"`
+	Other    string `xml:"Id>Othr>Id"`
+	Currency string `xml:"Ccy"`
+	Owner    string `xml:"Ownr>Nm"`
+}
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camtBalance struct {
+	Type      string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount    camtAmount `xml:"Amt"`
+	Indicator string     `xml:"CdtDbtInd"`
+	Date      camtDate   `xml:"Dt"`
+}
+
+type camtParty struct {
+	Name      string `xml:"Nm"`
+	PartyName string `xml:"Pty>Nm"` // camt.053.001.08 і новіші
+}
+
+// camtStatus - до версії .001.08 статус є текстом елемента Sts, далі - вкладеним кодом Cd
+type camtStatus struct {
+	Text string `xml:",chardata"`
+	Code string `xml:"Cd"`
+}
+
+type camtTransactionDetails struct {
+	Reference      string    `xml:"Refs>AcctSvcrRef"`
+	Debtor         camtParty `xml:"RltdPties>Dbtr"`
+	DebtorIBAN     string    `xml:"RltdPties>DbtrAcct>Id>IBAN"`
+	Creditor       camtParty `xml:"RltdPties>Cdtr"`
+	CreditorIBAN   string    `xml:"RltdPties>CdtrAcct>Id>IBAN"`
+	Unstructured   []string  `xml:"RmtInf>Ustrd"`
+	AdditionalInfo string    `xml:"AddtlTxInf"`
+}
+
+type camtEntry struct {
+	Reference      string     `xml:"NtryRef"`
+	Amount         camtAmount `xml:"Amt"`
+	Indicator      string     `xml:"CdtDbtInd"`
+	Status         camtStatus `xml:"Sts"`
+	BookingDate    camtDate   `xml:"BookgDt"`
+	ValueDate      camtDate   `xml:"ValDt"`
+	BankReference  string     `xml:"AcctSvcrRef"`
+	AdditionalInfo string     `xml:"AddtlNtryInf"`
+
+	Details []camtTransactionDetails `xml:"NtryDtls>TxDtls"`
+}
+
+// xmlCharsetReader - підтримує однобайтові кодування, які трапляються в XML-виписках окрім UTF-8
+func xmlCharsetReader(label string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(label) {
+	case "iso-8859-1", "latin1":
+		return charmap.ISO8859_1.NewDecoder().Reader(input), nil
+	case "windows-1252", "cp1252":
+		return charmap.Windows1252.NewDecoder().Reader(input), nil
+	case "windows-1251", "cp1251":
+		return charmap.Windows1251.NewDecoder().Reader(input), nil
+	}
+	return nil, fmt.Errorf("unsupported encoding: %s", label)
+}
+
+// value - дата з елемента Dt або DtTm у форматі YYYY-MM-DD
+func (d camtDate) value() (string, error) {
+	value := strings.TrimSpace(d.Date)
+	if value == "" {
+		value = strings.TrimSpace(d.DateTime)
+	}
+	if len(value) < 10 {
+		return "", fmt.Errorf("invalid date %q", value)
+	}
+	date, err := time.Parse(dateLayout, value[:10])
+	if err != nil {
+		return "", fmt.Errorf("invalid date %q", value)
+	}
+	return date.Format(dateLayout), nil
+}
+
+// name - ім'я сторони незалежно від версії схеми
+func (p camtParty) name() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.PartyName
+}
+
+// signedCamtAmount - сума зі знаком за індикатором CRDT/DBIT
+func signedCamtAmount(amount camtAmount, indicator string) (float64, error) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(amount.Value), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", amount.Value)
+	}
+	switch strings.ToUpper(strings.TrimSpace(indicator)) {
+	case "CRDT":
+		return value, nil
+	case "DBIT":
+		return -value, nil
+	}
+	return 0, fmt.Errorf("invalid credit/debit indicator %q", indicator)
+}
+
+// ParseCAMT053Statement - розбирає виписку ISO 20022 camt.053. Кожна виписка у файлі звіряється:
+// початковий залишок (OPBD) разом з проведеними операціями має дати кінцевий (CLBD).
+func ParseCAMT053Statement(data []byte) (models.ImportPreview, error) {
+	preview := models.ImportPreview{Format: "camt053", Encoding: "utf-8", Rows: []models.ImportRow{}}
+
+	var document camtDocument
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = xmlCharsetReader
+	if err := decoder.Decode(&document); err != nil {
+		return preview, fmt.Errorf("invalid camt.053 XML: %v", err)
+	}
+	if len(document.Statements) == 0 {
+		return preview, errors.New("not a camt.053 file: no BkToCstmrStmt/Stmt elements")
+	}
+
+	for _, statement := range document.Statements {
+		account := statement.Account.IBAN
+		if account == "" {
+			account = statement.Account.Other
+		}
+		if preview.Account == nil {
+			preview.Account = &models.StatementAccount{
+				AccountID: account,
+				Name:      statement.Account.Owner,
+				Currency:  statement.Account.Currency,
+			}
+		}
+
+		balance := models.StatementBalance{Account: account, Currency: statement.Account.Currency}
+		var opening, closing *camtBalance
+		for i := range statement.Balances {
+			switch statement.Balances[i].Type {
+			case "OPBD", "PRCD": // Початковий залишок або кінцевий залишок попередньої виписки
+				if opening == nil || statement.Balances[i].Type == "OPBD" {
+					opening = &statement.Balances[i]
+				}
+			case "CLBD":
+				closing = &statement.Balances[i]
+			}
+		}
+		if opening == nil || closing == nil {
+			return preview, fmt.Errorf("statement %s: opening (OPBD) and closing (CLBD) balances are required", statement.ID)
+		}
+
+		var err error
+		if balance.OpeningBalance, err = signedCamtAmount(opening.Amount, opening.Indicator); err != nil {
+			return preview, fmt.Errorf("statement %s: opening balance: %v", statement.ID, err)
+		}
+		if balance.ClosingBalance, err = signedCamtAmount(closing.Amount, closing.Indicator); err != nil {
+			return preview, fmt.Errorf("statement %s: closing balance: %v", statement.ID, err)
+		}
+		if balance.From, err = opening.Date.value(); err != nil {
+			return preview, fmt.Errorf("statement %s: opening balance: %v", statement.ID, err)
+		}
+		if balance.To, err = closing.Date.value(); err != nil {
+			return preview, fmt.Errorf("statement %s: closing balance: %v", statement.ID, err)
+		}
+		if balance.Currency == "" {
+			balance.Currency = closing.Amount.Currency
+		}
+
+		for _, entry := range statement.Entries {
+			status := entry.Status.Code
+			if status == "" {
+				status = strings.TrimSpace(entry.Status.Text)
+			}
+			if status != "" && status != "BOOK" {
+				continue // Заблоковані та очікувані операції не входять до залишку
+			}
+
+			row := camtEntryToRow(entry, len(preview.Rows)+1)
+			row.Account = account
+			if row.Currency == "" {
+				row.Currency = balance.Currency
+			}
+			if row.Error != "" {
+				return preview, fmt.Errorf("statement %s, entry %d: %s", statement.ID, row.Line, row.Error)
+			}
+			balance.EntriesTotal += row.Amount
+			preview.Rows = append(preview.Rows, row)
+		}
+
+		balance.EntriesTotal = math.Round(balance.EntriesTotal*100) / 100
+		if err := checkStatementBalance(balance); err != nil {
+			return preview, err
+		}
+		preview.Statements = append(preview.Statements, balance)
+	}
+	return preview, nil
+}
+
+// camtEntryToRow - перетворює проведену операцію на рядок імпорту; контрагент - платник для зарахувань і отримувач для списань
+func camtEntryToRow(entry camtEntry, line int) models.ImportRow {
+	row := models.ImportRow{Line: line, Currency: entry.Amount.Currency, BankReference: entry.BankReference}
+
+	var err error
+	if row.Amount, err = signedCamtAmount(entry.Amount, entry.Indicator); err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	if row.Date, err = entry.BookingDate.value(); err != nil {
+		row.Error = "booking date: " + err.Error()
+		return row
+	}
+	if entry.ValueDate.Date != "" || entry.ValueDate.DateTime != "" {
+		row.ValueDate, _ = entry.ValueDate.value()
+	}
+
+	var descriptions []string
+	if len(entry.Details) > 0 {
+		details := entry.Details[0]
+		if row.Amount > 0 {
+			row.Counterparty, row.CounterpartyIBAN = details.Debtor.name(), details.DebtorIBAN
+		} else {
+			row.Counterparty, row.CounterpartyIBAN = details.Creditor.name(), details.CreditorIBAN
+		}
+		if row.BankReference == "" {
+			row.BankReference = details.Reference
+		}
+		descriptions = append(descriptions, strings.Join(details.Unstructured, " "), details.AdditionalInfo)
+	}
+	descriptions = append(descriptions, entry.AdditionalInfo)
+
+	row.Description = row.Counterparty
+	for _, description := range descriptions {
+		if description = strings.TrimSpace(description); description != "" {
+			row.Description = strings.Join(nonEmpty(row.Counterparty, description), " - ")
+			break
+		}
+	}
+
+	// Посилання банку унікальне в межах рахунку, тому придатне для пошуку дублікатів
+	row.ExternalID = row.BankReference
+	if row.ExternalID == "" {
+		row.ExternalID = entry.Reference
+	}
+	return row
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"cashWise/models"
+	"cashWise/repo"
+)
+
+// categoryHierarchy - категорії користувача з перевіреними зв'язками "батько - діти"
+type categoryHierarchy struct {
+	byID     map[int]models.Category
+	parent   map[int]int   // Категорія -> батьківська; 0 - верхній рівень
+	children map[int][]int // Категорія -> підкатегорії за ID; ключ 0 - категорії верхнього рівня
+}
+
+// newCategoryHierarchy - будує ієрархію. Категорія, чий батько вже видалений або яка через
+// пошкоджені дані потрапила в цикл, вважається категорією верхнього рівня.
+func newCategoryHierarchy(categories []models.Category) categoryHierarchy {
+	hierarchy := categoryHierarchy{
+		byID:     make(map[int]models.Category, len(categories)),
+		parent:   make(map[int]int, len(categories)),
+		children: map[int][]int{},
+	}
+	for _, category := range categories {
+		hierarchy.byID[category.CategoryID] = category
+	}
+
+	sort.Slice(categories, func(i, j int) bool { return categories[i].CategoryID < categories[j].CategoryID })
+	for _, category := range categories {
+		parentID := category.ParentID
+		if _, ok := hierarchy.byID[parentID]; !ok || hierarchy.reaches(parentID, category.CategoryID) {
+			parentID = 0
+		}
+		hierarchy.parent[category.CategoryID] = parentID
+		hierarchy.children[parentID] = append(hierarchy.children[parentID], category.CategoryID)
+	}
+	return hierarchy
+}
+
+// reaches - чи є ancestorID серед предків categoryID (або нею самою) за сирими parentID
+func (h categoryHierarchy) reaches(categoryID int, ancestorID int) bool {
+	visited := map[int]bool{}
+	for categoryID != 0 && !visited[categoryID] {
+		if categoryID == ancestorID {
+			return true
+		}
+		visited[categoryID] = true
+		categoryID = h.byID[categoryID].ParentID
+	}
+	return false
+}
+
+// subtree - ID категорії та всіх її підкатегорій
+func (h categoryHierarchy) subtree(categoryID int) []int {
+	ids := []int{categoryID}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, h.children[ids[i]]...)
+	}
+	return ids
+}
+
+// node - вузол дерева з підкатегоріями, відсортованими за назвою
+func (h categoryHierarchy) node(categoryID int) models.CategoryNode {
+	node := models.CategoryNode{Category: h.byID[categoryID], Children: []models.CategoryNode{}}
+	for _, childID := range h.children[categoryID] {
+		node.Children = append(node.Children, h.node(childID))
+	}
+	sort.Slice(node.Children, func(i, j int) bool { return node.Children[i].Name < node.Children[j].Name })
+	return node
+}
+
+func loadCategoryHierarchy(userID int) (categoryHierarchy, error) {
+	categories, err := repo.GetCategories(userID)
+	if err != nil {
+		return categoryHierarchy{}, err
+	}
+	return newCategoryHierarchy(categories), nil
+}
+
+// GetCategoryTree - категорії користувача деревом: верхній рівень і вкладені підкатегорії
+func GetCategoryTree(userID int) ([]models.CategoryNode, error) {
+	hierarchy, err := loadCategoryHierarchy(userID)
+	if err != nil {
+		return nil, err
+	}
+	return hierarchy.node(0).Children, nil
+}
+
+// CategorySubtree - ID категорії разом з усіма її підкатегоріями, для звітів і бюджетів за гілкою
+func CategorySubtree(userID int, categoryID int) ([]int, error) {
+	hierarchy, err := loadCategoryHierarchy(userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := hierarchy.byID[categoryID]; !ok {
+		return []int{categoryID}, nil
+	}
+	return hierarchy.subtree(categoryID), nil
+}
+
+// ValidateCategoryParent - перевіряє, що батьківська категорія існує і не лежить у гілці самої
+// категорії, інакше дерево замкнеться в цикл. Для нової категорії categoryID дорівнює 0.
+func ValidateCategoryParent(userID int, categoryID int, parentID int) error {
+	if parentID == 0 {
+		return nil
+	}
+	if parentID == categoryID {
+		return fmt.Errorf("category cannot be its own parent")
+	}
+	hierarchy, err := loadCategoryHierarchy(userID)
+	if err != nil {
+		return err
+	}
+	if _, ok := hierarchy.byID[parentID]; !ok {
+		return fmt.Errorf("parent category %d not found", parentID)
+	}
+	if categoryID != 0 && hierarchy.reaches(parentID, categoryID) {
+		return fmt.Errorf("category %d cannot be moved under its own subcategory %d", categoryID, parentID)
+	}
+	return nil
+}
+
+// MoveCategory - переносить категорію з усією гілкою під іншу категорію або на верхній рівень
+func MoveCategory(userID int, categoryID int, parentID int) error {
+	if err := ValidateCategoryParent(userID, categoryID, parentID); err != nil {
+		return err
+	}
+	return repo.SetCategoryParent(userID, categoryID, parentID)
+}
+
+// GetCategoryRollup - суми за категоріями деревом: кожна батьківська категорія показує і власні
+// транзакції, і підсумок разом з підкатегоріями. Гілки без транзакцій не показуються; суми
+// видалених категорій лишаються окремими вузлами верхнього рівня, щоб загальний підсумок не змінився.
+func GetCategoryRollup(userID int, transactionTypes []string, from, to string) ([]models.CategoryRollup, error) {
+	hierarchy, err := loadCategoryHierarchy(userID)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := repo.GetCategoryTotals(userID, transactionTypes, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	own := make(map[int]models.CategoryTotal, len(totals))
+	for _, total := range totals {
+		own[total.CategoryID] = total
+		if _, ok := hierarchy.byID[total.CategoryID]; !ok && total.CategoryID != 0 {
+			hierarchy.children[0] = append(hierarchy.children[0], total.CategoryID)
+		}
+	}
+
+	var build func(categoryID int) models.CategoryRollup
+	build = func(categoryID int) models.CategoryRollup {
+		total := own[categoryID]
+		rollup := models.CategoryRollup{
+			CategoryID:  categoryID,
+			ParentID:    hierarchy.parent[categoryID],
+			Name:        hierarchy.byID[categoryID].Name,
+			Total:       total.Total,
+			Count:       total.Count,
+			RollupTotal: total.Total,
+			RollupCount: total.Count,
+			Children:    []models.CategoryRollup{},
+		}
+		for _, childID := range hierarchy.children[categoryID] {
+			child := build(childID)
+			if child.RollupCount == 0 {
+				continue
+			}
+			rollup.RollupTotal += child.RollupTotal
+			rollup.RollupCount += child.RollupCount
+			rollup.Children = append(rollup.Children, child)
+		}
+		sort.Slice(rollup.Children, func(i, j int) bool {
+			return rollup.Children[i].RollupTotal > rollup.Children[j].RollupTotal
+		})
+		return rollup
+	}
+	roots := build(0).Children
+	// Власні суми кореня - транзакції без категорії; показуємо їх окремим вузлом у кінці
+	if uncategorized := own[0]; uncategorized.Count > 0 {
+		roots = append(roots, models.CategoryRollup{
+			Total:       uncategorized.Total,
+			Count:       uncategorized.Count,
+			RollupTotal: uncategorized.Total,
+			RollupCount: uncategorized.Count,
+			Children:    []models.CategoryRollup{},
+		})
+	}
+	return roots, nil
+}
+
+// CategoryInUse - чи посилається на категорію хоч щось
+func CategoryInUse(usage models.CategoryUsage) bool {
+	for _, count := range usage {
+		if count > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ReplaceCategory - видаляє категорію, переводячи її транзакції, шаблони, правила та інші посилання
+// на replacementID. Підкатегорії переходять до батьківської категорії видаленої.
+func ReplaceCategory(userID int, categoryID int, replacementID int) (models.CategoryUsage, error) {
+	if replacementID == categoryID {
+		return nil, fmt.Errorf("replacement must differ from the deleted category")
+	}
+	replacement, err := repo.GetCategoryByID(userID, replacementID)
+	if err != nil {
+		return nil, fmt.Errorf("replacement category %d not found", replacementID)
+	}
+	if replacement.Archived {
+		return nil, fmt.Errorf("replacement category %d is archived", replacementID)
+	}
+
+	usage, err := repo.ReplaceCategory(userID, categoryID, replacementID)
+	if err != nil {
+		return nil, err
+	}
+	// Категорії транзакцій змінено масово, тож модель підказок простіше навчити заново
+	ResetCategoryModel(userID)
+	return usage, nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"fmt"
+
+	"cashWise/models"
+	"cashWise/repo"
+)
+
+// categoryTemplate - категорія стандартного набору з підкатегоріями
+type categoryTemplate struct {
+	name     string
+	icon     string
+	children []categoryTemplate
+}
+
+// defaultCategories - стандартний набір категорій нового користувача за мовою
+var defaultCategories = map[string][]categoryTemplate{
+	"uk": {
+		{"Їжа", "food.png", []categoryTemplate{{"Продукти", "groceries.png", nil}, {"Кафе та ресторани", "restaurant.png", nil}}},
+		{"Транспорт", "transport.png", []categoryTemplate{{"Громадський транспорт", "bus.png", nil}, {"Таксі", "taxi.png", nil}, {"Пальне", "fuel.png", nil}}},
+		{"Житло", "home.png", []categoryTemplate{{"Оренда", "rent.png", nil}, {"Комунальні послуги", "utilities.png", nil}}},
+		{"Здоров'я", "health.png", []categoryTemplate{{"Аптека", "pharmacy.png", nil}, {"Лікарі", "doctor.png", nil}}},
+		{"Зв'язок та інтернет", "phone.png", nil},
+		{"Одяг", "clothes.png", nil},
+		{"Розваги", "entertainment.png", nil},
+		{"Освіта", "education.png", nil},
+		{"Подарунки", "gift.png", nil},
+		{"Інше", "default-icon.png", nil},
+		{"Доходи", "income.png", []categoryTemplate{{"Зарплата", "salary.png", nil}, {"Підробіток", "freelance.png", nil}, {"Інші доходи", "income-other.png", nil}}},
+	},
+	"en": {
+		{"Food", "food.png", []categoryTemplate{{"Groceries", "groceries.png", nil}, {"Restaurants", "restaurant.png", nil}}},
+		{"Transport", "transport.png", []categoryTemplate{{"Public transport", "bus.png", nil}, {"Taxi", "taxi.png", nil}, {"Fuel", "fuel.png", nil}}},
+		{"Housing", "home.png", []categoryTemplate{{"Rent", "rent.png", nil}, {"Utilities", "utilities.png", nil}}},
+		{"Health", "health.png", []categoryTemplate{{"Pharmacy", "pharmacy.png", nil}, {"Doctors", "doctor.png", nil}}},
+		{"Phone and internet", "phone.png", nil},
+		{"Clothing", "clothes.png", nil},
+		{"Entertainment", "entertainment.png", nil},
+		{"Education", "education.png", nil},
+		{"Gifts", "gift.png", nil},
+		{"Other", "default-icon.png", nil},
+		{"Income", "income.png", []categoryTemplate{{"Salary", "salary.png", nil}, {"Side jobs", "freelance.png", nil}, {"Other income", "income-other.png", nil}}},
+	},
+}
+
+// SeedDefaultCategories - створює новому користувачу стандартний набір категорій мовою lang.
+// Користувача, який уже має категорії, не змінює.
+func SeedDefaultCategories(userID int, lang string) error {
+	existing, err := repo.GetCategories(userID)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return nil
+	}
+
+	templates, ok := defaultCategories[lang]
+	if !ok {
+		templates = defaultCategories["uk"]
+	}
+	return addCategoryTemplates(userID, 0, templates)
+}
+
+// addCategoryTemplates - додає категорії шаблону під parentID, а потім їхні підкатегорії
+func addCategoryTemplates(userID int, parentID int, templates []categoryTemplate) error {
+	for _, template := range templates {
+		category, err := repo.AddCategory(models.Category{
+			UserID:   userID,
+			Name:     template.name,
+			Icon:     template.icon,
+			ParentID: parentID,
+		})
+		if err != nil {
+			return fmt.Errorf("error creating category %q: %v", template.name, err)
+		}
+		if err := addCategoryTemplates(userID, category.CategoryID, template.children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
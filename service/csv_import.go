@@ -0,0 +1,341 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"cashWise/models"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// csvDelimiters - роздільники, серед яких шукаємо під час автовизначення
+var csvDelimiters = []rune{',', ';', '\t', '|'}
+
+// statementDateFormats - формати дат, які трапляються у банківських виписках
+var statementDateFormats = []string{
+	"2006-01-02",
+	"02.01.2006",
+	"02/01/2006",
+	"01/02/2006",
+	"2006/01/02",
+	"02-01-2006",
+	"02.01.06",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"02.01.2006 15:04:05",
+	"02.01.2006 15:04",
+	"02/01/2006 15:04",
+}
+
+// Ключові слова заголовків для автоматичного зіставлення колонок (англ., укр., рос.)
+var (
+	dateHeaderWords        = []string{"date", "дата"}
+	amountHeaderWords      = []string{"amount", "sum", "сума", "сумма"}
+	debitHeaderWords       = []string{"debit", "дебет", "списання", "расход", "витрата"}
+	creditHeaderWords      = []string{"credit", "кредит", "зарахування", "приход", "надходження"}
+	descriptionHeaderWords = []string{"description", "details", "memo", "опис", "призначення", "назначение", "описание", "деталі"}
+)
+
+// decodeStatement - перетворює вміст файлу на UTF-8, визначаючи кодування, якщо воно не задане
+func decodeStatement(data []byte, encoding string) (string, string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // UTF-8 BOM
+
+	switch strings.ToLower(encoding) {
+	case "":
+		if utf8.Valid(data) {
+			return string(data), "utf-8", nil
+		}
+		// Найпоширеніше однобайтове кодування українських та російських банків
+		decoded, err := charmap.Windows1251.NewDecoder().Bytes(data)
+		if err != nil {
+			return "", "", fmt.Errorf("unable to decode file: %v", err)
+		}
+		return string(decoded), "windows-1251", nil
+	case "utf-8", "utf8":
+		return string(data), "utf-8", nil
+	case "windows-1251", "cp1251":
+		decoded, err := charmap.Windows1251.NewDecoder().Bytes(data)
+		if err != nil {
+			return "", "", fmt.Errorf("unable to decode file: %v", err)
+		}
+		return string(decoded), "windows-1251", nil
+	}
+	return "", "", fmt.Errorf("unsupported encoding: %s", encoding)
+}
+
+// readCSV - читає записи з заданим роздільником
+func readCSV(text string, delimiter rune) ([][]string, error) {
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	return reader.ReadAll()
+}
+
+// detectDelimiter - вибирає роздільник, що дає найбільшу сталу кількість колонок у перших рядках
+func detectDelimiter(text string) rune {
+	lines := strings.SplitN(text, "\n", 12)
+	if len(lines) > 10 {
+		lines = lines[:10]
+	}
+	sample := strings.Join(lines, "\n")
+
+	best, bestScore := ',', 0
+	for _, delimiter := range csvDelimiters {
+		records, err := readCSV(sample, delimiter)
+		if err != nil || len(records) == 0 {
+			continue
+		}
+		// Рахуємо, скільки рядків мають найчастішу кількість колонок
+		counts := map[int]int{}
+		for _, record := range records {
+			counts[len(record)]++
+		}
+		for columns, rows := range counts {
+			if columns > 1 && rows*columns > bestScore {
+				best, bestScore = delimiter, rows*columns
+			}
+		}
+	}
+	return best
+}
+
+// findColumn - шукає колонку, заголовок якої містить одне з ключових слів
+func findColumn(header []string, words []string) *int {
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		for _, word := range words {
+			if strings.Contains(name, word) {
+				column := i
+				return &column
+			}
+		}
+	}
+	return nil
+}
+
+// detectDateFormat - вибирає формат, який розбирає найбільше значень (за рівності - перший у списку).
+// Рядки з датами, що не відповідають формату, потрапляють у попередній перегляд з помилкою.
+func detectDateFormat(values []string, formats []string) (string, error) {
+	best, bestCount := "", 0
+	for _, format := range formats {
+		count := 0
+		for _, value := range values {
+			if _, err := time.Parse(format, strings.TrimSpace(value)); err == nil {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = format, count
+		}
+	}
+	if bestCount == 0 {
+		return "", errors.New("unable to detect date format")
+	}
+	return best, nil
+}
+
+// detectDecimalSeparator - визначає десятковий роздільник за значеннями колонки
+func detectDecimalSeparator(values []string) string {
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		lastComma, lastDot := strings.LastIndex(value, ","), strings.LastIndex(value, ".")
+		if lastComma > lastDot && len(value)-lastComma-1 <= 2 {
+			return ","
+		}
+		if lastDot > lastComma && len(value)-lastDot-1 <= 2 {
+			return "."
+		}
+	}
+	return "."
+}
+
+// ParseAmount - розбирає суму з виписки: пробіли як роздільники тисяч, символи валют, дужки для від'ємних
+func ParseAmount(value string, decimalSeparator string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	negative := false
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		negative = true
+	}
+
+	var cleaned strings.Builder
+	for _, r := range value {
+		switch {
+		case unicode.IsDigit(r):
+			cleaned.WriteRune(r)
+		case r == '-' || r == '−':
+			negative = true
+		case string(r) == decimalSeparator:
+			cleaned.WriteRune('.')
+		}
+		// Роздільники тисяч, пробіли, валюти та інші символи пропускаємо
+	}
+
+	amount, err := strconv.ParseFloat(cleaned.String(), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+// column - безпечно бере значення колонки
+func column(record []string, index *int) string {
+	if index == nil || *index < 0 || *index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[*index])
+}
+
+// ParseCSVStatement - розбирає CSV-виписку за профілем; незаповнені поля профілю визначаються автоматично
+func ParseCSVStatement(data []byte, profile models.ImportProfile) (models.ImportPreview, error) {
+	preview := models.ImportPreview{Format: "csv", Rows: []models.ImportRow{}}
+
+	text, encoding, err := decodeStatement(data, profile.Encoding)
+	if err != nil {
+		return preview, err
+	}
+	preview.Encoding = encoding
+	profile.Encoding = encoding
+
+	// Пропускаємо службові рядки перед заголовком
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if profile.SkipRows >= len(lines) {
+		return preview, errors.New("file has no data after skipped rows")
+	}
+	text = strings.Join(lines[profile.SkipRows:], "\n")
+
+	delimiter := detectDelimiter(text)
+	if profile.Delimiter != "" {
+		delimiter, _ = utf8.DecodeRuneInString(profile.Delimiter)
+	}
+	preview.Delimiter = string(delimiter)
+	profile.Delimiter = string(delimiter)
+
+	records, err := readCSV(text, delimiter)
+	if err != nil {
+		return preview, fmt.Errorf("invalid CSV: %v", err)
+	}
+	if len(records) == 0 {
+		return preview, errors.New("file is empty")
+	}
+
+	firstDataLine := profile.SkipRows + 1
+	if !profile.NoHeader {
+		preview.Header = records[0]
+		records = records[1:]
+		firstDataLine++
+
+		// Колонки, не задані профілем, шукаємо за заголовками
+		if profile.DateColumn == nil {
+			profile.DateColumn = findColumn(preview.Header, dateHeaderWords)
+		}
+		if profile.AmountColumn == nil && profile.DebitColumn == nil && profile.CreditColumn == nil {
+			profile.AmountColumn = findColumn(preview.Header, amountHeaderWords)
+			if profile.AmountColumn == nil {
+				profile.DebitColumn = findColumn(preview.Header, debitHeaderWords)
+				profile.CreditColumn = findColumn(preview.Header, creditHeaderWords)
+			}
+		}
+		if profile.DescriptionColumn == nil {
+			profile.DescriptionColumn = findColumn(preview.Header, descriptionHeaderWords)
+		}
+	}
+	if profile.DateColumn == nil {
+		return preview, errors.New("date column not found, specify dateColumn")
+	}
+	if profile.AmountColumn == nil && profile.DebitColumn == nil && profile.CreditColumn == nil {
+		return preview, errors.New("amount column not found, specify amountColumn or debitColumn/creditColumn")
+	}
+
+	// Формат дати та десятковий роздільник визначаємо за всіма значеннями колонок
+	var dateValues, amountValues []string
+	for _, record := range records {
+		if value := column(record, profile.DateColumn); value != "" {
+			dateValues = append(dateValues, value)
+		}
+		for _, index := range []*int{profile.AmountColumn, profile.DebitColumn, profile.CreditColumn} {
+			if value := column(record, index); value != "" {
+				amountValues = append(amountValues, value)
+			}
+		}
+	}
+	if profile.DateFormat == "" {
+		if profile.DateFormat, err = detectDateFormat(dateValues, statementDateFormats); err != nil {
+			return preview, err
+		}
+	}
+	if profile.DecimalSeparator == "" {
+		profile.DecimalSeparator = detectDecimalSeparator(amountValues)
+	}
+	preview.DateFormat = profile.DateFormat
+
+	for i, record := range records {
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue // Порожній рядок
+		}
+		preview.Rows = append(preview.Rows, parseCSVRow(record, firstDataLine+i, profile))
+	}
+
+	preview.Profile = &profile
+	return preview, nil
+}
+
+// parseCSVRow - перетворює один запис CSV на рядок імпорту
+func parseCSVRow(record []string, line int, profile models.ImportProfile) models.ImportRow {
+	row := models.ImportRow{
+		Line:        line,
+		Description: column(record, profile.DescriptionColumn),
+		Account:     profile.Account,
+	}
+
+	date, err := time.Parse(profile.DateFormat, column(record, profile.DateColumn))
+	if err != nil {
+		row.Error = "invalid date"
+		return row
+	}
+	row.Date = date.Format(dateLayout)
+
+	if profile.AmountColumn != nil {
+		row.Amount, err = ParseAmount(column(record, profile.AmountColumn), profile.DecimalSeparator)
+	} else {
+		var debit, credit float64
+		debit, err = ParseAmount(column(record, profile.DebitColumn), profile.DecimalSeparator)
+		if err == nil {
+			credit, err = ParseAmount(column(record, profile.CreditColumn), profile.DecimalSeparator)
+		}
+		if debit < 0 {
+			debit = -debit
+		}
+		if credit < 0 {
+			credit = -credit
+		}
+		row.Amount = credit - debit
+	}
+	if err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	if profile.InvertSign {
+		row.Amount = -row.Amount
+	}
+	if row.Amount == 0 {
+		row.Error = "amount is zero"
+	}
+	return row
+}
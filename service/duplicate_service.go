@@ -0,0 +1,306 @@
+package service
+
+import (
+	"cashWise/models"
+	"cashWise/repo"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	duplicateWindowDays      = 3   // Найбільша різниця дат між дублікатами
+	duplicateMinSimilarity   = 0.5 // Частка спільних слів в описах
+	duplicateAmountTolerance = 0.005
+)
+
+// daysBetween - кількість днів між датами YYYY-MM-DD; для нерозбірних дат 0
+func daysBetween(a, b string) int {
+	dateA, errA := time.Parse(dateLayout, a)
+	dateB, errB := time.Parse(dateLayout, b)
+	if errA != nil || errB != nil {
+		return 0
+	}
+	days := int(dateB.Sub(dateA).Hours() / 24)
+	if days < 0 {
+		return -days
+	}
+	return days
+}
+
+// descriptionWords - множина слів нормалізованого опису
+func descriptionWords(description string) map[string]bool {
+	words := map[string]bool{}
+	for _, word := range strings.Split(normalizeDescription(description), "-") {
+		if word != "" {
+			words[word] = true
+		}
+	}
+	return words
+}
+
+// descriptionSimilarity - коефіцієнт Жаккара між словами двох описів; порожній опис схожий на будь-який
+func descriptionSimilarity(a, b string) float64 {
+	wordsA, wordsB := descriptionWords(a), descriptionWords(b)
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 1
+	}
+	common := 0
+	for word := range wordsA {
+		if wordsB[word] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(wordsA)+len(wordsB)-common)
+}
+
+// IsDuplicateCandidate - чи схожа транзакція b на a: той самий ID банку, або той самий тип і сума,
+// близькі дати та схожий опис. Частини переказів не порівнюються.
+func IsDuplicateCandidate(a, b models.Transaction) bool {
+	if a.UserID != b.UserID || a.Type == repo.TransferType || b.Type == repo.TransferType {
+		return false
+	}
+	if a.ExternalID != "" && b.ExternalID != "" {
+		return a.ExternalID == b.ExternalID && a.Account == b.Account
+	}
+	if a.Type != b.Type || math.Abs(a.Amount-b.Amount) > duplicateAmountTolerance {
+		return false
+	}
+	if a.Account != "" && b.Account != "" && a.Account != b.Account {
+		return false
+	}
+	if daysBetween(a.Date, b.Date) > duplicateWindowDays {
+		return false
+	}
+	return descriptionSimilarity(a.Description, b.Description) >= duplicateMinSimilarity
+}
+
+// duplicateSearchRange - межі дат, у яких шукати дублікати для заданих дат
+func duplicateSearchRange(dates []string) (string, string, bool) {
+	var from, to time.Time
+	for _, value := range dates {
+		date, err := time.Parse(dateLayout, value)
+		if err != nil {
+			continue
+		}
+		if from.IsZero() || date.Before(from) {
+			from = date
+		}
+		if to.IsZero() || date.After(to) {
+			to = date
+		}
+	}
+	if from.IsZero() {
+		return "", "", false
+	}
+	return from.AddDate(0, 0, -duplicateWindowDays).Format(dateLayout), to.AddDate(0, 0, duplicateWindowDays).Format(dateLayout), true
+}
+
+// findDuplicateAmong - повертає ID найстаршої схожої транзакції зі списку або 0
+func findDuplicateAmong(transaction models.Transaction, existing []models.Transaction) int {
+	found := 0
+	for _, candidate := range existing {
+		if candidate.TransactionID == transaction.TransactionID || !IsDuplicateCandidate(transaction, candidate) {
+			continue
+		}
+		// Позначка вказує на оригінал, а не на інший дублікат
+		original := candidate.TransactionID
+		if candidate.DuplicateOf != 0 {
+			original = candidate.DuplicateOf
+		}
+		if found == 0 || original < found {
+			found = original
+		}
+	}
+	return found
+}
+
+// FindDuplicateOf - шукає серед наявних транзакцій ту, дублікатом якої може бути нова
+func FindDuplicateOf(transaction models.Transaction) (int, error) {
+	from, to, ok := duplicateSearchRange([]string{transaction.Date})
+	if !ok {
+		return 0, nil
+	}
+	existing, err := repo.GetTransactionsByDateAndUserID(from, to, transaction.UserID)
+	if err != nil {
+		return 0, fmt.Errorf("error checking duplicates: %v", err)
+	}
+	return findDuplicateAmong(transaction, existing), nil
+}
+
+// flagSimilarRows - позначає рядки імпорту, схожі на наявні транзакції; такі рядки створюються з позначкою duplicateOf
+func flagSimilarRows(userID int, rows []models.ImportRow) error {
+	var dates []string
+	for _, row := range rows {
+		if row.Error == "" && !row.Duplicate {
+			dates = append(dates, row.Date)
+		}
+	}
+	from, to, ok := duplicateSearchRange(dates)
+	if !ok {
+		return nil
+	}
+
+	existing, err := repo.GetTransactionsByDateAndUserID(from, to, userID)
+	if err != nil {
+		return fmt.Errorf("error checking duplicates: %v", err)
+	}
+
+	for i := range rows {
+		row := &rows[i]
+		if row.Error != "" || row.Duplicate {
+			continue
+		}
+		row.DuplicateOf = findDuplicateAmong(rowToTransaction(userID, 0, *row), existing)
+	}
+	return nil
+}
+
+// GetDuplicateGroups - групує позначені дублікати за оригіналами.
+// З scan=true шукає дублікати серед усіх транзакцій, зокрема створених до появи перевірки.
+func GetDuplicateGroups(userID int, scan bool) ([]models.DuplicateGroup, error) {
+	if scan {
+		return scanDuplicates(userID)
+	}
+
+	flagged, err := repo.GetFlaggedDuplicates(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var originalIDs []int
+	byOriginal := map[int][]models.Transaction{}
+	for _, transaction := range flagged {
+		if _, ok := byOriginal[transaction.DuplicateOf]; !ok {
+			originalIDs = append(originalIDs, transaction.DuplicateOf)
+		}
+		byOriginal[transaction.DuplicateOf] = append(byOriginal[transaction.DuplicateOf], transaction)
+	}
+	if len(originalIDs) == 0 {
+		return []models.DuplicateGroup{}, nil
+	}
+
+	originals, err := repo.GetTransactionsByIDs(userID, originalIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []models.DuplicateGroup{}
+	for _, original := range originals {
+		groups = append(groups, models.DuplicateGroup{Original: original, Duplicates: byOriginal[original.TransactionID]})
+	}
+	return groups, nil
+}
+
+// scanDuplicates - порівнює кожну транзакцію з пізнішими в межах вікна дат
+func scanDuplicates(userID int) ([]models.DuplicateGroup, error) {
+	transactions, err := repo.GetAllTransactionsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		if transactions[i].Date != transactions[j].Date {
+			return transactions[i].Date < transactions[j].Date
+		}
+		return transactions[i].TransactionID < transactions[j].TransactionID
+	})
+
+	groups := []models.DuplicateGroup{}
+	groupOf := map[int]int{} // transactionID -> індекс групи
+	for i, transaction := range transactions {
+		if _, ok := groupOf[transaction.TransactionID]; ok {
+			continue
+		}
+		for _, later := range transactions[i+1:] {
+			if daysBetween(transaction.Date, later.Date) > duplicateWindowDays {
+				break // Далі лише пізніші дати
+			}
+			if _, ok := groupOf[later.TransactionID]; ok || !IsDuplicateCandidate(transaction, later) {
+				continue
+			}
+			index, ok := groupOf[transaction.TransactionID]
+			if !ok {
+				index = len(groups)
+				groups = append(groups, models.DuplicateGroup{Original: transaction})
+				groupOf[transaction.TransactionID] = index
+			}
+			groups[index].Duplicates = append(groups[index].Duplicates, later)
+			groupOf[later.TransactionID] = index
+		}
+	}
+	return groups, nil
+}
+
+// MergeDuplicates - залишає транзакцію keepID і видаляє mergeIDs, переносячи теги та відсутні дані банку
+func MergeDuplicates(userID int, merge models.DuplicateMerge) (models.Transaction, error) {
+	if merge.KeepID == 0 || len(merge.MergeIDs) == 0 {
+		return models.Transaction{}, errors.New("keepID and mergeIDs are required")
+	}
+	for _, id := range merge.MergeIDs {
+		if id == merge.KeepID {
+			return models.Transaction{}, errors.New("mergeIDs must not contain keepID")
+		}
+	}
+
+	transactions, err := repo.GetTransactionsByIDs(userID, append([]int{merge.KeepID}, merge.MergeIDs...))
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	if len(transactions) != len(merge.MergeIDs)+1 {
+		return models.Transaction{}, errors.New("some transactions were not found")
+	}
+
+	var kept models.Transaction
+	for _, transaction := range transactions {
+		if transaction.TransferID != 0 {
+			return models.Transaction{}, errors.New("transfer legs cannot be merged")
+		}
+		if transaction.TransactionID == merge.KeepID {
+			kept = transaction
+		}
+	}
+
+	for _, transaction := range transactions {
+		if transaction.TransactionID == merge.KeepID {
+			continue
+		}
+		for _, tag := range transaction.Tags {
+			if !containsTag(kept.Tags, tag) {
+				kept.Tags = append(kept.Tags, tag)
+			}
+		}
+		if kept.Description == "" {
+			kept.Description = transaction.Description
+		}
+		if kept.ExternalID == "" {
+			kept.ExternalID = transaction.ExternalID
+		}
+		if kept.BankReference == "" {
+			kept.BankReference = transaction.BankReference
+		}
+		if kept.Counterparty == "" {
+			kept.Counterparty = transaction.Counterparty
+		}
+	}
+
+	if err := repo.MergeTransactions(userID, kept, merge.MergeIDs); err != nil {
+		return models.Transaction{}, err
+	}
+	ResetCategoryModel(userID) // Злиті дублікати більше не мають впливати на підказки категорій
+	CheckBudgetAlerts(userID)
+	kept.DuplicateOf = 0
+	return kept, nil
+}
+
+// containsTag - чи є тег у списку
+func containsTag(tags []string, tag string) bool {
+	for _, existing := range tags {
+		if existing == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"cashWise/models"
+	"cashWise/repo"
+)
+
+// ErrEnvelopeModeDisabled - конвертний бюджет користувача не ввімкнено
+var ErrEnvelopeModeDisabled = errors.New("envelope mode is disabled")
+
+const monthLayout = "2006-01"
+
+// SetEnvelopeMode - вмикає або вимикає конвертний бюджет. Відлік починається з першого дня
+// поточного місяця: доходи цього місяця вже можна розподіляти, а давніша історія не враховується.
+func SetEnvelopeMode(userID int, enabled bool) error {
+	if !enabled {
+		return repo.SetEnvelopeStart(userID, "")
+	}
+	settings, err := repo.GetSettingsByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if settings.EnvelopeStart != "" {
+		return nil
+	}
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return repo.SetEnvelopeStart(userID, start.Format(dateLayout))
+}
+
+// envelopeStart - перший день конвертного бюджету користувача
+func envelopeStart(userID int) (time.Time, error) {
+	settings, err := repo.GetSettingsByUserID(userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if settings.EnvelopeStart == "" {
+		return time.Time{}, ErrEnvelopeModeDisabled
+	}
+	return time.Parse(dateLayout, settings.EnvelopeStart)
+}
+
+// currentMonth - поточний місяць у форматі YYYY-MM
+func currentMonth() string {
+	return time.Now().UTC().Format(monthLayout)
+}
+
+// GetEnvelopes - стан конвертів за місяць (YYYY-MM, за замовчуванням поточний). Залишки
+// накопичуються з початку конвертного бюджету: доходи поповнюють нерозподілений пул, розподіл
+// переносить гроші з пулу в конверти, витрати зменшують конверт своєї категорії. Витрати без
+// категорії зменшують пул напряму.
+func GetEnvelopes(userID int, month string) (models.EnvelopeSummary, error) {
+	if month == "" {
+		month = currentMonth()
+	}
+	monthStart, err := time.Parse(monthLayout, month)
+	if err != nil {
+		return models.EnvelopeSummary{}, fmt.Errorf("month must be in YYYY-MM format")
+	}
+	start, err := envelopeStart(userID)
+	if err != nil {
+		return models.EnvelopeSummary{}, err
+	}
+	if monthStart.Before(start) {
+		return models.EnvelopeSummary{}, fmt.Errorf("envelope budgeting started in %s", start.Format(monthLayout))
+	}
+
+	from, monthFrom := start.Format(dateLayout), monthStart.Format(dateLayout)
+	before, to := monthStart.AddDate(0, 0, -1).Format(dateLayout), monthStart.AddDate(0, 1, -1).Format(dateLayout)
+
+	summary := models.EnvelopeSummary{Month: month, Envelopes: []models.Envelope{}}
+	envelopes := map[int]*models.Envelope{}
+	envelope := func(categoryID int) *models.Envelope {
+		if envelopes[categoryID] == nil {
+			envelopes[categoryID] = &models.Envelope{CategoryID: categoryID}
+		}
+		return envelopes[categoryID]
+	}
+
+	// Попередні місяці дають лише перенесений залишок; при першому місяці їх немає
+	if monthFrom > from {
+		income, err := repo.GetCategoryTotals(userID, []string{"income"}, from, before)
+		if err != nil {
+			return summary, err
+		}
+		for _, total := range income {
+			summary.Unassigned += total.Total
+		}
+		expenses, err := repo.GetCategoryTotals(userID, []string{"expense"}, from, before)
+		if err != nil {
+			return summary, err
+		}
+		for _, total := range expenses {
+			if total.CategoryID == 0 {
+				summary.Unassigned -= total.Total
+				continue
+			}
+			envelope(total.CategoryID).Carried -= total.Total
+		}
+	}
+
+	income, err := repo.GetCategoryTotals(userID, []string{"income"}, monthFrom, to)
+	if err != nil {
+		return summary, err
+	}
+	for _, total := range income {
+		summary.Income += total.Total
+	}
+	summary.Unassigned += summary.Income
+	expenses, err := repo.GetCategoryTotals(userID, []string{"expense"}, monthFrom, to)
+	if err != nil {
+		return summary, err
+	}
+	for _, total := range expenses {
+		if total.CategoryID == 0 {
+			summary.Unassigned -= total.Total
+			continue
+		}
+		envelope(total.CategoryID).Spent += total.Total
+	}
+
+	movements, err := repo.GetEnvelopeMovements(userID, start.Format(monthLayout), month)
+	if err != nil {
+		return summary, err
+	}
+	for _, movement := range movements {
+		for _, side := range []struct {
+			categoryID int
+			amount     float64
+		}{{movement.FromCategoryID, -movement.Amount}, {movement.ToCategoryID, movement.Amount}} {
+			switch {
+			case side.categoryID == 0:
+				summary.Unassigned += side.amount
+			case movement.Month < month:
+				envelope(side.categoryID).Carried += side.amount
+			default:
+				envelope(side.categoryID).Assigned += side.amount
+			}
+		}
+	}
+
+	categories, err := repo.GetCategories(userID)
+	if err != nil {
+		return summary, err
+	}
+	names := make(map[int]string, len(categories))
+	for _, category := range categories {
+		names[category.CategoryID] = category.Name
+	}
+	for categoryID, envelope := range envelopes {
+		envelope.Name = names[categoryID]
+		envelope.Carried = roundMoney(envelope.Carried)
+		envelope.Assigned = roundMoney(envelope.Assigned)
+		envelope.Spent = roundMoney(envelope.Spent)
+		envelope.Available = roundMoney(envelope.Carried + envelope.Assigned - envelope.Spent)
+		summary.Envelopes = append(summary.Envelopes, *envelope)
+	}
+	sort.Slice(summary.Envelopes, func(i, j int) bool { return summary.Envelopes[i].Name < summary.Envelopes[j].Name })
+	summary.Income = roundMoney(summary.Income)
+	summary.Unassigned = roundMoney(summary.Unassigned)
+	return summary, nil
+}
+
+// envelopeLocks - м'ютекси переміщень за userID: залишок перевіряється і переміщення записується
+// під одним замком, тож паралельні переміщення не витратять ті самі гроші двічі
+var envelopeLocks = struct {
+	sync.Mutex
+	byUser map[int]*sync.Mutex
+}{byUser: map[int]*sync.Mutex{}}
+
+// lockEnvelopes - захоплює м'ютекс переміщень користувача і повертає функцію для його звільнення
+func lockEnvelopes(userID int) func() {
+	envelopeLocks.Lock()
+	lock, ok := envelopeLocks.byUser[userID]
+	if !ok {
+		lock = &sync.Mutex{}
+		envelopeLocks.byUser[userID] = lock
+	}
+	envelopeLocks.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// MoveEnvelopeMoney - переміщує гроші в поточному місяці: з пулу в конверт (fromCategoryID = 0),
+// між конвертами або назад у пул (toCategoryID = 0). Переміщувати можна лише наявні гроші;
+// переміщення одного користувача виконуються по черзі.
+func MoveEnvelopeMoney(userID int, movement models.EnvelopeMovement) (models.EnvelopeMovement, error) {
+	movement.Amount = roundMoney(movement.Amount)
+	if movement.Amount <= 0 {
+		return movement, fmt.Errorf("amount must be positive")
+	}
+	if movement.FromCategoryID == movement.ToCategoryID {
+		return movement, fmt.Errorf("source and destination must differ")
+	}
+	for _, categoryID := range []int{movement.FromCategoryID, movement.ToCategoryID} {
+		if categoryID == 0 {
+			continue
+		}
+		category, err := repo.GetCategoryByID(userID, categoryID)
+		if err != nil {
+			return movement, fmt.Errorf("category %d not found", categoryID)
+		}
+		if category.Archived && categoryID == movement.ToCategoryID {
+			return movement, fmt.Errorf("category %d is archived", categoryID)
+		}
+	}
+
+	defer lockEnvelopes(userID)()
+
+	movement.UserID = userID
+	movement.Month = currentMonth()
+	summary, err := GetEnvelopes(userID, movement.Month)
+	if err != nil {
+		return movement, err
+	}
+	available := summary.Unassigned
+	if movement.FromCategoryID != 0 {
+		available = 0
+		for _, envelope := range summary.Envelopes {
+			if envelope.CategoryID == movement.FromCategoryID {
+				available = envelope.Available
+			}
+		}
+	}
+	if movement.Amount > available {
+		return movement, fmt.Errorf("only %.2f is available to move", math.Max(available, 0))
+	}
+
+	movement.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	return repo.AddEnvelopeMovement(movement)
+}
+
+// CopyLastMonthEnvelopes - повторює розподіл конвертів минулого місяця в поточному: кожен конверт
+// отримує з пулу стільки, скільки отримав минулого місяця, за вирахуванням уже розподіленого цього
+// місяця. Конверти, на які не вистачило грошей у пулі, повертаються в skipped.
+func CopyLastMonthEnvelopes(userID int) ([]models.EnvelopeMovement, map[int]string, error) {
+	now := time.Now().UTC()
+	lastMonth := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC).Format(monthLayout)
+	previous, err := GetEnvelopes(userID, lastMonth)
+	if err != nil {
+		return nil, nil, err
+	}
+	current, err := GetEnvelopes(userID, currentMonth())
+	if err != nil {
+		return nil, nil, err
+	}
+	assigned := map[int]float64{}
+	for _, envelope := range current.Envelopes {
+		assigned[envelope.CategoryID] = envelope.Assigned
+	}
+
+	movements := []models.EnvelopeMovement{}
+	skipped := map[int]string{}
+	for _, envelope := range previous.Envelopes {
+		amount := roundMoney(envelope.Assigned - assigned[envelope.CategoryID])
+		if amount <= 0 {
+			continue
+		}
+		movement, err := MoveEnvelopeMoney(userID, models.EnvelopeMovement{
+			ToCategoryID: envelope.CategoryID,
+			Amount:       amount,
+			Note:         "Copied from " + lastMonth,
+		})
+		if err != nil {
+			skipped[envelope.CategoryID] = err.Error()
+			continue
+		}
+		movements = append(movements, movement)
+	}
+	return movements, skipped, nil
+}
+
+// GetEnvelopeMovements - історія переміщень за місяць (YYYY-MM, за замовчуванням поточний)
+func GetEnvelopeMovements(userID int, month string) ([]models.EnvelopeMovement, error) {
+	if month == "" {
+		month = currentMonth()
+	}
+	if _, err := time.Parse(monthLayout, month); err != nil {
+		return nil, fmt.Errorf("month must be in YYYY-MM format")
+	}
+	if _, err := envelopeStart(userID); err != nil {
+		return nil, err
+	}
+	return repo.GetEnvelopeMovements(userID, month, month)
+}
+
+// roundMoney - округлює суму до копійок
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
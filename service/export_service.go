@@ -0,0 +1,207 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+
+	"cashWise/db"
+	"cashWise/models"
+	"cashWise/repo"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ExportFormat - тип вмісту та розширення файлу для формату експорту
+type ExportFormat struct {
+	ContentType string
+	Extension   string
+}
+
+// ExportFormats - підтримувані формати експорту транзакцій
+var ExportFormats = map[string]ExportFormat{
+	"csv":  {ContentType: "text/csv; charset=utf-8", Extension: "csv"},
+	"xlsx": {ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", Extension: "xlsx"},
+	"json": {ContentType: "application/json", Extension: "json"},
+}
+
+// exportColumns - колонки табличних форматів (CSV, XLSX)
+var exportColumns = []string{
+	"transactionID", "date", "type", "amount", "currency", "account",
+	"categoryID", "category", "icon", "description", "tags", "counterparty", "transferID",
+}
+
+// categoryCache - категорії, вже отримані під час експорту, щоб не звертатися до БД для кожного рядка
+type categoryCache map[int]models.Category
+
+func (c categoryCache) get(categoryID int) models.Category {
+	if category, ok := c[categoryID]; ok {
+		return category
+	}
+	var category models.Category
+	err := db.GetCategoryCollection().FindOne(context.TODO(), bson.M{"categoryID": categoryID}).Decode(&category)
+	if err != nil {
+		log.Printf("Error retrieving category %d for export: %v", categoryID, err)
+	}
+	c[categoryID] = category
+	return category
+}
+
+// categoryOf - категорія транзакції; у частин переказу категорії немає
+func (c categoryCache) categoryOf(transaction models.Transaction) models.Category {
+	if transaction.TransferID != 0 {
+		return models.Category{}
+	}
+	return c.get(transaction.CategoryID)
+}
+
+// exportRow - значення колонок exportColumns для транзакції
+func exportRow(transaction models.Transaction, category models.Category) []interface{} {
+	return []interface{}{
+		transaction.TransactionID,
+		transaction.Date,
+		transaction.Type,
+		transaction.Amount,
+		transaction.Currency,
+		transaction.Account,
+		transaction.CategoryID,
+		category.Name,
+		category.Icon,
+		transaction.Description,
+		strings.Join(transaction.Tags, ", "),
+		transaction.Counterparty,
+		transaction.TransferID,
+	}
+}
+
+// exportObject - транзакція для JSON-експорту в тому ж вигляді, що й у списку транзакцій, з назвою категорії
+func exportObject(transaction models.Transaction, categories categoryCache) bson.M {
+	category := categories.categoryOf(transaction)
+
+	result := bson.M{
+		"transactionID": transaction.TransactionID,
+		"userID":        transaction.UserID,
+		"categoryID":    transaction.CategoryID,
+		"category":      category.Name,
+		"type":          transaction.Type,
+		"amount":        transaction.Amount,
+		"date":          transaction.Date,
+		"description":   transaction.Description,
+		"icon":          category.Icon,
+	}
+	addTransferFields(result, transaction)
+	if transaction.Account != "" {
+		result["account"] = transaction.Account
+	}
+	if transaction.Currency != "" {
+		result["currency"] = transaction.Currency
+	}
+	if len(transaction.Tags) > 0 {
+		result["tags"] = transaction.Tags
+	}
+	if len(transaction.Splits) > 0 {
+		splits := make([]bson.M, 0, len(transaction.Splits))
+		for _, split := range transaction.Splits {
+			splitCategory := categories.get(split.CategoryID)
+			splits = append(splits, bson.M{
+				"categoryID": split.CategoryID,
+				"category":   splitCategory.Name,
+				"amount":     split.Amount,
+				"memo":       split.Memo,
+				"icon":       splitCategory.Icon,
+			})
+		}
+		result["splits"] = splits
+	}
+	return result
+}
+
+// csvValue - текстове представлення значення для CSV
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	case int:
+		if v == 0 {
+			return ""
+		}
+		return strconv.Itoa(v)
+	}
+	return fmt.Sprint(value)
+}
+
+// ExportTransactions - пише транзакції у форматі format (csv, xlsx, json) прямо в w, рядок за рядком з курсора
+func ExportTransactions(w io.Writer, format string, userID int, search models.TransactionSearch, sortField string, desc bool) error {
+	categories := categoryCache{}
+
+	switch format {
+	case "csv":
+		// BOM, щоб Excel відкривав кирилицю як UTF-8
+		if _, err := io.WriteString(w, "\xef\xbb\xbf"); err != nil {
+			return err
+		}
+		writer := csv.NewWriter(w)
+		if err := writer.Write(exportColumns); err != nil {
+			return err
+		}
+		err := repo.StreamTransactions(userID, search, sortField, desc, func(transaction models.Transaction) error {
+			row := exportRow(transaction, categories.categoryOf(transaction))
+			record := make([]string, len(row))
+			for i, value := range row {
+				record[i] = csvValue(value)
+			}
+			return writer.Write(record)
+		})
+		writer.Flush()
+		if err != nil {
+			return err
+		}
+		return writer.Error()
+
+	case "xlsx":
+		writer, err := newXLSXWriter(w)
+		if err != nil {
+			return err
+		}
+		header := make([]interface{}, len(exportColumns))
+		for i, column := range exportColumns {
+			header[i] = column
+		}
+		if err := writer.writeRow(header, xlsxStyleHeader); err != nil {
+			return err
+		}
+		err = repo.StreamTransactions(userID, search, sortField, desc, func(transaction models.Transaction) error {
+			return writer.writeRow(exportRow(transaction, categories.categoryOf(transaction)), 0)
+		})
+		if err != nil {
+			return err
+		}
+		return writer.close()
+
+	case "json":
+		// Масив пишемо вручну, щоб не тримати всі об'єкти в пам'яті
+		buffered := bufio.NewWriter(w)
+		encoder := json.NewEncoder(buffered)
+		buffered.WriteString("[")
+		first := true
+		err := repo.StreamTransactions(userID, search, sortField, desc, func(transaction models.Transaction) error {
+			if !first {
+				buffered.WriteString(",")
+			}
+			first = false
+			return encoder.Encode(exportObject(transaction, categories))
+		})
+		if err != nil {
+			return err
+		}
+		buffered.WriteString("]\n")
+		return buffered.Flush()
+	}
+	return fmt.Errorf("unsupported export format: %s", format)
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"cashWise/models"
+	"cashWise/repo"
+	"fmt"
+	"math"
+	"time"
+)
+
+// rowToTransaction - перетворює рядок імпорту на транзакцію: знак суми визначає тип
+func rowToTransaction(userID int, batchID int, row models.ImportRow) models.Transaction {
+	transactionType := "income"
+	if row.Amount < 0 {
+		transactionType = "expense"
+	}
+	return models.Transaction{
+		UserID:        userID,
+		CategoryID:    row.CategoryID,
+		Type:          transactionType,
+		Amount:        math.Abs(row.Amount),
+		Date:          row.Date,
+		Description:   row.Description,
+		Account:       row.Account,
+		Currency:      row.Currency,
+		ImportBatchID: batchID,
+		ExternalID:    row.ExternalID,
+		DuplicateOf:   row.DuplicateOf,
+
+		Counterparty:     row.Counterparty,
+		CounterpartyIBAN: row.CounterpartyIBAN,
+		ValueDate:        row.ValueDate,
+		BankReference:    row.BankReference,
+		Tags:             row.Tags,
+		PayeeID:          row.PayeeID,
+	}
+}
+
+// balanceTolerance - допустима похибка округлення під час звірки залишків
+const balanceTolerance = 0.005
+
+// checkStatementBalance - перевіряє, що початковий залишок разом з операціями дає кінцевий
+func checkStatementBalance(balance models.StatementBalance) error {
+	expected := balance.OpeningBalance + balance.EntriesTotal
+	if math.Abs(expected-balance.ClosingBalance) > balanceTolerance {
+		return fmt.Errorf("statement for %s (%s - %s) does not add up: opening %.2f + entries %.2f = %.2f, closing balance is %.2f",
+			balance.Account, balance.From, balance.To, balance.OpeningBalance, balance.EntriesTotal, expected, balance.ClosingBalance)
+	}
+	return nil
+}
+
+// ReconcileStatements - порівнює початкові залишки виписок із залишками рахунків, обчисленими за транзакціями.
+// Якщо рахунок ще не має транзакцій, звірка вважається успішною.
+func ReconcileStatements(userID int, preview *models.ImportPreview) error {
+	for i := range preview.Statements {
+		statement := &preview.Statements[i]
+		balance, count, err := repo.GetAccountBalance(userID, statement.Account, statement.From)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			statement.Matches = true
+			continue
+		}
+		statement.AccountBalance = &balance
+		statement.Difference = math.Round((statement.OpeningBalance-balance)*100) / 100
+		statement.Matches = math.Abs(statement.Difference) <= balanceTolerance
+	}
+	return nil
+}
+
+// ApplyImportDefaults - задає рахунок рядкам, для яких файл його не визначив.
+// Рахунок, переданий користувачем, має перевагу над даними з виписки.
+func ApplyImportDefaults(preview *models.ImportPreview, account string) {
+	statementAccount := ""
+	if preview.Account != nil {
+		statementAccount = preview.Account.AccountID
+		if statementAccount == "" {
+			statementAccount = preview.Account.Name
+		}
+	}
+	if account != "" {
+		for i := range preview.Statements {
+			preview.Statements[i].Account = account
+		}
+	}
+	for i := range preview.Rows {
+		row := &preview.Rows[i]
+		if account != "" {
+			row.Account = account
+		} else if row.Account == "" {
+			row.Account = statementAccount
+		}
+	}
+}
+
+// ApplyImportDefaultCategory - категорія імпорту за замовчуванням для рядків, яким її не дали
+// ні правила, ні отримувач; тому застосовується останньою
+func ApplyImportDefaultCategory(rows []models.ImportRow, categoryID int) {
+	for i := range rows {
+		if rows[i].CategoryID == 0 {
+			rows[i].CategoryID = categoryID
+		}
+	}
+}
+
+// MarkDuplicateRows - позначає рядки, чий ExternalID уже імпортовано, та рахує рядки до створення
+func MarkDuplicateRows(userID int, preview *models.ImportPreview) error {
+	// FITID унікальний у межах рахунку, тому перевіряємо кожен рахунок окремо
+	idsByAccount := map[string][]string{}
+	for _, row := range preview.Rows {
+		if row.ExternalID != "" && row.Error == "" {
+			idsByAccount[row.Account] = append(idsByAccount[row.Account], row.ExternalID)
+		}
+	}
+
+	existing := map[string]map[string]bool{}
+	for account, ids := range idsByAccount {
+		found, err := repo.GetExistingExternalIDs(userID, account, ids)
+		if err != nil {
+			return err
+		}
+		existing[account] = found
+	}
+
+	// Рядки без збігу за ID банку порівнюємо з наявними транзакціями за сумою, датою та описом
+	if err := flagSimilarRows(userID, preview.Rows); err != nil {
+		return err
+	}
+
+	preview.NewRows, preview.Duplicates = 0, 0
+	seen := map[string]bool{} // Повтори всередині самого файлу
+	for i := range preview.Rows {
+		row := &preview.Rows[i]
+		if row.Error != "" {
+			continue
+		}
+		if row.ExternalID != "" {
+			key := row.Account + "\x00" + row.ExternalID
+			row.Duplicate = existing[row.Account][row.ExternalID] || seen[key]
+			seen[key] = true
+		}
+		if row.Duplicate {
+			preview.Duplicates++
+		} else {
+			preview.NewRows++
+		}
+	}
+	return nil
+}
+
+// CommitImport - створює транзакції з розібраних рядків через repo.AddTransaction під одним ID імпорту.
+// Рядки з помилками розбору пропускаються і потрапляють у звіт імпорту, дублікати лише рахуються.
+func CommitImport(userID int, fileName string, preview models.ImportPreview) (models.ImportBatch, error) {
+	batchID, err := repo.GetNextImportBatchID()
+	if err != nil {
+		return models.ImportBatch{}, fmt.Errorf("failed to get next import batch ID: %v", err)
+	}
+
+	batch := models.ImportBatch{
+		BatchID:    batchID,
+		UserID:     userID,
+		Source:     preview.Format,
+		FileName:   fileName,
+		CreatedAt:  time.Now().UTC().Format(time.RFC3339),
+		Statements: preview.Statements,
+		Status:     "completed",
+	}
+
+	// Невідомих продавців додаємо до довідника, щоб наступні імпорти впізнавали їх
+	payees, err := loadPayeeDirectory(userID)
+	if err != nil {
+		return models.ImportBatch{}, fmt.Errorf("failed to load payees: %v", err)
+	}
+
+	for _, row := range preview.Rows {
+		if row.Error != "" {
+			batch.Failed++
+			batch.Errors = append(batch.Errors, fmt.Sprintf("line %d: %s", row.Line, row.Error))
+			continue
+		}
+		if row.Duplicate {
+			batch.Skipped++
+			continue
+		}
+		transaction := rowToTransaction(userID, batchID, row)
+		if transaction.PayeeID == 0 {
+			if err := payees.resolve(&transaction, true); err != nil {
+				batch.Failed++
+				batch.Errors = append(batch.Errors, fmt.Sprintf("line %d: %v", row.Line, err))
+				continue
+			}
+		}
+		if err := repo.AddTransaction(transaction); err != nil {
+			batch.Failed++
+			batch.Errors = append(batch.Errors, fmt.Sprintf("line %d: %v", row.Line, err))
+			continue
+		}
+		LearnTransaction(transaction)
+		batch.Created++
+	}
+
+	// Створених імпортом отримувачів відкат імпорту прибере разом з транзакціями
+	batch.PayeeIDs = payees.created
+	if err := repo.SaveImportBatch(batch); err != nil {
+		return batch, err
+	}
+	if batch.Created > 0 {
+		CheckBudgetAlerts(userID)
+	}
+	return batch, nil
+}
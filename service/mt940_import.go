@@ -0,0 +1,251 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"cashWise/models"
+)
+
+// mt940Tag - рядок, з якого починається поле MT940, наприклад ":61:" або ":60F:"
+var mt940Tag = regexp.MustCompile(`^:(\d{2}[A-Z]?):`)
+
+// mt940Balance - поля :60F:/:60M:/:62F:/:62M: виду C240131EUR1234,56
+var mt940Balance = regexp.MustCompile(`^([CD])(\d{6})([A-Z]{3})([\d,]+)`)
+
+// mt940Entry - поле :61: - дата валютування, необов'язкова дата проведення MMDD, знак, сума, тип, посилання клієнта і банку
+var mt940Entry = regexp.MustCompile(`^(\d{6})(\d{4})?(RC|RD|C|D)([A-Z])?([\d,]+)([NSF][A-Z0-9]{3})([^/\n]*)(?://([^\n]*))?`)
+
+// mt940Field - поле виписки разом з рядками-продовженнями
+type mt940Field struct {
+	tag   string
+	value string
+	line  int
+}
+
+// splitMT940Fields - розбиває текст на поля; рядки без тегу належать попередньому полю
+func splitMT940Fields(text string) []mt940Field {
+	// Повідомлення SWIFT може бути обгорнуте блоками {1:...}{2:...}{4: ... -}
+	if start := strings.Index(text, "{4:"); start >= 0 {
+		text = text[start+3:]
+	}
+
+	var fields []mt940Field
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(line, " ")
+		if match := mt940Tag.FindStringSubmatch(line); match != nil {
+			fields = append(fields, mt940Field{tag: match[1], value: line[len(match[0]):], line: i + 1})
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "-") || len(fields) == 0 {
+			continue // Кінець повідомлення або службові рядки
+		}
+		fields[len(fields)-1].value += "\n" + line
+	}
+	return fields
+}
+
+// parseMT940Balance - розбирає залишок зі знаком і датою
+func parseMT940Balance(value string) (float64, string, string, error) {
+	match := mt940Balance.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return 0, "", "", fmt.Errorf("invalid balance %q", value)
+	}
+	date, err := time.Parse("060102", match[2])
+	if err != nil {
+		return 0, "", "", fmt.Errorf("invalid balance date %q", match[2])
+	}
+	amount, err := ParseAmount(match[4], ",")
+	if err != nil {
+		return 0, "", "", err
+	}
+	if match[1] == "D" {
+		amount = -amount
+	}
+	return amount, date.Format(dateLayout), match[3], nil
+}
+
+// parseMT940Entry - розбирає поле :61: у рядок імпорту
+func parseMT940Entry(value string, line int) models.ImportRow {
+	row := models.ImportRow{Line: line}
+
+	match := mt940Entry.FindStringSubmatch(value)
+	if match == nil {
+		row.Error = fmt.Sprintf("invalid statement line %q", strings.SplitN(value, "\n", 2)[0])
+		return row
+	}
+
+	valueDate, err := time.Parse("060102", match[1])
+	if err != nil {
+		row.Error = "invalid value date"
+		return row
+	}
+	row.ValueDate = valueDate.Format(dateLayout)
+
+	// Дата проведення має лише місяць і день; рік беремо з дати валютування з поправкою на межу року
+	bookingDate := valueDate
+	if match[2] != "" {
+		booked, err := time.Parse("0102", match[2])
+		if err != nil {
+			row.Error = "invalid booking date"
+			return row
+		}
+		bookingDate = time.Date(valueDate.Year(), booked.Month(), booked.Day(), 0, 0, 0, 0, time.UTC)
+		if bookingDate.Sub(valueDate) > 180*24*time.Hour {
+			bookingDate = bookingDate.AddDate(-1, 0, 0)
+		} else if valueDate.Sub(bookingDate) > 180*24*time.Hour {
+			bookingDate = bookingDate.AddDate(1, 0, 0)
+		}
+	}
+	row.Date = bookingDate.Format(dateLayout)
+
+	if row.Amount, err = ParseAmount(match[5], ","); err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	// RC - сторно зарахування (списання), RD - сторно списання (зарахування)
+	if match[3] == "D" || match[3] == "RC" {
+		row.Amount = -row.Amount
+	}
+
+	row.BankReference = strings.TrimSpace(match[8])
+	if customerReference := strings.TrimSpace(match[7]); row.BankReference == "" && customerReference != "NONREF" {
+		row.BankReference = customerReference
+	}
+
+	// Другий рядок поля :61: - додаткові відомості
+	if parts := strings.SplitN(value, "\n", 2); len(parts) == 2 {
+		row.Description = strings.TrimSpace(parts[1])
+	}
+	return row
+}
+
+// applyMT940Information - заповнює контрагента та призначення з поля :86:.
+// Підтримуються структуровані варіанти ?20..?33 (німецькі банки) і /NAME/, /IBAN/, /REMI/ (SEPA), інакше текст береться як є.
+func applyMT940Information(row *models.ImportRow, information string) {
+	information = strings.ReplaceAll(information, "\n", "")
+
+	var purpose string
+	switch {
+	case strings.Contains(information, "?2") || strings.Contains(information, "?3"):
+		for _, part := range strings.Split(information, "?")[1:] {
+			if len(part) < 2 {
+				continue
+			}
+			code, value := part[:2], strings.TrimSpace(part[2:])
+			switch {
+			case code >= "20" && code <= "29", code >= "60" && code <= "63":
+				purpose += value // Призначення розбите на частини по 27 символів, часто посеред слова
+			case code == "31":
+				row.CounterpartyIBAN = value
+			case code == "32", code == "33":
+				row.Counterparty = strings.TrimSpace(row.Counterparty + value)
+			}
+		}
+	case strings.HasPrefix(information, "/"):
+		parts := strings.Split(information, "/")
+		for i := 1; i+1 < len(parts); i += 2 {
+			code, value := parts[i], strings.TrimSpace(parts[i+1])
+			switch code {
+			case "NAME":
+				row.Counterparty = value
+			case "IBAN":
+				row.CounterpartyIBAN = value
+			case "REMI":
+				purpose = value
+			}
+		}
+	default:
+		purpose = strings.TrimSpace(information)
+	}
+
+	row.Description = strings.Join(nonEmpty(row.Counterparty, strings.TrimSpace(purpose), row.Description), " - ")
+}
+
+// ParseMT940Statement - розбирає виписку SWIFT MT940. Кожна виписка у файлі (поле :20:) звіряється:
+// початковий залишок :60F:/:60M: разом з операціями :61: має дати кінцевий :62F:/:62M:.
+func ParseMT940Statement(data []byte) (models.ImportPreview, error) {
+	preview := models.ImportPreview{Format: "mt940", Rows: []models.ImportRow{}}
+
+	text, encoding, err := decodeStatement(data, "")
+	if err != nil {
+		return preview, err
+	}
+	preview.Encoding = encoding
+
+	fields := splitMT940Fields(text)
+	if len(fields) == 0 {
+		return preview, errors.New("not an MT940 file: no fields found")
+	}
+
+	var account, reference string
+	var balance *models.StatementBalance
+	var row *models.ImportRow
+
+	for _, field := range fields {
+		switch field.tag {
+		case "20":
+			reference = strings.TrimSpace(field.value)
+		case "25":
+			account = strings.TrimSpace(field.value)
+			if preview.Account == nil {
+				preview.Account = &models.StatementAccount{AccountID: account}
+			}
+		case "60F", "60M":
+			amount, date, currency, err := parseMT940Balance(field.value)
+			if err != nil {
+				return preview, fmt.Errorf("statement %s: opening balance: %v", reference, err)
+			}
+			balance = &models.StatementBalance{Account: account, Currency: currency, From: date, OpeningBalance: amount}
+			if preview.Account != nil && preview.Account.Currency == "" {
+				preview.Account.Currency = currency
+			}
+		case "61":
+			if balance == nil {
+				return preview, fmt.Errorf("line %d: statement line before opening balance", field.line)
+			}
+			entry := parseMT940Entry(field.value, field.line)
+			if entry.Error != "" {
+				return preview, fmt.Errorf("statement %s, line %d: %s", reference, entry.Line, entry.Error)
+			}
+			entry.Account = account
+			entry.Currency = balance.Currency
+			entry.ExternalID = entry.BankReference
+			balance.EntriesTotal += entry.Amount
+			preview.Rows = append(preview.Rows, entry)
+			row = &preview.Rows[len(preview.Rows)-1]
+		case "86":
+			if row != nil {
+				applyMT940Information(row, field.value)
+				row = nil
+			}
+		case "62F", "62M":
+			if balance == nil {
+				return preview, fmt.Errorf("line %d: closing balance without opening balance", field.line)
+			}
+			amount, date, _, err := parseMT940Balance(field.value)
+			if err != nil {
+				return preview, fmt.Errorf("statement %s: closing balance: %v", reference, err)
+			}
+			balance.ClosingBalance, balance.To = amount, date
+			balance.EntriesTotal = math.Round(balance.EntriesTotal*100) / 100
+			if err := checkStatementBalance(*balance); err != nil {
+				return preview, err
+			}
+			preview.Statements = append(preview.Statements, *balance)
+			balance, row = nil, nil
+		}
+	}
+
+	if balance != nil {
+		return preview, fmt.Errorf("statement %s: closing balance (:62F:) is missing", reference)
+	}
+	if len(preview.Statements) == 0 {
+		return preview, errors.New("not an MT940 file: no statements found")
+	}
+	return preview, nil
+}
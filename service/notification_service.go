@@ -5,6 +5,8 @@ import (
 	"cashWise/repo"
 	"encoding/json"
 	"fmt"
+	"log"
+	"time"
 )
 
 func CheckGoalTransactionsAndNotify(userID int) (bool, error) {
@@ -52,3 +54,71 @@ func CreateGoalNotification(user models.User, goal string) ([]byte, error) {
 
 	return notificationJSON, nil
 }
+
+// CreateBudgetNotification - сповіщення про досягнення порогу бюджету. Ключ гарантує, що
+// кожен поріг спрацьовує лише раз за період бюджету.
+func CreateBudgetNotification(status models.BudgetStatus, threshold float64) models.Notification {
+	message := fmt.Sprintf("Budget %q has reached %g%% of its limit: %.2f of %.2f spent", status.Name, threshold, status.Spent, status.Available)
+	if threshold >= 100 {
+		message = fmt.Sprintf("Budget %q is exceeded: %.2f of %.2f spent", status.Name, status.Spent, status.Available)
+	}
+	return models.Notification{
+		UserID:    status.UserID,
+		Type:      "budgetThreshold",
+		Message:   message,
+		BudgetID:  status.BudgetID,
+		Threshold: threshold,
+		Key:       fmt.Sprintf("budget:%d:%s:%g", status.BudgetID, status.From, threshold),
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// CheckBudgetAlerts - після зміни транзакцій перевіряє пороги бюджетів користувача і надсилає
+// по сповіщенню на кожен досягнутий у поточному періоді поріг. Нічого не надсилає, якщо
+// сповіщення вимкнено. Помилки лише журналюються, щоб не зривати збереження транзакції.
+func CheckBudgetAlerts(userID int) {
+	settings, err := repo.GetSettingsByUserID(userID)
+	if err != nil {
+		log.Printf("Budget alerts skipped for userID %d: %v", userID, err)
+		return
+	}
+	if !settings.Notifications {
+		return
+	}
+
+	budgets, err := repo.GetBudgetsByUserID(userID)
+	if err != nil {
+		log.Printf("Budget alerts skipped for userID %d: %v", userID, err)
+		return
+	}
+	now := time.Now().UTC()
+	for _, budget := range budgets {
+		if len(budget.Thresholds) == 0 {
+			continue
+		}
+		if budget, err = RollOverBudget(budget, now); err != nil {
+			log.Printf("Error rolling over budget %d: %v", budget.BudgetID, err)
+			continue
+		}
+		status, err := GetBudgetStatus(budget, now)
+		if err != nil {
+			log.Printf("Error checking budget %d: %v", budget.BudgetID, err)
+			continue
+		}
+
+		for _, threshold := range budget.Thresholds {
+			if status.PercentUsed < threshold {
+				continue
+			}
+			notification := CreateBudgetNotification(status, threshold)
+			created, err := repo.AddNotification(notification)
+			if err != nil {
+				log.Printf("Error sending budget alert for budget %d: %v", budget.BudgetID, err)
+				continue
+			}
+			if created {
+				log.Printf("Notification for userID %d: %s", userID, notification.Message)
+			}
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"cashWise/models"
+)
+
+// ofxToken - відкриваючий або закриваючий тег OFX разом з текстом після нього
+type ofxToken struct {
+	name    string
+	closing bool
+	value   string
+}
+
+// tokenizeOFX - розбирає тіло OFX на теги. Підходить для обох варіантів:
+// у SGML (OFX 1.x) листові елементи не закриваються, у XML (OFX 2.x) закриваються.
+func tokenizeOFX(body string) []ofxToken {
+	var tokens []ofxToken
+	for {
+		start := strings.Index(body, "<")
+		if start < 0 {
+			return tokens
+		}
+		end := strings.Index(body[start:], ">")
+		if end < 0 {
+			return tokens
+		}
+		tag := strings.TrimSpace(body[start+1 : start+end])
+		body = body[start+end+1:]
+
+		// Інструкції обробки та коментарі XML пропускаємо
+		if strings.HasPrefix(tag, "?") || strings.HasPrefix(tag, "!") {
+			continue
+		}
+
+		token := ofxToken{name: strings.ToUpper(tag)}
+		if strings.HasPrefix(tag, "/") {
+			token.closing = true
+			token.name = strings.ToUpper(strings.TrimPrefix(tag, "/"))
+		} else {
+			next := strings.Index(body, "<")
+			if next < 0 {
+				next = len(body)
+			}
+			token.value = html.UnescapeString(strings.TrimSpace(body[:next]))
+		}
+		tokens = append(tokens, token)
+	}
+}
+
+// parseOFXDate - розбирає дату OFX виду YYYYMMDD[HHMMSS[.XXX][[-5:EST]]]
+func parseOFXDate(value string) (string, error) {
+	if len(value) < 8 {
+		return "", fmt.Errorf("invalid date %q", value)
+	}
+	date, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return "", fmt.Errorf("invalid date %q", value)
+	}
+	return date.Format(dateLayout), nil
+}
+
+// parseOFXAmount - розбирає суму OFX; деякі банки використовують кому як десятковий роздільник
+func parseOFXAmount(value string) (float64, error) {
+	amount, err := strconv.ParseFloat(strings.Replace(strings.TrimSpace(value), ",", ".", 1), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	return amount, nil
+}
+
+// ParseOFXStatement - розбирає виписку OFX/QFX (SGML або XML) у рядки імпорту.
+// FITID операції зберігається як ExternalID для пошуку дублікатів.
+func ParseOFXStatement(data []byte) (models.ImportPreview, error) {
+	preview := models.ImportPreview{Format: "ofx", Rows: []models.ImportRow{}}
+
+	text, encoding, err := decodeStatement(data, "")
+	if err != nil {
+		return preview, err
+	}
+	preview.Encoding = encoding
+
+	// Заголовок SGML-варіанту (OFXHEADER:100 ...) не містить тегів, тому просто починаємо з <OFX>
+	start := strings.Index(strings.ToUpper(text), "<OFX>")
+	if start < 0 {
+		return preview, errors.New("not an OFX file: <OFX> element not found")
+	}
+
+	account := &models.StatementAccount{}
+	var row *models.ImportRow
+	var name, memo string
+	var balanceDate string
+	var balance *float64
+	inLedgerBalance := false
+
+	for _, token := range tokenizeOFX(text[start:]) {
+		if token.closing {
+			switch token.name {
+			case "STMTTRN":
+				if row != nil {
+					if memo == name {
+						memo = ""
+					}
+					row.Description = strings.Join(nonEmpty(name, memo), " - ")
+					preview.Rows = append(preview.Rows, *row)
+					row = nil
+				}
+			case "LEDGERBAL":
+				inLedgerBalance = false
+			}
+			continue
+		}
+
+		switch token.name {
+		case "STMTTRN":
+			row = &models.ImportRow{Line: len(preview.Rows) + 1}
+			name, memo = "", ""
+			continue
+		case "LEDGERBAL":
+			inLedgerBalance = true
+			continue
+		}
+
+		if row != nil {
+			switch token.name {
+			case "DTPOSTED":
+				if row.Date, err = parseOFXDate(token.value); err != nil {
+					row.Error = err.Error()
+				}
+			case "TRNAMT":
+				if row.Amount, err = parseOFXAmount(token.value); err != nil {
+					row.Error = err.Error()
+				}
+			case "FITID":
+				row.ExternalID = token.value
+			case "NAME", "PAYEE":
+				name = token.value
+			case "MEMO":
+				memo = token.value
+			case "CURSYM": // Валюта операції, якщо вона відрізняється від CURDEF
+				row.Currency = token.value
+			}
+			continue
+		}
+
+		switch token.name {
+		case "CURDEF":
+			account.Currency = token.value
+		case "BANKID":
+			account.BankID = token.value
+		case "ACCTID":
+			account.AccountID = token.value
+		case "ACCTTYPE":
+			account.AccountType = token.value
+		case "BALAMT":
+			if inLedgerBalance {
+				if amount, err := parseOFXAmount(token.value); err == nil {
+					balance = &amount
+				}
+			}
+		case "DTASOF":
+			if inLedgerBalance {
+				balanceDate, _ = parseOFXDate(token.value)
+			}
+		}
+	}
+
+	if account.AccountType == "" && strings.Contains(strings.ToUpper(text), "<CCACCTFROM>") {
+		account.AccountType = "CREDITCARD"
+	}
+	account.Balance = balance
+	account.BalanceDate = balanceDate
+	preview.Account = account
+
+	for i := range preview.Rows {
+		row := &preview.Rows[i]
+		if row.Currency == "" {
+			row.Currency = account.Currency
+		}
+		if row.Error == "" && row.Date == "" {
+			row.Error = "missing DTPOSTED"
+		}
+		if row.Error == "" && row.Amount == 0 {
+			row.Error = "amount is zero"
+		}
+	}
+	return preview, nil
+}
+
+// nonEmpty - повертає лише непорожні рядки
+func nonEmpty(values ...string) []string {
+	var result []string
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
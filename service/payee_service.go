@@ -0,0 +1,345 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"cashWise/models"
+	"cashWise/repo"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// payeeKeyWords - скільки значущих слів опису лишати в ключі отримувача; решта зазвичай адреса
+const payeeKeyWords = 3
+
+// payeeStopwords - слова банківських описів, що не належать до назви продавця: тип операції,
+// міста, організаційно-правові форми, частини адреси
+var payeeStopwords = map[string]bool{
+	"pos": true, "purchase": true, "payment": true, "card": true, "debit": true, "credit": true,
+	"www": true, "com": true, "ua": true, "llc": true, "ltd": true, "inc": true, "tov": true, "fop": true,
+	"kyiv": true, "kiev": true, "lviv": true, "odesa": true, "odessa": true, "kharkiv": true, "dnipro": true,
+	"ukraine": true, "vul": true, "str": true, "pr": true,
+	"оплата": true, "покупка": true, "платіж": true, "картка": true, "тов": true, "фоп": true, "пп": true,
+	"київ": true, "львів": true, "одеса": true, "харків": true, "дніпро": true, "україна": true,
+	"вул": true, "пр": true,
+}
+
+// PayeeKey - нормалізований опис продавця: до трьох перших значущих слів у нижньому регістрі.
+// Цифри, розділові знаки й службові слова відкидаються, тож "SILPO 123 KYIV" і "Silpo #45" дають "silpo".
+func PayeeKey(text string) string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if len([]rune(word)) < 2 || payeeStopwords[word] {
+			continue
+		}
+		words = append(words, word)
+		if len(words) == payeeKeyWords {
+			break
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// payeeText - текст, з якого визначається отримувач: контрагент з виписки, а без нього опис
+func payeeText(transaction models.Transaction) string {
+	if transaction.Counterparty != "" {
+		return transaction.Counterparty
+	}
+	return transaction.Description
+}
+
+// payeeName - назва для автоматично створеного отримувача: ключ з великих літер
+func payeeName(key string) string {
+	words := strings.Fields(key)
+	for i, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
+
+// aliasMatches - чи починається ключ опису зі слів псевдоніма
+func aliasMatches(key, alias string) bool {
+	return key == alias || strings.HasPrefix(key, alias+" ")
+}
+
+// payeeDirectory - довідник отримувачів користувача для зіставлення багатьох транзакцій підряд.
+// created накопичує ID отримувачів, яких довідник додав сам.
+type payeeDirectory struct {
+	userID  int
+	payees  []models.Payee
+	created []int
+}
+
+func loadPayeeDirectory(userID int) (*payeeDirectory, error) {
+	payees, err := repo.GetPayees(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &payeeDirectory{userID: userID, payees: payees}, nil
+}
+
+// match - отримувач із найдовшим псевдонімом, що підходить до ключа, або nil
+func (d *payeeDirectory) match(key string) *models.Payee {
+	if key == "" {
+		return nil
+	}
+	var best *models.Payee
+	bestLength := 0
+	for i := range d.payees {
+		for _, alias := range d.payees[i].Aliases {
+			if len(alias) > bestLength && aliasMatches(key, alias) {
+				best, bestLength = &d.payees[i], len(alias)
+			}
+		}
+	}
+	return best
+}
+
+// resolve - прив'язує транзакцію до отримувача за описом і ставить його категорію за замовчуванням,
+// якщо категорію не вибрано. З create невідомий продавець додається до довідника.
+func (d *payeeDirectory) resolve(transaction *models.Transaction, create bool) error {
+	if transaction.TransferID != 0 {
+		return nil
+	}
+	key := PayeeKey(payeeText(*transaction))
+	payee := d.match(key)
+	if payee == nil {
+		if !create || key == "" {
+			return nil
+		}
+		// Транзакцію, яку AddTransaction однаково відхилить, не перетворюємо на отримувача-сироту
+		if repo.ValidateTransaction(*transaction) != nil {
+			return nil
+		}
+		created, err := repo.CreatePayee(models.Payee{
+			UserID:            d.userID,
+			Name:              payeeName(key),
+			Aliases:           []string{key},
+			DefaultCategoryID: transaction.CategoryID,
+		})
+		if errors.Is(err, repo.ErrPayeeAliasTaken) {
+			// Того самого продавця щойно додав паралельний запит: беремо його отримувача
+			payees, err := repo.GetPayees(d.userID)
+			if err != nil {
+				return err
+			}
+			d.payees = payees
+			if payee = d.match(key); payee == nil {
+				return repo.ErrPayeeAliasTaken
+			}
+		} else if err != nil {
+			return err
+		} else {
+			d.payees = append(d.payees, created)
+			d.created = append(d.created, created.PayeeID)
+			payee = &d.payees[len(d.payees)-1]
+		}
+	}
+
+	transaction.PayeeID = payee.PayeeID
+	if transaction.CategoryID == 0 && len(transaction.Splits) == 0 {
+		transaction.CategoryID = payee.DefaultCategoryID
+	}
+	return nil
+}
+
+// AssignPayee - визначає отримувача нової транзакції, якщо його не вказано явно. З create
+// невідомий продавець додається до довідника, інакше транзакція лишається без отримувача.
+func AssignPayee(transaction *models.Transaction, create bool) error {
+	if transaction.PayeeID != 0 {
+		_, err := repo.GetPayeeByID(transaction.UserID, transaction.PayeeID)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fmt.Errorf("payee %d not found", transaction.PayeeID)
+		}
+		return err
+	}
+	directory, err := loadPayeeDirectory(transaction.UserID)
+	if err != nil {
+		return err
+	}
+	return directory.resolve(transaction, create)
+}
+
+// MatchImportPayees - позначає рядки імпорту відомими отримувачами; нові отримувачі створюються
+// лише під час збереження імпорту
+func MatchImportPayees(userID int, rows []models.ImportRow) error {
+	directory, err := loadPayeeDirectory(userID)
+	if err != nil {
+		return err
+	}
+	for i := range rows {
+		row := &rows[i]
+		if row.Error != "" || row.PayeeID != 0 {
+			continue
+		}
+		transaction := rowToTransaction(userID, 0, *row)
+		if err := directory.resolve(&transaction, false); err != nil {
+			return err
+		}
+		row.PayeeID = transaction.PayeeID
+		row.CategoryID = transaction.CategoryID
+	}
+	return nil
+}
+
+// normalizePayeeAliases - псевдоніми у вигляді ключів без порожніх і повторів
+func normalizePayeeAliases(aliases []string) []string {
+	result := make([]string, 0, len(aliases))
+	seen := map[string]bool{}
+	for _, alias := range aliases {
+		if key := PayeeKey(alias); key != "" && !seen[key] {
+			seen[key] = true
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
+// ValidatePayee - перевіряє отримувача та нормалізує його псевдоніми. Без псевдонімів отримувача
+// впізнають за назвою; псевдонім не може належати двом отримувачам.
+func ValidatePayee(userID int, payee *models.Payee) error {
+	payee.Name = strings.TrimSpace(payee.Name)
+	if payee.Name == "" {
+		return fmt.Errorf("payee name is required")
+	}
+	if len(payee.Aliases) == 0 {
+		payee.Aliases = []string{payee.Name}
+	}
+	payee.Aliases = normalizePayeeAliases(payee.Aliases)
+	if len(payee.Aliases) == 0 {
+		return fmt.Errorf("payee must have at least one alias with letters")
+	}
+	if payee.DefaultCategoryID != 0 {
+		if _, err := repo.GetCategoryByID(userID, payee.DefaultCategoryID); err != nil {
+			return fmt.Errorf("category %d not found", payee.DefaultCategoryID)
+		}
+	}
+
+	payees, err := repo.GetPayees(userID)
+	if err != nil {
+		return err
+	}
+	for _, other := range payees {
+		if other.PayeeID == payee.PayeeID {
+			continue
+		}
+		for _, alias := range other.Aliases {
+			if containsTag(payee.Aliases, alias) {
+				return fmt.Errorf("alias %q already belongs to payee %d", alias, other.PayeeID)
+			}
+		}
+	}
+	return nil
+}
+
+// MergePayees - зливає отримувачів в одного разом з псевдонімами та транзакціями
+func MergePayees(userID int, merge models.PayeeMerge) (int64, error) {
+	if merge.TargetID == 0 || len(merge.SourceIDs) == 0 {
+		return 0, fmt.Errorf("sourceIDs and targetID are required")
+	}
+	var sourceIDs []int
+	for _, sourceID := range merge.SourceIDs {
+		if sourceID == merge.TargetID {
+			return 0, fmt.Errorf("targetID must not be among sourceIDs")
+		}
+		if !containsID(sourceIDs, sourceID) {
+			sourceIDs = append(sourceIDs, sourceID)
+		}
+	}
+	return repo.MergePayees(userID, merge.TargetID, sourceIDs)
+}
+
+// containsID - чи є ID у списку
+func containsID(ids []int, id int) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitPayee - виділяє частину псевдонімів отримувача в нового. Транзакції, опис яких точніше
+// відповідає перенесеним псевдонімам, переходять до нового отримувача.
+func SplitPayee(userID int, payeeID int, split models.PayeeSplit) (models.Payee, error) {
+	source, err := repo.GetPayeeByID(userID, payeeID)
+	if err != nil {
+		return models.Payee{}, err
+	}
+
+	moved := normalizePayeeAliases(split.Aliases)
+	if len(moved) == 0 {
+		return models.Payee{}, fmt.Errorf("aliases are required")
+	}
+	var remaining []string
+	for _, alias := range source.Aliases {
+		if !containsTag(moved, alias) {
+			remaining = append(remaining, alias)
+		}
+	}
+	for _, alias := range moved {
+		if !containsTag(source.Aliases, alias) {
+			return models.Payee{}, fmt.Errorf("alias %q does not belong to payee %d", alias, payeeID)
+		}
+	}
+	if len(remaining) == 0 {
+		return models.Payee{}, fmt.Errorf("at least one alias must remain with the payee")
+	}
+
+	payee := models.Payee{
+		UserID:            userID,
+		Name:              strings.TrimSpace(split.Name),
+		Aliases:           moved,
+		DefaultCategoryID: split.DefaultCategoryID,
+	}
+	if payee.Name == "" {
+		payee.Name = payeeName(moved[0])
+	}
+	if payee.DefaultCategoryID != 0 {
+		if _, err := repo.GetCategoryByID(userID, payee.DefaultCategoryID); err != nil {
+			return models.Payee{}, fmt.Errorf("category %d not found", payee.DefaultCategoryID)
+		}
+	}
+
+	transactions, err := repo.GetPayeeTransactions(userID, payeeID)
+	if err != nil {
+		return models.Payee{}, err
+	}
+	// Мінімальний довідник з двох отримувачів вирішує, чий псевдонім підходить транзакції краще
+	directory := payeeDirectory{userID: userID, payees: []models.Payee{
+		{PayeeID: payeeID, Aliases: remaining},
+		{PayeeID: 0, Aliases: moved},
+	}}
+	var transactionIDs []int
+	for _, transaction := range transactions {
+		if match := directory.match(PayeeKey(payeeText(transaction))); match != nil && match.PayeeID == 0 {
+			transactionIDs = append(transactionIDs, transaction.TransactionID)
+		}
+	}
+
+	return repo.SplitPayee(userID, payeeID, payee, transactionIDs)
+}
+
+// GetPayeeStats - історія витрат в отримувача за період з розбивкою за місяцями
+func GetPayeeStats(userID int, payeeID int, from, to string) (models.PayeeStats, error) {
+	payee, err := repo.GetPayeeByID(userID, payeeID)
+	if err != nil {
+		return models.PayeeStats{}, err
+	}
+
+	stats, err := repo.GetPayeeSpending(userID, payeeID, from, to)
+	if err != nil {
+		return stats, err
+	}
+	stats.Payee = payee
+	if stats.Count > 0 {
+		stats.Average = math.Round(stats.Total/float64(stats.Count)*100) / 100
+	}
+	return stats, nil
+}
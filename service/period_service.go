@@ -0,0 +1,109 @@
+package service
+
+import (
+	"cashWise/repo"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PeriodOptions - налаштування, від яких залежать межі періоду
+type PeriodOptions struct {
+	WeekStart        time.Weekday // Перший день тижня, за замовчуванням понеділок (ISO)
+	FiscalMonthStart int          // День початку фінансового місяця, наприклад 25 - день зарплати
+	From             string       // Початок довільного періоду (custom), YYYY-MM-DD
+	To               string       // Кінець довільного періоду (custom), YYYY-MM-DD
+}
+
+// DateRange - діапазон дат, обидві межі включно
+type DateRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// StartString - початок діапазону у форматі YYYY-MM-DD
+func (r DateRange) StartString() string {
+	return r.Start.Format(dateLayout)
+}
+
+// EndString - кінець діапазону у форматі YYYY-MM-DD
+func (r DateRange) EndString() string {
+	return r.End.Format(dateLayout)
+}
+
+// Days - кількість днів у діапазоні
+func (r DateRange) Days() int {
+	return int(r.End.Sub(r.Start).Hours()/24) + 1
+}
+
+// PeriodOptionsForUser - повертає налаштування періодів з налаштувань користувача
+func PeriodOptionsForUser(userID int) PeriodOptions {
+	opts := PeriodOptions{WeekStart: time.Monday, FiscalMonthStart: 1}
+
+	settings, err := repo.GetSettingsByUserID(userID)
+	if err != nil {
+		return opts
+	}
+	if settings.WeekStart != nil {
+		opts.WeekStart = time.Weekday(*settings.WeekStart)
+	}
+	if settings.FiscalMonthStart > 0 {
+		opts.FiscalMonthStart = settings.FiscalMonthStart
+	}
+	return opts
+}
+
+// ResolvePeriod - обчислює межі періоду, що містить date.
+// Підтримувані періоди: day, week, isoWeek, month, fiscalMonth, quarter, year, custom.
+func ResolvePeriod(period string, date time.Time, opts PeriodOptions) (DateRange, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch period {
+	case "day":
+		return DateRange{Start: day, End: day}, nil
+	case "week", "isoWeek":
+		weekStart := opts.WeekStart
+		if period == "isoWeek" {
+			weekStart = time.Monday
+		}
+		offset := (int(day.Weekday()) - int(weekStart) + 7) % 7 // Зсув до початку поточного тижня
+		start := day.AddDate(0, 0, -offset)
+		return DateRange{Start: start, End: start.AddDate(0, 0, 6)}, nil
+	case "month":
+		start := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return DateRange{Start: start, End: start.AddDate(0, 1, -1)}, nil
+	case "fiscalMonth":
+		startDay := opts.FiscalMonthStart
+		if startDay <= 1 {
+			return ResolvePeriod("month", date, opts)
+		}
+		// Фінансовий місяць починається в startDay поточного місяця або попереднього, якщо цей день ще не настав
+		start := monthlyDate(day.Year(), day.Month(), startDay)
+		if day.Before(start) {
+			start = monthlyDate(day.Year(), day.Month()-1, startDay)
+		}
+		next := monthlyDate(start.Year(), start.Month()+1, startDay)
+		return DateRange{Start: start, End: next.AddDate(0, 0, -1)}, nil
+	case "quarter":
+		firstMonth := time.Month((int(day.Month())-1)/3*3 + 1)
+		start := time.Date(day.Year(), firstMonth, 1, 0, 0, 0, 0, time.UTC)
+		return DateRange{Start: start, End: start.AddDate(0, 3, -1)}, nil
+	case "year":
+		start := time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		return DateRange{Start: start, End: start.AddDate(1, 0, -1)}, nil
+	case "custom":
+		from, err := time.Parse(dateLayout, opts.From)
+		if err != nil {
+			return DateRange{}, errors.New("custom period requires from in YYYY-MM-DD format")
+		}
+		to, err := time.Parse(dateLayout, opts.To)
+		if err != nil {
+			return DateRange{}, errors.New("custom period requires to in YYYY-MM-DD format")
+		}
+		if to.Before(from) {
+			return DateRange{}, errors.New("to must not be before from")
+		}
+		return DateRange{Start: from, End: to}, nil
+	}
+	return DateRange{}, fmt.Errorf("invalid period %q, must be day, week, isoWeek, month, fiscalMonth, quarter, year or custom", period)
+}
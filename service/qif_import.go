@@ -0,0 +1,185 @@
+package service
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cashWise/models"
+)
+
+// qifDateFormats - формати дат QIF; апостроф у датах Quicken (1/15'24) замінюється на "/"
+var qifDateFormats = []string{
+	"01/02/2006",
+	"1/2/2006",
+	"01/02/06",
+	"1/2/06",
+	"02/01/2006",
+	"2/1/2006",
+	"02.01.2006",
+	"2.1.2006",
+	"2006-01-02",
+	"02-01-2006",
+}
+
+// qifEntry - запис QIF до перетворення на рядок імпорту
+type qifEntry struct {
+	line    int
+	date    string
+	amount  string
+	payee   string
+	memo    string
+	number  string
+	account string
+}
+
+// normalizeQIFDate - зводить варіації дат Quicken до звичайного вигляду
+func normalizeQIFDate(value string) string {
+	value = strings.ReplaceAll(value, "'", "/")
+	return strings.ReplaceAll(value, " ", "")
+}
+
+// qifExternalID - QIF не має ID операцій, тому будуємо стабільний ідентифікатор із самих даних.
+// Порядковий номер однакових записів у файлі розрізняє справжні повтори в один день.
+func qifExternalID(entry qifEntry, occurrence int) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s|%s|%s|%s|%d", entry.date, entry.amount, entry.payee, entry.number, occurrence)))
+	return "qif:" + hex.EncodeToString(sum[:8])
+}
+
+// ParseQIFStatement - розбирає файл QIF у рядки імпорту; розділи !Account задають рахунок наступних записів
+func ParseQIFStatement(data []byte) (models.ImportPreview, error) {
+	preview := models.ImportPreview{Format: "qif", Rows: []models.ImportRow{}}
+
+	text, encoding, err := decodeStatement(data, "")
+	if err != nil {
+		return preview, err
+	}
+	preview.Encoding = encoding
+
+	var entries []qifEntry
+	var current qifEntry
+	account := &models.StatementAccount{}
+	inAccountBlock, inTransactions, started := false, false, false
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "!") {
+			header := strings.ToLower(line)
+			switch {
+			case header == "!account":
+				inAccountBlock, inTransactions = true, false
+			case strings.HasPrefix(header, "!type:"):
+				// Транзакції є лише в банківських, карткових та готівкових розділах
+				kind := strings.TrimPrefix(header, "!type:")
+				inTransactions = kind == "bank" || kind == "ccard" || kind == "cash" || kind == "oth a" || kind == "oth l"
+				if inTransactions && account.AccountType == "" {
+					account.AccountType = line[len("!Type:"):]
+				}
+			default:
+				inTransactions = false // !Option, !Clear та інші службові розділи
+			}
+			started = true
+			continue
+		}
+		if !started {
+			return preview, errors.New("not a QIF file: missing !Type header")
+		}
+
+		code, value := line[0], strings.TrimSpace(line[1:])
+
+		if inAccountBlock {
+			switch code {
+			case 'N':
+				account.Name = value
+			case 'T':
+				account.AccountType = value
+			case '^':
+				inAccountBlock = false
+			}
+			continue
+		}
+		if !inTransactions {
+			continue
+		}
+
+		if current.line == 0 {
+			current.line = i + 1
+			current.account = account.Name
+		}
+		switch code {
+		case 'D':
+			current.date = normalizeQIFDate(value)
+		case 'T', 'U':
+			if current.amount == "" {
+				current.amount = value
+			}
+		case 'P':
+			current.payee = value
+		case 'M':
+			current.memo = value
+		case 'N':
+			current.number = value
+		case '^':
+			entries = append(entries, current)
+			current = qifEntry{}
+		}
+	}
+	if current.line != 0 && current.date != "" {
+		entries = append(entries, current) // Останній запис без завершального ^
+	}
+	if !started {
+		return preview, errors.New("not a QIF file: missing !Type header")
+	}
+
+	// Формат дати та десятковий роздільник спільні для всього файлу
+	var dateValues, amountValues []string
+	for _, entry := range entries {
+		if entry.date != "" {
+			dateValues = append(dateValues, entry.date)
+		}
+		amountValues = append(amountValues, entry.amount)
+	}
+	dateFormat, err := detectDateFormat(dateValues, qifDateFormats)
+	if err != nil && len(entries) > 0 {
+		return preview, err
+	}
+	preview.DateFormat = dateFormat
+	decimalSeparator := detectDecimalSeparator(amountValues)
+
+	occurrences := map[string]int{}
+	for _, entry := range entries {
+		row := models.ImportRow{
+			Line:        entry.line,
+			Description: strings.Join(nonEmpty(entry.payee, entry.memo), " - "),
+			Account:     entry.account,
+		}
+
+		key := entry.date + "|" + entry.amount + "|" + entry.payee + "|" + entry.number
+		row.ExternalID = qifExternalID(entry, occurrences[key])
+		occurrences[key]++
+
+		date, err := time.Parse(dateFormat, entry.date)
+		if err != nil {
+			row.Error = "invalid date"
+		} else {
+			row.Date = date.Format(dateLayout)
+			if row.Amount, err = ParseAmount(entry.amount, decimalSeparator); err != nil {
+				row.Error = err.Error()
+			} else if row.Amount == 0 {
+				row.Error = "amount is zero"
+			}
+		}
+		preview.Rows = append(preview.Rows, row)
+	}
+
+	preview.Account = account
+	return preview, nil
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"cashWise/models"
+	"cashWise/repo"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// maxOccurrenceSteps - запобіжник від нескінченного циклу для некоректних розкладів
+const maxOccurrenceSteps = 100000
+
+// ValidateSchedule - перевіряє правило повторення
+func ValidateSchedule(schedule models.Schedule) error {
+	switch schedule.Frequency {
+	case "daily", "weekly", "monthly", "yearly":
+	default:
+		return errors.New("frequency must be daily, weekly, monthly or yearly")
+	}
+	if schedule.Interval < 0 {
+		return errors.New("interval must be positive")
+	}
+	for _, weekday := range schedule.Weekdays {
+		if weekday < 0 || weekday > 6 {
+			return errors.New("weekdays must be between 0 (Sunday) and 6 (Saturday)")
+		}
+	}
+	if schedule.MonthDay < -1 || schedule.MonthDay > 31 {
+		return errors.New("monthDay must be between 1 and 31, or -1 for the last day")
+	}
+	if schedule.EndDate != "" {
+		if _, err := time.Parse(dateLayout, schedule.EndDate); err != nil {
+			return errors.New("invalid endDate format")
+		}
+	}
+	if schedule.Count < 0 {
+		return errors.New("count must be positive")
+	}
+	return nil
+}
+
+// daysIn - кількість днів у місяці
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// monthlyDate - дата в місяці з обмеженням дня останнім днем місяця (31 -> 30 квітня, 29 лютого -> 28)
+func monthlyDate(year int, month time.Month, day int) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	last := daysIn(first.Year(), first.Month())
+	if day == -1 || day > last {
+		day = last
+	}
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+// ScheduleOccurrences - повертає всі дати повторень від start до until включно з урахуванням endDate та count
+func ScheduleOccurrences(schedule models.Schedule, start, until time.Time) []time.Time {
+	interval := schedule.Interval
+	if interval == 0 {
+		interval = 1
+	}
+	if schedule.EndDate != "" {
+		if endDate, err := time.Parse(dateLayout, schedule.EndDate); err == nil && endDate.Before(until) {
+			until = endDate
+		}
+	}
+
+	var dates []time.Time
+	// add - додає дату, повертає false, коли розклад вичерпано
+	add := func(date time.Time) bool {
+		if date.After(until) {
+			return false
+		}
+		if schedule.Count > 0 && len(dates) >= schedule.Count {
+			return false
+		}
+		if !date.Before(start) {
+			dates = append(dates, date)
+		}
+		return true
+	}
+
+	switch schedule.Frequency {
+	case "daily":
+		for step := 0; step < maxOccurrenceSteps; step++ {
+			if !add(start.AddDate(0, 0, step*interval)) {
+				break
+			}
+		}
+	case "weekly":
+		weekdays := schedule.Weekdays
+		if len(weekdays) == 0 {
+			weekdays = []int{int(start.Weekday())}
+		}
+		weekdays = append([]int(nil), weekdays...)
+		sort.Ints(weekdays)
+		weekStart := start.AddDate(0, 0, -int(start.Weekday())) // Неділя тижня початку
+	weeks:
+		for step := 0; step < maxOccurrenceSteps; step++ {
+			week := weekStart.AddDate(0, 0, 7*step*interval)
+			for _, weekday := range weekdays {
+				if !add(week.AddDate(0, 0, weekday)) {
+					break weeks
+				}
+			}
+		}
+	case "monthly":
+		day := schedule.MonthDay
+		if day == 0 {
+			day = start.Day()
+		}
+		for step := 0; step < maxOccurrenceSteps; step++ {
+			if !add(monthlyDate(start.Year(), start.Month()+time.Month(step*interval), day)) {
+				break
+			}
+		}
+	case "yearly":
+		for step := 0; step < maxOccurrenceSteps; step++ {
+			if !add(monthlyDate(start.Year()+step*interval, start.Month(), start.Day())) {
+				break
+			}
+		}
+	}
+	return dates
+}
+
+// recurringOccurrences - повторення шаблону від start до until з урахуванням винятків
+func recurringOccurrences(recurring models.RecurringTransaction, from, until time.Time) ([]models.Occurrence, error) {
+	start, err := time.Parse(dateLayout, recurring.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid startDate for recurring %d: %v", recurring.RecurringID, err)
+	}
+
+	exceptions := make(map[string]models.RecurringException, len(recurring.Exceptions))
+	for _, exception := range recurring.Exceptions {
+		exceptions[exception.Date] = exception
+	}
+
+	var occurrences []models.Occurrence
+	for _, date := range ScheduleOccurrences(recurring.Schedule, start, until) {
+		if date.Before(from) {
+			continue
+		}
+		occurrence := models.Occurrence{
+			RecurringID: recurring.RecurringID,
+			Date:        date.Format(dateLayout),
+			CategoryID:  recurring.CategoryID,
+			Type:        recurring.Type,
+			Amount:      recurring.Amount,
+			Description: recurring.Description,
+		}
+		if exception, ok := exceptions[occurrence.Date]; ok {
+			occurrence.Skipped = exception.Skip
+			if exception.Amount != 0 {
+				occurrence.Amount = exception.Amount
+			}
+			if exception.Description != "" {
+				occurrence.Description = exception.Description
+			}
+			if exception.CategoryID != 0 {
+				occurrence.CategoryID = exception.CategoryID
+			}
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+	return occurrences, nil
+}
+
+// GetUpcomingOccurrences - повертає повторення всіх активних шаблонів користувача на найближчі days днів
+func GetUpcomingOccurrences(userID int, days int) ([]models.Occurrence, error) {
+	recurringList, err := repo.GetRecurringByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	until := today.AddDate(0, 0, days)
+
+	upcoming := []models.Occurrence{}
+	for _, recurring := range recurringList {
+		if !recurring.Active {
+			continue
+		}
+		occurrences, err := recurringOccurrences(recurring, today, until)
+		if err != nil {
+			log.Printf("Skipping recurring %d: %v", recurring.RecurringID, err)
+			continue
+		}
+		upcoming = append(upcoming, occurrences...)
+	}
+
+	sort.SliceStable(upcoming, func(i, j int) bool { return upcoming[i].Date < upcoming[j].Date })
+	return upcoming, nil
+}
+
+// generatorMutex - не дає двом запускам генератора створювати ті самі повторення одночасно
+var generatorMutex sync.Mutex
+
+// GenerateDueRecurringTransactions - створює транзакції для всіх повторень, що настали до today включно.
+// Ідемпотентна: повторення, для яких транзакцію вже створено, пропускаються.
+func GenerateDueRecurringTransactions(today time.Time) error {
+	generatorMutex.Lock()
+	defer generatorMutex.Unlock()
+
+	recurringList, err := repo.GetActiveRecurring()
+	if err != nil {
+		return fmt.Errorf("error fetching recurring transactions: %v", err)
+	}
+
+	for _, recurring := range recurringList {
+		// Починаємо з останнього створеного повторення, щоб не перебирати всю історію
+		from := time.Time{}
+		if recurring.LastGenerated != "" {
+			if last, err := time.Parse(dateLayout, recurring.LastGenerated); err == nil {
+				from = last
+			}
+		}
+
+		occurrences, err := recurringOccurrences(recurring, from, today)
+		if err != nil {
+			log.Printf("Skipping recurring %d: %v", recurring.RecurringID, err)
+			continue
+		}
+
+		for _, occurrence := range occurrences {
+			if !occurrence.Skipped {
+				if err := materializeOccurrence(recurring, occurrence); err != nil {
+					log.Printf("Error generating occurrence %s of recurring %d: %v", occurrence.Date, recurring.RecurringID, err)
+					break
+				}
+			}
+			if err := repo.SetRecurringLastGenerated(recurring.RecurringID, occurrence.Date); err != nil {
+				log.Printf("Error updating lastGenerated for recurring %d: %v", recurring.RecurringID, err)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// materializeOccurrence - створює транзакцію для повторення, якщо її ще немає
+func materializeOccurrence(recurring models.RecurringTransaction, occurrence models.Occurrence) error {
+	exists, err := repo.RecurringOccurrenceExists(recurring.UserID, recurring.RecurringID, occurrence.Date)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	transaction := models.Transaction{
+		UserID:      recurring.UserID,
+		CategoryID:  occurrence.CategoryID,
+		Type:        occurrence.Type,
+		Amount:      occurrence.Amount,
+		Date:        occurrence.Date,
+		Description: occurrence.Description,
+		Account:     recurring.Account,
+		Currency:    recurring.Currency,
+		RecurringID: recurring.RecurringID,
+	}
+	// Розбиття застосовуємо лише до незміненої суми, інакше частини не зійдуться
+	if occurrence.Amount == recurring.Amount {
+		transaction.Splits = recurring.Splits
+	}
+	if err := repo.AddTransaction(transaction); err != nil {
+		return err
+	}
+
+	LearnTransaction(transaction)
+	CheckBudgetAlerts(recurring.UserID)
+	return nil
+}
+
+// StartRecurringGenerator - запускає фоновий генератор повторень з заданим інтервалом
+func StartRecurringGenerator(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := GenerateDueRecurringTransactions(time.Now().UTC().Truncate(24 * time.Hour)); err != nil {
+				log.Printf("Recurring generator error: %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"strings"
+
+	"cashWise/models"
+	"cashWise/repo"
+)
+
+// ruleSampleLimit - скільки змінених транзакцій показувати у відповіді перевірки та застосування правил
+const ruleSampleLimit = 20
+
+// ruleBatchSize - скільки оновлень транзакцій записувати за один запит до БД
+const ruleBatchSize = 500
+
+// compiledRule - правило з уже скомпільованим регулярним виразом
+type compiledRule struct {
+	rule  models.CategoryRule
+	regex *regexp.Regexp
+}
+
+// ValidateRule - перевіряє правило та нормалізує його теги
+func ValidateRule(userID int, rule *models.CategoryRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		return fmt.Errorf("rule name is required")
+	}
+
+	conditions := rule.Conditions
+	if conditions.DescriptionContains == "" && conditions.DescriptionRegex == "" && conditions.MinAmount == nil &&
+		conditions.MaxAmount == nil && conditions.Account == "" && conditions.Type == "" {
+		return fmt.Errorf("rule must have at least one condition")
+	}
+	if conditions.DescriptionRegex != "" {
+		if _, err := regexp.Compile(conditions.DescriptionRegex); err != nil {
+			return fmt.Errorf("invalid descriptionRegex: %v", err)
+		}
+	}
+	if conditions.MinAmount != nil && conditions.MaxAmount != nil && *conditions.MinAmount > *conditions.MaxAmount {
+		return fmt.Errorf("minAmount must not be greater than maxAmount")
+	}
+	switch conditions.Type {
+	case "", "income", "expense", "goal":
+	default:
+		return fmt.Errorf("type must be income, expense or goal")
+	}
+
+	rule.Actions.Tags = repo.NormalizeTags(rule.Actions.Tags)
+	rule.Actions.Description = strings.TrimSpace(rule.Actions.Description)
+	if rule.Actions.CategoryID == 0 && len(rule.Actions.Tags) == 0 && rule.Actions.Description == "" {
+		return fmt.Errorf("rule must have at least one action")
+	}
+	if rule.Actions.CategoryID != 0 {
+		if _, err := repo.GetCategoryByID(userID, rule.Actions.CategoryID); err != nil {
+			return fmt.Errorf("category %d not found", rule.Actions.CategoryID)
+		}
+	}
+	return nil
+}
+
+// compileRules - готує ввімкнені правила до перевірки, зберігаючи їхній порядок
+func compileRules(rules []models.CategoryRule) []compiledRule {
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Disabled {
+			continue
+		}
+		entry := compiledRule{rule: rule}
+		if rule.Conditions.DescriptionRegex != "" {
+			regex, err := regexp.Compile(rule.Conditions.DescriptionRegex)
+			if err != nil {
+				log.Printf("Skipping rule %d with invalid regex: %v", rule.RuleID, err)
+				continue
+			}
+			entry.regex = regex
+		}
+		compiled = append(compiled, entry)
+	}
+	return compiled
+}
+
+// loadRules - ввімкнені правила користувача в порядку пріоритету
+func loadRules(userID int) ([]compiledRule, error) {
+	rules, err := repo.GetRules(userID)
+	if err != nil {
+		return nil, err
+	}
+	return compileRules(rules), nil
+}
+
+// matches - чи виконуються всі умови правила для транзакції; частини переказів правила не змінюють
+func (c compiledRule) matches(transaction models.Transaction) bool {
+	if transaction.TransferID != 0 {
+		return false
+	}
+	conditions := c.rule.Conditions
+	if conditions.DescriptionContains != "" &&
+		!strings.Contains(strings.ToLower(transaction.Description), strings.ToLower(conditions.DescriptionContains)) {
+		return false
+	}
+	if c.regex != nil && !c.regex.MatchString(transaction.Description) {
+		return false
+	}
+	amount := math.Abs(transaction.Amount)
+	if conditions.MinAmount != nil && amount < *conditions.MinAmount {
+		return false
+	}
+	if conditions.MaxAmount != nil && amount > *conditions.MaxAmount {
+		return false
+	}
+	if conditions.Account != "" && !strings.EqualFold(conditions.Account, transaction.Account) {
+		return false
+	}
+	if conditions.Type != "" && conditions.Type != transaction.Type {
+		return false
+	}
+	return true
+}
+
+// applyRules - застосовує правила за порядком. Умови перевіряються на вихідній транзакції; категорію та опис
+// задає перше правило, що їх має, теги всіх правил об'єднуються. Без overwriteCategory категорія
+// змінюється лише у транзакції без категорії. Повертає ID правил, що змінили транзакцію.
+func applyRules(transaction *models.Transaction, rules []compiledRule, overwriteCategory bool) []int {
+	original := *transaction
+	categorySet, descriptionSet := false, false
+	var applied []int
+
+	for _, entry := range rules {
+		if !entry.matches(original) {
+			continue
+		}
+		actions := entry.rule.Actions
+		changed := false
+
+		if actions.CategoryID != 0 && !categorySet && len(transaction.Splits) == 0 &&
+			(overwriteCategory || transaction.CategoryID == 0) {
+			categorySet = true
+			if transaction.CategoryID != actions.CategoryID {
+				transaction.CategoryID = actions.CategoryID
+				changed = true
+			}
+		}
+		if actions.Description != "" && !descriptionSet {
+			descriptionSet = true
+			if transaction.Description != actions.Description {
+				transaction.Description = actions.Description
+				changed = true
+			}
+		}
+		for _, tag := range actions.Tags {
+			if !containsTag(transaction.Tags, tag) {
+				transaction.Tags = append(transaction.Tags, tag)
+				changed = true
+			}
+		}
+
+		if changed {
+			applied = append(applied, entry.rule.RuleID)
+		}
+		if entry.rule.StopProcessing {
+			break
+		}
+	}
+	return applied
+}
+
+// CategorizeTransaction - застосовує правила користувача до нової транзакції; категорію, вибрану вручну, не змінює
+func CategorizeTransaction(transaction *models.Transaction) error {
+	rules, err := loadRules(transaction.UserID)
+	if err != nil {
+		return err
+	}
+	applyRules(transaction, rules, false)
+	return nil
+}
+
+// CategorizeImportRows - застосовує правила до рядків імпорту. Категорія з правила має перевагу
+// над категорією за замовчуванням, заданою для всього файлу.
+func CategorizeImportRows(userID int, rows []models.ImportRow) error {
+	rules, err := loadRules(userID)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	for i := range rows {
+		row := &rows[i]
+		if row.Error != "" {
+			continue
+		}
+		transaction := rowToTransaction(userID, 0, *row)
+		if applyRules(&transaction, rules, true) == nil {
+			continue
+		}
+		row.CategoryID = transaction.CategoryID
+		row.Description = transaction.Description
+		row.Tags = transaction.Tags
+	}
+	return nil
+}
+
+// ruleMatch - пара "до/після" для відповіді; теги копіюються, щоб зміни не торкались оригіналу
+func ruleMatch(transaction models.Transaction, rules []compiledRule, overwriteCategory bool) (models.RuleMatch, bool) {
+	result := transaction
+	result.Tags = append([]string(nil), transaction.Tags...)
+	applied := applyRules(&result, rules, overwriteCategory)
+	return models.RuleMatch{Transaction: transaction, Result: result, RuleIDs: applied}, applied != nil
+}
+
+// TestRule - перевіряє правило на всій історії користувача, нічого не змінюючи
+func TestRule(userID int, rule models.CategoryRule) (models.RuleTestResult, error) {
+	result := models.RuleTestResult{Samples: []models.RuleMatch{}}
+	rule.Disabled = false
+	rules := compileRules([]models.CategoryRule{rule})
+
+	err := repo.StreamTransactions(userID, models.TransactionSearch{}, "date", true, func(transaction models.Transaction) error {
+		result.Scanned++
+		if !rules[0].matches(transaction) {
+			return nil
+		}
+		result.Matched++
+		match, changed := ruleMatch(transaction, rules, true)
+		if changed {
+			result.Changed++
+		}
+		if len(result.Samples) < ruleSampleLimit {
+			result.Samples = append(result.Samples, match)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// ApplyRulesToHistory - застосовує всі ввімкнені правила до наявних транзакцій, що відповідають пошуку.
+// Категорію правила ставлять і тим транзакціям, що вже її мають; з dryRun лише рахує зміни.
+func ApplyRulesToHistory(userID int, search models.TransactionSearch, dryRun bool) (models.RuleApplyResult, error) {
+	result := models.RuleApplyResult{DryRun: dryRun, Samples: []models.RuleMatch{}}
+	rules, err := loadRules(userID)
+	if err != nil {
+		return result, err
+	}
+	if len(rules) == 0 {
+		return result, nil
+	}
+
+	var pending []models.Transaction
+	err = repo.StreamTransactions(userID, search, "date", true, func(transaction models.Transaction) error {
+		result.Scanned++
+		match, changed := ruleMatch(transaction, rules, true)
+		if !changed {
+			return nil
+		}
+		result.Updated++
+		if len(result.Samples) < ruleSampleLimit {
+			result.Samples = append(result.Samples, match)
+		}
+		if dryRun {
+			return nil
+		}
+		pending = append(pending, match.Result)
+		if len(pending) >= ruleBatchSize {
+			if err := repo.SaveCategorizedTransactions(userID, pending); err != nil {
+				return err
+			}
+			pending = pending[:0]
+		}
+		return nil
+	})
+	if err == nil && !dryRun {
+		err = repo.SaveCategorizedTransactions(userID, pending)
+	}
+	if !dryRun && result.Updated > 0 {
+		// Категорії змінено масово, тож модель підказок простіше навчити заново
+		ResetCategoryModel(userID)
+	}
+	return result, err
+}
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"cashWise/models"
+	"cashWise/pdf"
+	"cashWise/repo"
+)
+
+// statementLocale - мова підписів і формат чисел та дат у PDF-виписці
+type statementLocale struct {
+	thousands string
+	decimal   string
+	months    [12]string
+	date      func(t time.Time) string
+	labels    map[string]string
+}
+
+// statementLocales - підтримувані мови виписки; за замовчуванням українська
+var statementLocales = map[string]statementLocale{
+	"uk": {
+		thousands: "\u00a0", // Нерозривний пробіл, щоб число не розривалось між рядками
+		decimal:   ",",
+		months: [12]string{"Січень", "Лютий", "Березень", "Квітень", "Травень", "Червень",
+			"Липень", "Серпень", "Вересень", "Жовтень", "Листопад", "Грудень"},
+		date: func(t time.Time) string { return t.Format("02.01.2006") },
+		labels: map[string]string{
+			"title":         "Місячна виписка",
+			"holder":        "Власник",
+			"period":        "Період",
+			"opening":       "Залишок на початок",
+			"income":        "Надходження",
+			"expense":       "Витрати",
+			"closing":       "Залишок на кінець",
+			"transactions":  "Транзакції",
+			"date":          "Дата",
+			"description":   "Опис",
+			"category":      "Категорія",
+			"amount":        "Сума",
+			"none":          "Немає даних за період",
+			"byIncome":      "Надходження за категоріями",
+			"byExpense":     "Витрати за категоріями",
+			"count":         "К-сть",
+			"share":         "Частка",
+			"budgets":       "Бюджети",
+			"budget":        "Бюджет",
+			"limit":         "Ліміт",
+			"spent":         "Витрачено",
+			"remaining":     "Залишок",
+			"status":        "Стан",
+			"within":        "У межах",
+			"exceeded":      "Перевищено",
+			"goals":         "Цілі",
+			"goal":          "Ціль",
+			"saved":         "Накопичено",
+			"target":        "Мета",
+			"progress":      "Прогрес",
+			"deadline":      "Термін",
+			"transfer":      "Переказ",
+			"goalPayment":   "Внесок на ціль",
+			"uncategorized": "Без категорії",
+			"page":          "Сторінка %d з %d",
+			"generated":     "Сформовано",
+		},
+	},
+	"en": {
+		thousands: ",",
+		decimal:   ".",
+		months: [12]string{"January", "February", "March", "April", "May", "June",
+			"July", "August", "September", "October", "November", "December"},
+		date: func(t time.Time) string { return t.Format("Jan 2, 2006") },
+		labels: map[string]string{
+			"title":         "Monthly statement",
+			"holder":        "Account holder",
+			"period":        "Period",
+			"opening":       "Opening balance",
+			"income":        "Income",
+			"expense":       "Expenses",
+			"closing":       "Closing balance",
+			"transactions":  "Transactions",
+			"date":          "Date",
+			"description":   "Description",
+			"category":      "Category",
+			"amount":        "Amount",
+			"none":          "No data for this period",
+			"byIncome":      "Income by category",
+			"byExpense":     "Expenses by category",
+			"count":         "Count",
+			"share":         "Share",
+			"budgets":       "Budgets",
+			"budget":        "Budget",
+			"limit":         "Limit",
+			"spent":         "Spent",
+			"remaining":     "Remaining",
+			"status":        "Status",
+			"within":        "Within limit",
+			"exceeded":      "Exceeded",
+			"goals":         "Goals",
+			"goal":          "Goal",
+			"saved":         "Saved",
+			"target":        "Target",
+			"progress":      "Progress",
+			"deadline":      "Deadline",
+			"transfer":      "Transfer",
+			"goalPayment":   "Goal contribution",
+			"uncategorized": "Uncategorized",
+			"page":          "Page %d of %d",
+			"generated":     "Generated",
+		},
+	},
+}
+
+// RequestLanguage - мова відповіді з параметра lang або заголовка Accept-Language; невідомі мови - українська
+func RequestLanguage(lang, acceptLanguage string) string {
+	for _, candidate := range append([]string{lang}, strings.Split(acceptLanguage, ",")...) {
+		code := strings.ToLower(strings.TrimSpace(candidate))
+		if len(code) >= 2 {
+			if _, ok := statementLocales[code[:2]]; ok {
+				return code[:2]
+			}
+		}
+	}
+	return "uk"
+}
+
+// formatNumber - число з двома знаками, розділювачем тисяч і десятковим розділювачем мови
+func (l statementLocale) formatNumber(value float64) string {
+	sign := ""
+	if value < 0 && math.Round(value*100) != 0 {
+		sign = "-"
+	}
+	cents := int64(math.Round(math.Abs(value) * 100))
+	whole := fmt.Sprint(cents / 100)
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteString(l.thousands)
+		}
+		grouped.WriteRune(digit)
+	}
+	return fmt.Sprintf("%s%s%s%02d", sign, grouped.String(), l.decimal, cents%100)
+}
+
+// formatPercent - відсоток з precision знаками після десяткового розділювача мови
+func (l statementLocale) formatPercent(value float64, precision int) string {
+	return strings.Replace(strconv.FormatFloat(value, 'f', precision, 64), ".", l.decimal, 1) + "%"
+}
+
+// formatDate - дата YYYY-MM-DD у форматі мови; нерозпізнані значення повертаються як є
+func (l statementLocale) formatDate(value string) string {
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return value
+	}
+	return l.date(date)
+}
+
+// statementRenderer - розкладка виписки на сторінках з поточною позицією y
+type statementRenderer struct {
+	doc    *pdf.Document
+	locale statementLocale
+	y      float64
+}
+
+const (
+	statementBodySize   = 9.0
+	statementRowHeight  = 14.0
+	statementFooterSize = 8.0
+	statementRight      = pdf.PageWidth - pdf.Margin
+	statementBottom     = pdf.PageHeight - pdf.Margin - 20 // Місце під колонтитул
+)
+
+// ensure - переходить на нову сторінку, якщо блок висотою height не вміщається;
+// header перемальовує заголовок таблиці на новій сторінці
+func (s *statementRenderer) ensure(height float64, header func()) {
+	if s.y+height <= statementBottom {
+		return
+	}
+	s.doc.AddPage()
+	s.y = pdf.Margin + 10
+	if header != nil {
+		header()
+	}
+}
+
+// heading - заголовок розділу
+func (s *statementRenderer) heading(title string) {
+	s.ensure(50, nil)
+	s.y += 22
+	s.doc.Text(pdf.Margin, s.y, 12, true, pdf.Black, title)
+	s.y += 6
+	s.doc.Line(pdf.Margin, s.y, statementRight, s.y, 0.8, pdf.Black)
+	s.y += 4
+}
+
+// statementColumn - колонка таблиці; з right=true x - правий край колонки
+type statementColumn struct {
+	x     float64
+	title string
+	right bool
+}
+
+// tableHeader - функція, що малює рядок назв колонок
+func (s *statementRenderer) tableHeader(columns ...statementColumn) func() {
+	return func() {
+		s.y += statementRowHeight
+		for _, column := range columns {
+			if column.right {
+				s.doc.TextRight(column.x, s.y, statementBodySize, true, pdf.Gray, column.title)
+			} else {
+				s.doc.Text(column.x, s.y, statementBodySize, true, pdf.Gray, column.title)
+			}
+		}
+		s.y += 4
+		s.doc.Line(pdf.Margin, s.y, statementRight, s.y, 0.4, pdf.LightGray)
+	}
+}
+
+// row - переходить на наступний рядок таблиці
+func (s *statementRenderer) row(header func()) {
+	s.ensure(statementRowHeight, header)
+	s.y += statementRowHeight
+}
+
+// summaryLine - рядок підсумку: назва ліворуч, сума праворуч
+func (s *statementRenderer) summaryLine(label string, value float64, bold bool) {
+	s.y += 16
+	s.doc.Text(pdf.Margin, s.y, 10, bold, pdf.Black, label)
+	s.doc.TextRight(statementRight, s.y, 10, bold, pdf.Black, s.locale.formatNumber(value))
+}
+
+// RenderStatementPDF - малює виписку у PDF мовою lang ("uk" або "en") і пише її в w
+func RenderStatementPDF(w io.Writer, statement models.MonthlyStatement, lang string) error {
+	locale, ok := statementLocales[lang]
+	if !ok {
+		locale = statementLocales["uk"]
+	}
+	doc := pdf.NewDocument()
+	// Без шрифту з кирилицею українські підписи стали б знаками питання, тож виписка виходить англійською
+	if !doc.CanRender(locale.labels["title"]) {
+		log.Printf("PDF font cannot render the %q statement, using English", lang)
+		locale = statementLocales["en"]
+	}
+	label := locale.labels
+	s := &statementRenderer{doc: doc, locale: locale, y: pdf.Margin}
+
+	// Шапка і зведення
+	month, _ := time.Parse("2006-01", statement.Month)
+	s.y += 16
+	s.doc.Text(pdf.Margin, s.y, 16, true, pdf.Black, label["title"])
+	s.doc.TextRight(statementRight, s.y, 16, true, pdf.Black, fmt.Sprintf("%s %d", locale.months[month.Month()-1], month.Year()))
+	s.y += 18
+	if statement.FullName != "" {
+		s.doc.Text(pdf.Margin, s.y, 10, false, pdf.Gray, fmt.Sprintf("%s: %s", label["holder"], statement.FullName))
+	}
+	s.doc.TextRight(statementRight, s.y, 10, false, pdf.Gray, fmt.Sprintf("%s: %s - %s",
+		label["period"], locale.formatDate(statement.From), locale.formatDate(statement.To)))
+	s.y += 8
+
+	s.summaryLine(label["opening"], statement.OpeningBalance, false)
+	s.summaryLine(label["income"], statement.TotalIncome, false)
+	s.summaryLine(label["expense"], -statement.TotalExpense, false)
+	s.y += 6
+	s.doc.Line(statementRight-200, s.y, statementRight, s.y, 0.6, pdf.Black)
+	s.summaryLine(label["closing"], statement.ClosingBalance, true)
+	s.y += 6
+
+	// Транзакції в хронологічному порядку
+	s.heading(label["transactions"])
+	header := s.tableHeader(statementColumn{pdf.Margin, label["date"], false}, statementColumn{110, label["description"], false},
+		statementColumn{330, label["category"], false}, statementColumn{statementRight, label["amount"], true})
+	header()
+	if len(statement.Transactions) == 0 {
+		s.row(nil)
+		s.doc.Text(pdf.Margin, s.y, statementBodySize, false, pdf.Gray, label["none"])
+	}
+	for _, line := range statement.Transactions {
+		s.row(header)
+		category := line.Category
+		switch {
+		case line.Type == repo.TransferType:
+			category = label["transfer"]
+		case line.Type == "goal":
+			category = label["goalPayment"]
+		case category == "":
+			category = label["uncategorized"]
+		}
+		amount := locale.formatNumber(line.Amount)
+		if line.Amount > 0 {
+			amount = "+" + amount
+		}
+		if line.Currency != "" {
+			amount += " " + line.Currency
+		}
+		color := pdf.Black
+		if line.Amount > 0 {
+			color = pdf.Green
+		}
+
+		s.doc.Text(pdf.Margin, s.y, statementBodySize, false, pdf.Black, locale.formatDate(line.Date))
+		s.doc.Text(110, s.y, statementBodySize, false, pdf.Black, s.doc.FitText(line.Description, statementBodySize, 212))
+		s.doc.Text(330, s.y, statementBodySize, false, pdf.Gray, s.doc.FitText(category, statementBodySize, 140))
+		s.doc.TextRight(statementRight, s.y, statementBodySize, false, color, amount)
+	}
+
+	// Підсумки за категоріями
+	s.renderCategories(label["byIncome"], statement.IncomeByCategory, statement.TotalIncome)
+	s.renderCategories(label["byExpense"], statement.ExpenseByCategory, statement.TotalExpense)
+
+	// Бюджети
+	if len(statement.Budgets) > 0 {
+		s.heading(label["budgets"])
+		header := s.tableHeader(statementColumn{pdf.Margin, label["budget"], false}, statementColumn{300, label["limit"], true},
+			statementColumn{390, label["spent"], true}, statementColumn{475, label["remaining"], true},
+			statementColumn{statementRight, label["status"], true})
+		header()
+		for _, budget := range statement.Budgets {
+			s.row(header)
+			status, color := label["within"], pdf.Green
+			if budget.Exceeded {
+				status, color = label["exceeded"], pdf.Red
+			}
+			// Бюджет з іншим, ніж місяць виписки, періодом показуємо разом з його межами
+			name := budget.Name
+			if budget.From != statement.From || budget.To != statement.To {
+				name = fmt.Sprintf("%s (%s - %s)", name, locale.formatDate(budget.From), locale.formatDate(budget.To))
+			}
+			s.doc.Text(pdf.Margin, s.y, statementBodySize, false, pdf.Black, s.doc.FitText(name, statementBodySize, 180))
+			s.doc.TextRight(300, s.y, statementBodySize, false, pdf.Black, locale.formatNumber(budget.Limit))
+			s.doc.TextRight(390, s.y, statementBodySize, false, pdf.Black, locale.formatNumber(budget.Spent))
+			s.doc.TextRight(475, s.y, statementBodySize, false, color, locale.formatNumber(budget.Remaining))
+			s.doc.TextRight(statementRight, s.y, statementBodySize, true, color, status)
+		}
+	}
+
+	// Цілі зі шкалою прогресу
+	if len(statement.Goals) > 0 {
+		s.heading(label["goals"])
+		header := s.tableHeader(statementColumn{pdf.Margin, label["goal"], false}, statementColumn{290, label["saved"], true},
+			statementColumn{370, label["target"], true}, statementColumn{385, label["progress"], false},
+			statementColumn{statementRight, label["deadline"], true})
+		header()
+		for _, goal := range statement.Goals {
+			s.row(header)
+			s.doc.Text(pdf.Margin, s.y, statementBodySize, false, pdf.Black, s.doc.FitText(goal.Name, statementBodySize, 170))
+			s.doc.TextRight(290, s.y, statementBodySize, false, pdf.Black, locale.formatNumber(goal.Saved))
+			s.doc.TextRight(370, s.y, statementBodySize, false, pdf.Black, locale.formatNumber(goal.TargetAmount))
+			s.doc.FillRect(385, s.y-7, 70, 7, pdf.LightGray)
+			s.doc.FillRect(385, s.y-7, 70*goal.Progress/100, 7, pdf.Green)
+			s.doc.Text(460, s.y, statementBodySize, false, pdf.Black, s.locale.formatPercent(goal.Progress, 0))
+			s.doc.TextRight(statementRight, s.y, statementBodySize, false, pdf.Gray, locale.formatDate(goal.Deadline))
+		}
+	}
+
+	// Колонтитули дописуються в кінці, коли відома кількість сторінок
+	generated := fmt.Sprintf("%s: %s", label["generated"], locale.date(time.Now()))
+	for i := 0; i < s.doc.PageCount(); i++ {
+		s.doc.SetPage(i)
+		footerY := pdf.PageHeight - pdf.Margin + 10
+		s.doc.Line(pdf.Margin, footerY-12, statementRight, footerY-12, 0.4, pdf.LightGray)
+		s.doc.Text(pdf.Margin, footerY, statementFooterSize, false, pdf.Gray, generated)
+		s.doc.TextRight(statementRight, footerY, statementFooterSize, false, pdf.Gray, fmt.Sprintf(label["page"], i+1, s.doc.PageCount()))
+	}
+
+	return s.doc.Write(w)
+}
+
+// renderCategories - таблиця сум за категоріями з часткою від загальної суми
+func (s *statementRenderer) renderCategories(title string, categories []models.StatementCategory, total float64) {
+	label := s.locale.labels
+	s.heading(title)
+	header := s.tableHeader(statementColumn{pdf.Margin, label["category"], false}, statementColumn{390, label["count"], true},
+		statementColumn{465, label["share"], true}, statementColumn{statementRight, label["amount"], true})
+	header()
+	if len(categories) == 0 {
+		s.row(nil)
+		s.doc.Text(pdf.Margin, s.y, statementBodySize, false, pdf.Gray, label["none"])
+		return
+	}
+	for _, category := range categories {
+		s.row(header)
+		name := category.Name
+		if name == "" {
+			name = label["uncategorized"]
+		}
+		share := 0.0
+		if total > 0 {
+			share = category.Total / total * 100
+		}
+		s.doc.Text(pdf.Margin, s.y, statementBodySize, false, pdf.Black, s.doc.FitText(name, statementBodySize, 280))
+		s.doc.TextRight(390, s.y, statementBodySize, false, pdf.Gray, fmt.Sprint(category.Count))
+		s.doc.TextRight(465, s.y, statementBodySize, false, pdf.Gray, s.locale.formatPercent(share, 1))
+		s.doc.TextRight(statementRight, s.y, statementBodySize, false, pdf.Black, s.locale.formatNumber(category.Total))
+	}
+}
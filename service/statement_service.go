@@ -0,0 +1,188 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"cashWise/models"
+	"cashWise/repo"
+)
+
+// BuildMonthlyStatement - збирає дані місячної виписки: залишок на початок місяця, транзакції в хронологічному
+// порядку, підсумки за категоріями, стан бюджетів і прогрес цілей. month - у форматі YYYY-MM.
+func BuildMonthlyStatement(userID int, month string) (models.MonthlyStatement, error) {
+	start, err := time.Parse("2006-01", month)
+	if err != nil {
+		return models.MonthlyStatement{}, fmt.Errorf("month must be in YYYY-MM format")
+	}
+	end := start.AddDate(0, 1, -1)
+
+	statement := models.MonthlyStatement{
+		UserID:            userID,
+		Month:             month,
+		From:              start.Format(dateLayout),
+		To:                end.Format(dateLayout),
+		Transactions:      []models.StatementLine{},
+		IncomeByCategory:  []models.StatementCategory{},
+		ExpenseByCategory: []models.StatementCategory{},
+		Budgets:           []models.StatementBudget{},
+		Goals:             []models.StatementGoal{},
+	}
+
+	if user, err := repo.GetUserByID(userID); err == nil {
+		statement.FullName = user.FullName
+	}
+
+	statement.OpeningBalance, err = repo.GetBalanceBefore(userID, statement.From)
+	if err != nil {
+		return statement, err
+	}
+
+	categories := categoryCache{}
+	search := models.TransactionSearch{From: statement.From, To: statement.To}
+	err = repo.StreamTransactions(userID, search, "date", false, func(transaction models.Transaction) error {
+		statement.Transactions = append(statement.Transactions, statementLine(transaction, categories))
+		switch transaction.Type {
+		case "income":
+			statement.TotalIncome += transaction.Amount
+		case "expense", "goal":
+			// Внески на цілі, як і в CalculateTotalExpense, зменшують залишок разом з витратами
+			statement.TotalExpense += transaction.Amount
+		}
+		return nil
+	})
+	if err != nil {
+		return statement, err
+	}
+	statement.ClosingBalance = statement.OpeningBalance + statement.TotalIncome - statement.TotalExpense
+
+	if statement.IncomeByCategory, err = statementCategories(userID, []string{"income"}, statement, categories); err != nil {
+		return statement, err
+	}
+	if statement.ExpenseByCategory, err = statementCategories(userID, []string{"expense", "goal"}, statement, categories); err != nil {
+		return statement, err
+	}
+
+	budgets, err := repo.GetBudgetsByUserID(userID)
+	if err != nil {
+		return statement, err
+	}
+	for _, budget := range budgets {
+		// Ліміт діє на власний період бюджету: беремо період, що триває на кінець місяця,
+		// і витрати в ньому до кінця місяця виписки
+		dates, err := budgetPeriodRange(budget, end)
+		if err != nil {
+			return statement, err
+		}
+		dates.End = end
+		spent, err := GetBudgetSpent(budget, dates)
+		if err != nil {
+			return statement, err
+		}
+		statement.Budgets = append(statement.Budgets, models.StatementBudget{
+			BudgetID:  budget.BudgetID,
+			Name:      budget.Name,
+			From:      dates.StartString(),
+			To:        dates.EndString(),
+			Limit:     budget.Limit,
+			Spent:     spent,
+			Remaining: budget.Limit - spent,
+			Exceeded:  spent > budget.Limit,
+		})
+	}
+
+	if statement.Goals, err = statementGoals(userID, statement.To); err != nil {
+		return statement, err
+	}
+	return statement, nil
+}
+
+// statementLine - рядок виписки; витрати, внески на цілі та вихідні перекази мають від'ємну суму
+func statementLine(transaction models.Transaction, categories categoryCache) models.StatementLine {
+	line := models.StatementLine{
+		TransactionID: transaction.TransactionID,
+		Date:          transaction.Date,
+		Type:          transaction.Type,
+		Description:   transaction.Description,
+		Amount:        transaction.Amount,
+		Currency:      transaction.Currency,
+	}
+	if transaction.Type != "income" && !(transaction.Type == repo.TransferType && transaction.TransferSide == "in") {
+		line.Amount = -transaction.Amount
+	}
+
+	// У частин переказу категорії немає
+	switch {
+	case transaction.TransferID != 0:
+	case len(transaction.Splits) > 0:
+		names := make([]string, 0, len(transaction.Splits))
+		for _, split := range transaction.Splits {
+			if name := categories.get(split.CategoryID).Name; name != "" {
+				names = append(names, name)
+			}
+		}
+		line.Category = strings.Join(names, ", ")
+	default:
+		line.Category = categories.get(transaction.CategoryID).Name
+	}
+	return line
+}
+
+// statementCategories - суми за категоріями для типів транзакцій у межах місяця виписки
+func statementCategories(userID int, transactionTypes []string, statement models.MonthlyStatement, categories categoryCache) ([]models.StatementCategory, error) {
+	totals, err := repo.GetCategoryTotals(userID, transactionTypes, statement.From, statement.To)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]models.StatementCategory, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, models.StatementCategory{
+			CategoryID: total.CategoryID,
+			Name:       categories.get(total.CategoryID).Name,
+			Total:      total.Total,
+			Count:      total.Count,
+		})
+	}
+	return result, nil
+}
+
+// statementGoals - прогрес цілей на дату to за внесками, прив'язаними до цілі через goalID
+func statementGoals(userID int, to string) ([]models.StatementGoal, error) {
+	goals, err := repo.GetGoalsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	contributions, err := repo.GetTransactionsByUserIDAndType(userID, "goal")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.StatementGoal, 0, len(goals))
+	for _, goal := range goals {
+		saved := 0.0
+		for _, transaction := range contributions {
+			if transaction.GoalID == goal.GoalID && transaction.Date <= to {
+				saved += transaction.Amount
+			}
+		}
+
+		progress := 0.0
+		if goal.TargetAmount > 0 {
+			progress = saved / goal.TargetAmount * 100
+			if progress > 100 {
+				progress = 100
+			}
+		}
+		result = append(result, models.StatementGoal{
+			GoalID:       goal.GoalID,
+			Name:         goal.Name,
+			TargetAmount: goal.TargetAmount,
+			Saved:        saved,
+			Progress:     progress,
+			Deadline:     goal.Deadline,
+			Status:       goal.Status,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"cashWise/models"
+	"cashWise/repo"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// minSubscriptionOccurrences - мінімальна кількість платежів, щоб вважати їх регулярними
+const minSubscriptionOccurrences = 3
+
+// amountTolerance - допустиме відхилення суми від медіани групи (частка)
+const amountTolerance = 0.2
+
+// subscriptionPeriod - відомий період повторення з допуском у днях
+type subscriptionPeriod struct {
+	frequency string
+	interval  int
+	days      int
+	minDays   int
+	maxDays   int
+}
+
+var subscriptionPeriods = []subscriptionPeriod{
+	{"weekly", 1, 7, 6, 8},
+	{"weekly", 2, 14, 13, 15},
+	{"monthly", 1, 30, 27, 33},
+	{"monthly", 3, 91, 85, 97},
+	{"yearly", 1, 365, 355, 375},
+}
+
+// normalizeDescription - приводить опис до ключа групування: нижній регістр, лише літери, слова через дефіс
+func normalizeDescription(description string) string {
+	words := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	return strings.Join(words, "-")
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// matchPeriod - знаходить період, якому відповідають усі інтервали між платежами
+func matchPeriod(intervals []float64) (subscriptionPeriod, bool) {
+	typical := median(intervals)
+	for _, period := range subscriptionPeriods {
+		if typical < float64(period.minDays) || typical > float64(period.maxDays) {
+			continue
+		}
+		for _, interval := range intervals {
+			if interval < float64(period.minDays) || interval > float64(period.maxDays) {
+				return period, false
+			}
+		}
+		return period, true
+	}
+	return subscriptionPeriod{}, false
+}
+
+// nextDate - дата наступного платежу після last
+func (p subscriptionPeriod) nextDate(last time.Time) time.Time {
+	switch p.frequency {
+	case "weekly":
+		return last.AddDate(0, 0, 7*p.interval)
+	case "monthly":
+		return monthlyDate(last.Year(), last.Month()+time.Month(p.interval), last.Day())
+	default:
+		return monthlyDate(last.Year()+p.interval, last.Month(), last.Day())
+	}
+}
+
+// DetectSubscriptions - шукає в історії витрат регулярні платежі, які користувач ще не оформив як шаблон
+func DetectSubscriptions(userID int) ([]models.DetectedSubscription, error) {
+	transactions, err := repo.GetAllTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	decisions, err := repo.GetSubscriptionDecisions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Групуємо витрати за нормалізованим описом; вже створені з шаблонів не враховуємо
+	groups := make(map[string][]models.Transaction)
+	for _, transaction := range transactions {
+		if transaction.Type != "expense" || transaction.RecurringID != 0 {
+			continue
+		}
+		key := normalizeDescription(transaction.Description)
+		if key == "" {
+			continue
+		}
+		if _, decided := decisions[key]; decided {
+			continue
+		}
+		groups[key] = append(groups[key], transaction)
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	detected := []models.DetectedSubscription{}
+	for key, group := range groups {
+		if subscription, ok := detectSubscription(key, group, today); ok {
+			detected = append(detected, subscription)
+		}
+	}
+
+	sort.Slice(detected, func(i, j int) bool { return detected[i].MonthlyCost > detected[j].MonthlyCost })
+	return detected, nil
+}
+
+// detectSubscription - перевіряє, чи група платежів з однаковим описом є регулярною. Групу, чий
+// наступний платіж мав відбутися більш ніж за період до today, вважаємо припиненою підпискою.
+func detectSubscription(key string, group []models.Transaction, today time.Time) (models.DetectedSubscription, bool) {
+	if len(group) < minSubscriptionOccurrences {
+		return models.DetectedSubscription{}, false
+	}
+
+	// Залишаємо лише платежі зі схожою сумою
+	amounts := make([]float64, len(group))
+	for i, transaction := range group {
+		amounts[i] = transaction.Amount
+	}
+	typicalAmount := median(amounts)
+
+	type payment struct {
+		transaction models.Transaction
+		date        time.Time
+	}
+	var payments []payment
+	for _, transaction := range group {
+		if math.Abs(transaction.Amount-typicalAmount) > typicalAmount*amountTolerance {
+			continue
+		}
+		date, err := time.Parse(dateLayout, transaction.Date)
+		if err != nil {
+			continue
+		}
+		payments = append(payments, payment{transaction, date})
+	}
+	if len(payments) < minSubscriptionOccurrences {
+		return models.DetectedSubscription{}, false
+	}
+	sort.Slice(payments, func(i, j int) bool { return payments[i].date.Before(payments[j].date) })
+
+	intervals := make([]float64, 0, len(payments)-1)
+	for i := 1; i < len(payments); i++ {
+		intervals = append(intervals, payments[i].date.Sub(payments[i-1].date).Hours()/24)
+	}
+	period, ok := matchPeriod(intervals)
+	if !ok {
+		return models.DetectedSubscription{}, false
+	}
+
+	var total float64
+	ids := make([]int, 0, len(payments))
+	for _, p := range payments {
+		total += p.transaction.Amount
+		ids = append(ids, p.transaction.TransactionID)
+	}
+	average := total / float64(len(payments))
+	last := payments[len(payments)-1]
+	next := period.nextDate(last.date)
+	if period.nextDate(next).Before(today) {
+		return models.DetectedSubscription{}, false
+	}
+
+	return models.DetectedSubscription{
+		Key:            key,
+		Description:    last.transaction.Description,
+		CategoryID:     last.transaction.CategoryID,
+		Amount:         math.Round(average*100) / 100,
+		Frequency:      period.frequency,
+		Interval:       period.interval,
+		IntervalDays:   period.days,
+		Occurrences:    len(payments),
+		LastDate:       last.date.Format(dateLayout),
+		NextDate:       next.Format(dateLayout),
+		MonthlyCost:    math.Round(average*365.25/12/float64(period.days)*100) / 100,
+		TransactionIDs: ids,
+	}, true
+}
+
+// findDetectedSubscription - шукає знайдений платіж за ключем
+func findDetectedSubscription(userID int, key string) (models.DetectedSubscription, error) {
+	detected, err := DetectSubscriptions(userID)
+	if err != nil {
+		return models.DetectedSubscription{}, err
+	}
+	for _, subscription := range detected {
+		if subscription.Key == key {
+			return subscription, nil
+		}
+	}
+	return models.DetectedSubscription{}, errors.New("subscription not found")
+}
+
+// ConfirmSubscription - перетворює знайдений платіж на шаблон транзакції, що повторюється
+func ConfirmSubscription(userID int, key string) (models.RecurringTransaction, error) {
+	subscription, err := findDetectedSubscription(userID, key)
+	if err != nil {
+		return models.RecurringTransaction{}, err
+	}
+
+	last, _ := time.Parse(dateLayout, subscription.LastDate)
+	schedule := models.Schedule{Frequency: subscription.Frequency, Interval: subscription.Interval}
+	if subscription.Frequency == "monthly" {
+		schedule.MonthDay = last.Day()
+	}
+
+	// Починаємо з наступного платежу, щоб не дублювати вже наявні транзакції, але не раніше
+	// сьогодні: пропущені платежі не відбулися, і генератор не повинен їх дозаписувати
+	start, _ := time.Parse(dateLayout, subscription.NextDate)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if start.Before(today) {
+		for _, date := range ScheduleOccurrences(schedule, start, today.AddDate(1, 0, 0)) {
+			if !date.Before(today) {
+				start = date
+				break
+			}
+		}
+	}
+
+	recurring, err := repo.CreateRecurring(models.RecurringTransaction{
+		UserID:      userID,
+		CategoryID:  subscription.CategoryID,
+		Type:        "expense",
+		Amount:      subscription.Amount,
+		Description: subscription.Description,
+		StartDate:   start.Format(dateLayout),
+		Schedule:    schedule,
+	})
+	if err != nil {
+		return recurring, err
+	}
+
+	err = repo.SaveSubscriptionDecision(models.SubscriptionDecision{
+		UserID:      userID,
+		Key:         key,
+		Status:      "confirmed",
+		RecurringID: recurring.RecurringID,
+	})
+	return recurring, err
+}
+
+// DismissSubscription - приховує знайдений платіж, щоб він більше не пропонувався
+func DismissSubscription(userID int, key string) error {
+	return repo.SaveSubscriptionDecision(models.SubscriptionDecision{
+		UserID: userID,
+		Key:    key,
+		Status: "dismissed",
+	})
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+
+	"cashWise/models"
+	"cashWise/repo"
+)
+
+// categoryClassifier - наївний баєсів класифікатор категорій одного користувача. Ознаки транзакції -
+// слова опису, порядок суми та тип; модель тримає лише лічильники, тому легко оновлюється по одній транзакції.
+type categoryClassifier struct {
+	mu            sync.Mutex
+	documents     map[int]int            // Категорія -> кількість транзакцій
+	total         int                    // Усього транзакцій у моделі
+	features      map[int]map[string]int // Категорія -> ознака -> кількість
+	featureTotals map[int]int            // Категорія -> сума лічильників ознак
+	vocabulary    map[string]int         // Ознака -> кількість у всіх категоріях
+}
+
+// categoryModels - навчені моделі за userID; модель будується з історії при першому запиті підказки
+var categoryModels = struct {
+	sync.Mutex
+	byUser map[int]*categoryClassifier
+}{byUser: map[int]*categoryClassifier{}}
+
+func newCategoryClassifier() *categoryClassifier {
+	return &categoryClassifier{
+		documents:     map[int]int{},
+		features:      map[int]map[string]int{},
+		featureTotals: map[int]int{},
+		vocabulary:    map[string]int{},
+	}
+}
+
+// amountBucket - порядок суми з кроком у пів десятка: 10-31, 32-99, 100-316, ...
+func amountBucket(amount float64) string {
+	amount = math.Abs(amount)
+	if amount < 1 {
+		return "amount:0"
+	}
+	return fmt.Sprintf("amount:%d", int(math.Floor(math.Log10(amount)*2)))
+}
+
+// transactionFeatures - ознаки для класифікатора: слова опису від двох літер, порядок суми і тип
+func transactionFeatures(description string, amount float64, transactionType string) []string {
+	var features []string
+	for word := range descriptionWords(description) {
+		if len([]rune(word)) >= 2 {
+			features = append(features, "word:"+word)
+		}
+	}
+	if amount != 0 {
+		features = append(features, amountBucket(amount))
+	}
+	if transactionType != "" {
+		features = append(features, "type:"+transactionType)
+	}
+	return features
+}
+
+// learnable - чи годиться транзакція для навчання: частини переказів і розбиті транзакції не мають однієї категорії
+func learnable(transaction models.Transaction) bool {
+	return transaction.CategoryID != 0 && transaction.TransferID == 0 && len(transaction.Splits) == 0
+}
+
+// update - додає (delta = 1) або прибирає (delta = -1) транзакцію з моделі
+func (c *categoryClassifier) update(transaction models.Transaction, delta int) {
+	if !learnable(transaction) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	categoryID := transaction.CategoryID
+	if delta < 0 && c.documents[categoryID] == 0 {
+		return // Транзакція потрапила в БД до побудови моделі і не врахована в ній
+	}
+	c.documents[categoryID] += delta
+	c.total += delta
+	if c.features[categoryID] == nil {
+		c.features[categoryID] = map[string]int{}
+	}
+	for _, feature := range transactionFeatures(transaction.Description, transaction.Amount, transaction.Type) {
+		c.features[categoryID][feature] += delta
+		c.featureTotals[categoryID] += delta
+		c.vocabulary[feature] += delta
+		if c.features[categoryID][feature] <= 0 {
+			delete(c.features[categoryID], feature)
+		}
+		if c.vocabulary[feature] <= 0 {
+			delete(c.vocabulary, feature)
+		}
+	}
+	if c.documents[categoryID] <= 0 {
+		delete(c.documents, categoryID)
+		delete(c.features, categoryID)
+		delete(c.featureTotals, categoryID)
+	}
+}
+
+// categoryScore - категорія з імовірністю для опису
+type categoryScore struct {
+	categoryID  int
+	probability float64
+}
+
+// predict - імовірності категорій за формулою Байєса зі згладжуванням Лапласа, від найімовірнішої
+func (c *categoryClassifier) predict(features []string) []categoryScore {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.total == 0 {
+		return nil
+	}
+	vocabularySize := float64(len(c.vocabulary) + 1)
+	scores := make([]categoryScore, 0, len(c.documents))
+	maxLog := math.Inf(-1)
+	for categoryID, documents := range c.documents {
+		logProbability := math.Log(float64(documents) / float64(c.total))
+		denominator := float64(c.featureTotals[categoryID]) + vocabularySize
+		for _, feature := range features {
+			logProbability += math.Log((float64(c.features[categoryID][feature]) + 1) / denominator)
+		}
+		scores = append(scores, categoryScore{categoryID: categoryID, probability: logProbability})
+		if logProbability > maxLog {
+			maxLog = logProbability
+		}
+	}
+
+	// Логарифми перетворюємо на нормовані ймовірності, віднімаючи максимум, щоб не втратити точність
+	sum := 0.0
+	for i := range scores {
+		scores[i].probability = math.Exp(scores[i].probability - maxLog)
+		sum += scores[i].probability
+	}
+	for i := range scores {
+		scores[i].probability /= sum
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].probability != scores[j].probability {
+			return scores[i].probability > scores[j].probability
+		}
+		return scores[i].categoryID < scores[j].categoryID
+	})
+	return scores
+}
+
+// loadedCategoryModel - модель користувача, якщо вона вже в пам'яті
+func loadedCategoryModel(userID int) *categoryClassifier {
+	categoryModels.Lock()
+	defer categoryModels.Unlock()
+	return categoryModels.byUser[userID]
+}
+
+// categoryModel - модель користувача; при першому зверненні навчається на всіх його транзакціях
+func categoryModel(userID int) (*categoryClassifier, error) {
+	if model := loadedCategoryModel(userID); model != nil {
+		return model, nil
+	}
+
+	model := newCategoryClassifier()
+	search := models.TransactionSearch{Types: []string{"income", "expense"}}
+	err := repo.StreamTransactions(userID, search, "transactionID", false, func(transaction models.Transaction) error {
+		model.update(transaction, 1)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	categoryModels.Lock()
+	defer categoryModels.Unlock()
+	if existing := categoryModels.byUser[userID]; existing != nil {
+		return existing, nil // Паралельний запит встиг навчити модель раніше
+	}
+	categoryModels.byUser[userID] = model
+	return model, nil
+}
+
+// LearnTransaction - додає нову або змінену транзакцію до моделі користувача, якщо модель уже навчена
+func LearnTransaction(transaction models.Transaction) {
+	if model := loadedCategoryModel(transaction.UserID); model != nil {
+		model.update(transaction, 1)
+	}
+}
+
+// ForgetTransaction - прибирає видалену транзакцію або її попередній стан з моделі користувача
+func ForgetTransaction(transaction models.Transaction) {
+	if model := loadedCategoryModel(transaction.UserID); model != nil {
+		model.update(transaction, -1)
+	}
+}
+
+// ResetCategoryModel - скидає модель після масових змін; наступна підказка навчить її заново
+func ResetCategoryModel(userID int) {
+	categoryModels.Lock()
+	defer categoryModels.Unlock()
+	delete(categoryModels.byUser, userID)
+}
+
+// SuggestCategories - найімовірніші категорії для опису та суми з упевненістю від 0 до 1
+func SuggestCategories(userID int, description string, amount float64, transactionType string, limit int) ([]models.CategorySuggestion, error) {
+	model, err := categoryModel(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := []models.CategorySuggestion{}
+	features := transactionFeatures(description, amount, transactionType)
+	if len(features) == 0 {
+		return suggestions, nil
+	}
+
+	for _, score := range model.predict(features) {
+		if len(suggestions) >= limit {
+			break
+		}
+		category, err := repo.GetCategoryByID(userID, score.categoryID)
+		if err != nil {
+			// Категорію видалено після навчання моделі
+			log.Printf("Skipping suggested category %d for userID %d: %v", score.categoryID, userID, err)
+			continue
+		}
+		if category.Archived {
+			continue
+		}
+		suggestions = append(suggestions, models.CategorySuggestion{
+			CategoryID: category.CategoryID,
+			Name:       category.Name,
+			Icon:       category.Icon,
+			Confidence: math.Round(score.probability*1000) / 1000,
+		})
+	}
+	return suggestions, nil
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"cashWise/db"
 	"cashWise/models"
+	"cashWise/repo"
 	"context"
 	"errors"
 	"log"
@@ -23,12 +24,14 @@ func GetTransactionWithCategory(transactionID int) (map[string]interface{}, erro
 		return nil, errors.New("transaction not found")
 	}
 
-	// Отримуємо категорію, пов'язану з транзакцією
+	// Отримуємо категорію, пов'язану з транзакцією (у частин переказу категорії немає)
 	var category models.Category
-	err = categoryCollection.FindOne(context.TODO(), bson.M{"categoryID": transaction.CategoryID}).Decode(&category)
-	if err != nil {
-		log.Printf("Error retrieving category: %v", err)
-		return nil, errors.New("category not found")
+	if transaction.TransferID == 0 {
+		err = categoryCollection.FindOne(context.TODO(), bson.M{"categoryID": transaction.CategoryID}).Decode(&category)
+		if err != nil {
+			log.Printf("Error retrieving category: %v", err)
+			return nil, errors.New("category not found")
+		}
 	}
 
 	// Формуємо фінальний JSON
@@ -42,51 +45,63 @@ func GetTransactionWithCategory(transactionID int) (map[string]interface{}, erro
 		"description":   transaction.Description,
 		"icon":          category.Icon, // Додаємо icon з категорії
 	}
+	addTransferFields(result, transaction)
+	if len(transaction.Splits) > 0 {
+		result["splits"] = splitsWithIcons(transaction.Splits)
+	}
 
 	return result, nil
 }
 
-// GetTransactionsByUserID - отримує всі транзакції для заданого userID і повертає їх у потрібному форматі
-func GetTransactionsByUserID(userID int) ([]map[string]interface{}, error) {
-	transactionCollection := db.GetTransactionCollection()
+// GetTransactionsByUserID - отримує сторінку транзакцій для заданого userID і повертає їх у потрібному форматі
+func GetTransactionsByUserID(userID int, tags []string, params repo.ListParams) (repo.Page, error) {
 	categoryCollection := db.GetCategoryCollection()
 
-	// Отримуємо всі транзакції для користувача за userID
-	cursor, err := transactionCollection.Find(context.TODO(), bson.M{"userID": userID})
+	// Для іконок потрібні повні документи, тому поля відбираємо вже після збагачення
+	fields := params.Fields
+	params.Fields = nil
+
+	// Отримуємо сторінку транзакцій для користувача за userID
+	page, err := repo.ListTransactions(userID, tags, params)
 	if err != nil {
 		log.Printf("Error retrieving transactions: %v", err)
-		return nil, errors.New("transactions not found")
+		return page, errors.New("transactions not found")
 	}
-	defer cursor.Close(context.TODO())
 
-	var transactions []map[string]interface{}
+	transactions := make([]bson.M, 0, len(page.Items))
 
 	// Обробляємо кожну транзакцію
-	for cursor.Next(context.TODO()) {
+	for _, item := range page.Items {
 		var transaction models.Transaction
-		if err := cursor.Decode(&transaction); err != nil {
-			log.Printf("Error decoding transaction: %v", err)
-			continue
+		raw, err := bson.Marshal(item)
+		if err == nil {
+			err = bson.Unmarshal(raw, &transaction)
 		}
-
-		// Отримуємо категорію для поточної транзакції
-		var category models.Category
-		err := categoryCollection.FindOne(context.TODO(), bson.M{"categoryID": transaction.CategoryID}).Decode(&category)
 		if err != nil {
-			log.Printf("Error retrieving category: %v", err)
+			log.Printf("Error decoding transaction: %v", err)
 			continue
 		}
 
-		// Перевірка наявності іконки
-		if category.Icon == "" {
-			log.Printf("Category icon is empty for categoryID: %d", transaction.CategoryID)
+		// Отримуємо категорію для поточної транзакції (у частин переказу категорії немає)
+		var category models.Category
+		if transaction.TransferID == 0 {
+			err := categoryCollection.FindOne(context.TODO(), bson.M{"categoryID": transaction.CategoryID}).Decode(&category)
+			if err != nil {
+				// Транзакцію показуємо і без категорії, лише без іконки
+				log.Printf("Error retrieving category %d: %v", transaction.CategoryID, err)
+			}
+
+			// Перевірка наявності іконки
+			if category.Icon == "" {
+				log.Printf("Category icon is empty for categoryID: %d", transaction.CategoryID)
+			}
 		}
 
 		// Форматуємо дату у правильний формат
 		formattedDate := transaction.Date
 
 		// Формуємо об'єкт для результату
-		transactionData := map[string]interface{}{
+		transactionData := bson.M{
 			"transactionID": transaction.TransactionID,
 			"userID":        transaction.UserID,
 			"categoryID":    transaction.CategoryID,
@@ -96,16 +111,65 @@ func GetTransactionsByUserID(userID int) ([]map[string]interface{}, error) {
 			"description":   transaction.Description,
 			"icon":          category.Icon, // Додаємо icon з категорії
 		}
+		addTransferFields(transactionData, transaction)
+		if len(transaction.Splits) > 0 {
+			transactionData["splits"] = splitsWithIcons(transaction.Splits)
+		}
+		// Необов'язкові поля, як у /transactions, щоб fields= вибирало їх так само
+		if transaction.Account != "" {
+			transactionData["account"] = transaction.Account
+		}
+		if transaction.Currency != "" {
+			transactionData["currency"] = transaction.Currency
+		}
+		if transaction.ExchangeRate != 0 {
+			transactionData["exchangeRate"] = transaction.ExchangeRate
+		}
+		if transaction.RecurringID != 0 {
+			transactionData["recurringID"] = transaction.RecurringID
+		}
+		if len(transaction.Tags) > 0 {
+			transactionData["tags"] = transaction.Tags
+		}
 
 		// Додаємо транзакцію в результат
 		transactions = append(transactions, transactionData)
 	}
 
-	// Перевіряємо, чи є помилка після завершення обробки курсору
-	if err := cursor.Err(); err != nil {
-		log.Printf("Cursor error: %v", err)
-		return nil, err
+	repo.KeepFields(transactions, fields)
+	page.Items = transactions
+	return page, nil
+}
+
+// addTransferFields - додає до результату дані переказу, якщо транзакція є його частиною
+func addTransferFields(result map[string]interface{}, transaction models.Transaction) {
+	if transaction.TransferID == 0 {
+		return
 	}
+	result["transferID"] = transaction.TransferID
+	result["transferSide"] = transaction.TransferSide
+	result["account"] = transaction.Account
+	result["currency"] = transaction.Currency
+}
 
-	return transactions, nil
+// splitsWithIcons - формує частини транзакції з іконками їхніх категорій
+func splitsWithIcons(splits []models.TransactionSplit) []map[string]interface{} {
+	categoryCollection := db.GetCategoryCollection()
+
+	result := make([]map[string]interface{}, 0, len(splits))
+	for _, split := range splits {
+		var category models.Category
+		err := categoryCollection.FindOne(context.TODO(), bson.M{"categoryID": split.CategoryID}).Decode(&category)
+		if err != nil {
+			log.Printf("Error retrieving category %d for split: %v", split.CategoryID, err)
+		}
+
+		result = append(result, map[string]interface{}{
+			"categoryID": split.CategoryID,
+			"amount":     split.Amount,
+			"memo":       split.Memo,
+			"icon":       category.Icon,
+		})
+	}
+	return result
 }
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Мінімальна книга Office Open XML з одним аркушем. Рядки пишуться в zip одразу, тому розмір файлу
+// не обмежений пам'яттю; рядки зберігаються як inlineStr, щоб не збирати таблицю sharedStrings.
+var xlsxStaticParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Transactions" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`},
+	// Стилі: 0 - звичайний, 1 - число з двома знаками (вбудований формат 2), 2 - жирний заголовок
+	{"xl/styles.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="2" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>
+</styleSheet>`},
+}
+
+const (
+	xlsxStyleAmount = 1
+	xlsxStyleHeader = 2
+)
+
+// xlsxWriter - пише аркуш XLSX рядок за рядком
+type xlsxWriter struct {
+	archive *zip.Writer
+	sheet   io.Writer
+	row     int
+}
+
+// newXLSXWriter - записує службові частини книги та відкриває аркуш
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	archive := zip.NewWriter(w)
+	for _, part := range xlsxStaticParts {
+		file, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(file, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.WriteString(sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err != nil {
+		return nil, err
+	}
+	return &xlsxWriter{archive: archive, sheet: sheet}, nil
+}
+
+// xlsxColumn - назва колонки за номером з 0: A, B, ..., Z, AA, ...
+func xlsxColumn(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// writeRow - додає рядок; числа записуються як числа, решта - як текст
+func (x *xlsxWriter) writeRow(values []interface{}, style int) error {
+	x.row++
+	if _, err := fmt.Fprintf(x.sheet, `<row r="%d">`, x.row); err != nil {
+		return err
+	}
+	for i, value := range values {
+		ref := xlsxColumn(i) + strconv.Itoa(x.row)
+		var err error
+		switch v := value.(type) {
+		case float64:
+			cellStyle := style
+			if cellStyle == 0 {
+				cellStyle = xlsxStyleAmount
+			}
+			_, err = fmt.Fprintf(x.sheet, `<c r="%s" s="%d"><v>%s</v></c>`, ref, cellStyle, strconv.FormatFloat(v, 'f', -1, 64))
+		case int:
+			if v == 0 {
+				continue // Порожня клітинка замість нуля для необов'язкових ID
+			}
+			_, err = fmt.Fprintf(x.sheet, `<c r="%s" s="%d"><v>%d</v></c>`, ref, style, v)
+		default:
+			text := fmt.Sprint(v)
+			if text == "" {
+				continue
+			}
+			if _, err = fmt.Fprintf(x.sheet, `<c r="%s" t="inlineStr" s="%d"><is><t xml:space="preserve">`, ref, style); err != nil {
+				return err
+			}
+			if err = xml.EscapeText(x.sheet, []byte(text)); err != nil {
+				return err
+			}
+			_, err = io.WriteString(x.sheet, `</t></is></c>`)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(x.sheet, `</row>`)
+	return err
+}
+
+// close - закриває аркуш і архів
+func (x *xlsxWriter) close() error {
+	if _, err := io.WriteString(x.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return x.archive.Close()
+}